@@ -0,0 +1,487 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+const (
+	// DeltaFormatVersion текущая версия формата дельты
+	DeltaFormatVersion = 1
+
+	deltaManifestName = ".criage-delta.json"
+	deltaPayloadDir   = "data"
+
+	// deltaDictID идентификатор словаря для patch-from кодирования
+	deltaDictID = 0x43524744
+
+	// maxPatchSize максимальный размер файла, для которого строится патч
+	maxPatchSize = 256 * 1024 * 1024
+
+	// maxDeltaFileSize максимальный размер файла в дельте: файл восстанавливается
+	// в памяти, поэтому заявленный размер ограничивает и декодирование
+	maxDeltaFileSize = 1024 * 1024 * 1024
+)
+
+// deltaFileInfo информация о файле распакованного пакета
+type deltaFileInfo struct {
+	mode     os.FileMode
+	size     int64
+	checksum string
+}
+
+// CreateDelta создает бинарную дельту между двумя версиями пакета.
+// Неизменившиеся файлы помечаются для копирования, изменившиеся кодируются
+// zstd с базовой версией файла в качестве словаря (аналог zstd --patch-from).
+func (m *Manager) CreateDelta(basePath string, baseFormat types.ArchiveFormat, targetPath string, targetFormat types.ArchiveFormat, deltaPath string) (*types.DeltaManifest, error) {
+	baseChecksum, err := fileChecksum(basePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to checksum base archive: %w", err)
+	}
+
+	tempDir, err := os.MkdirTemp("", "criage-delta-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	baseDir := filepath.Join(tempDir, "base")
+	targetDir := filepath.Join(tempDir, "target")
+	if err := m.ExtractArchive(basePath, baseDir, baseFormat); err != nil {
+		return nil, fmt.Errorf("failed to extract base archive: %w", err)
+	}
+	if err := m.ExtractArchive(targetPath, targetDir, targetFormat); err != nil {
+		return nil, fmt.Errorf("failed to extract target archive: %w", err)
+	}
+
+	baseFiles, err := scanDeltaFiles(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	targetFiles, err := scanDeltaFiles(targetDir)
+	if err != nil {
+		return nil, err
+	}
+	targetDirs, err := scanDeltaDirs(targetDir)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &types.DeltaManifest{
+		FormatVersion: DeltaFormatVersion,
+		BaseChecksum:  baseChecksum,
+		TargetFormat:  targetFormat,
+		CreatedAt:     time.Now().UTC(),
+		Files:         targetDirs,
+	}
+	if metadata := readDeltaMetadata(baseDir); metadata != nil && metadata.PackageManifest != nil {
+		manifest.FromVersion = metadata.PackageManifest.Version
+	}
+	if metadata := readDeltaMetadata(targetDir); metadata != nil && metadata.PackageManifest != nil {
+		manifest.Name = metadata.PackageManifest.Name
+		manifest.ToVersion = metadata.PackageManifest.Version
+	}
+
+	file, err := os.Create(deltaPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	tarWriter := tar.NewWriter(file)
+
+	paths := make([]string, 0, len(targetFiles))
+	for path := range targetFiles {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for i, path := range paths {
+		info := targetFiles[path]
+		if info.size > maxDeltaFileSize {
+			return nil, fmt.Errorf("file %s exceeds maximum delta file size %d", path, maxDeltaFileSize)
+		}
+		entry := types.DeltaFile{
+			Path:     path,
+			Mode:     uint32(info.mode.Perm()),
+			Size:     info.size,
+			Checksum: info.checksum,
+		}
+
+		base, inBase := baseFiles[path]
+		if inBase && base.checksum == info.checksum {
+			entry.Op = types.DeltaKeep
+			entry.BaseChecksum = base.checksum
+			manifest.Files = append(manifest.Files, entry)
+			continue
+		}
+
+		target, err := os.ReadFile(filepath.Join(targetDir, filepath.FromSlash(path)))
+		if err != nil {
+			return nil, err
+		}
+
+		payload := m.zstdEncoder.EncodeAll(target, nil)
+		entry.Op = types.DeltaAdd
+
+		if inBase && base.size <= maxPatchSize && info.size <= maxPatchSize {
+			baseData, err := os.ReadFile(filepath.Join(baseDir, filepath.FromSlash(path)))
+			if err != nil {
+				return nil, err
+			}
+			patch, err := m.encodePatch(baseData, target)
+			if err != nil {
+				return nil, err
+			}
+			// Используем патч только если он действительно меньше
+			if len(patch) < len(payload) {
+				payload = patch
+				entry.Op = types.DeltaPatch
+				entry.BaseChecksum = base.checksum
+			}
+		}
+
+		entry.Payload = fmt.Sprintf("%s/%06d", deltaPayloadDir, i)
+		if err := writeTarBytes(tarWriter, entry.Payload, payload); err != nil {
+			return nil, err
+		}
+		manifest.Files = append(manifest.Files, entry)
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeTarBytes(tarWriter, deltaManifestName, manifestData); err != nil {
+		return nil, err
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, file.Close()
+}
+
+// ApplyDelta применяет дельту к базовому архиву и распаковывает целевую
+// версию пакета в destDir. Проверяются контрольные суммы базового архива
+// и каждого восстановленного файла, а если целевая версия содержит манифест
+// файлов - и соответствие восстановленных файлов ему.
+func (m *Manager) ApplyDelta(basePath string, baseFormat types.ArchiveFormat, deltaPath, destDir string) (*types.DeltaManifest, error) {
+	tempDir, err := os.MkdirTemp("", "criage-delta-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	deltaDir := filepath.Join(tempDir, "delta")
+	if err := m.ExtractArchive(deltaPath, deltaDir, ""); err != nil {
+		return nil, fmt.Errorf("failed to read delta: %w", err)
+	}
+
+	manifest, err := ReadDeltaManifest(filepath.Join(deltaDir, deltaManifestName))
+	if err != nil {
+		return nil, err
+	}
+
+	baseChecksum, err := fileChecksum(basePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to checksum base archive: %w", err)
+	}
+	if baseChecksum != manifest.BaseChecksum {
+		return nil, fmt.Errorf("base archive checksum mismatch: expected %s, got %s", manifest.BaseChecksum, baseChecksum)
+	}
+
+	baseDir := filepath.Join(tempDir, "base")
+	if err := m.ExtractArchive(basePath, baseDir, baseFormat); err != nil {
+		return nil, fmt.Errorf("failed to extract base archive: %w", err)
+	}
+
+	if err := os.MkdirAll(destDir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	for _, entry := range manifest.Files {
+		target := filepath.Join(destDir, filepath.FromSlash(entry.Path))
+		if !strings.HasPrefix(target, filepath.Clean(destDir)+string(os.PathSeparator)) {
+			return nil, fmt.Errorf("invalid path: %s", entry.Path)
+		}
+
+		if entry.Op == types.DeltaDir {
+			if err := os.MkdirAll(target, os.FileMode(entry.Mode)&0777|0700); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		data, err := m.deltaFileContent(entry, baseDir, deltaDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", entry.Path, err)
+		}
+
+		sum := sha256.Sum256(data)
+		if checksum := hex.EncodeToString(sum[:]); checksum != entry.Checksum {
+			return nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", entry.Path, entry.Checksum, checksum)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(target, data, os.FileMode(entry.Mode)&0777); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := verifyDeltaResult(destDir); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// verifyDeltaResult сверяет восстановленную версию с ее манифестом файлов
+func verifyDeltaResult(dir string) error {
+	metadata := readDeltaMetadata(dir)
+	if metadata == nil || metadata.MerkleRoot == "" {
+		return nil
+	}
+	result, err := VerifyInstallDir(dir, metadata)
+	if err != nil {
+		return fmt.Errorf("failed to verify restored package: %w", err)
+	}
+	if len(result.Modified) > 0 || len(result.Missing) > 0 {
+		return fmt.Errorf("restored package does not match file manifest: modified %v, missing %v", result.Modified, result.Missing)
+	}
+	return nil
+}
+
+// ReadDeltaManifest загружает описание дельты из файла
+func ReadDeltaManifest(path string) (*types.DeltaManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read delta manifest: %w", err)
+	}
+
+	var manifest types.DeltaManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse delta manifest: %w", err)
+	}
+
+	if manifest.FormatVersion > DeltaFormatVersion {
+		return nil, fmt.Errorf("unsupported delta format version: %d", manifest.FormatVersion)
+	}
+
+	return &manifest, nil
+}
+
+// deltaFileContent восстанавливает содержимое файла целевой версии
+func (m *Manager) deltaFileContent(entry types.DeltaFile, baseDir, deltaDir string) ([]byte, error) {
+	if entry.Size < 0 || entry.Size > maxDeltaFileSize {
+		return nil, fmt.Errorf("invalid file size %d", entry.Size)
+	}
+
+	readPayload := func() ([]byte, error) {
+		if entry.Payload == "" || strings.Contains(entry.Payload, "..") {
+			return nil, fmt.Errorf("invalid payload reference: %q", entry.Payload)
+		}
+		return os.ReadFile(filepath.Join(deltaDir, filepath.FromSlash(entry.Payload)))
+	}
+
+	switch entry.Op {
+	case types.DeltaKeep:
+		return os.ReadFile(filepath.Join(baseDir, filepath.FromSlash(entry.Path)))
+	case types.DeltaAdd:
+		payload, err := readPayload()
+		if err != nil {
+			return nil, err
+		}
+		return decodePatch(nil, payload, entry.Size)
+	case types.DeltaPatch:
+		payload, err := readPayload()
+		if err != nil {
+			return nil, err
+		}
+		base, err := os.ReadFile(filepath.Join(baseDir, filepath.FromSlash(entry.Path)))
+		if err != nil {
+			return nil, err
+		}
+		return decodePatch(base, payload, entry.Size)
+	default:
+		return nil, fmt.Errorf("unknown delta operation: %s", entry.Op)
+	}
+}
+
+// encodePatch кодирует target с base в качестве словаря
+func (m *Manager) encodePatch(base, target []byte) ([]byte, error) {
+	// Быстрые уровни zstd плохо используют большие словари, поэтому
+	// для патчей уровень не опускается ниже SpeedBetterCompression
+	level := zstd.EncoderLevelFromZstd(m.config.CompressionLevel)
+	if level < zstd.SpeedBetterCompression {
+		level = zstd.SpeedBetterCompression
+	}
+
+	encoder, err := zstd.NewWriter(nil,
+		zstd.WithEncoderLevel(level),
+		zstd.WithEncoderDictRaw(deltaDictID, base),
+		zstd.WithWindowSize(patchWindowSize(len(base)+len(target))),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create patch encoder: %w", err)
+	}
+	defer encoder.Close()
+
+	return encoder.EncodeAll(target, nil), nil
+}
+
+// decodePatch восстанавливает файл размером size по базовой версии и патчу.
+// Без базовой версии декодирует обычный zstd фрейм. Декодирование прерывается,
+// как только результат превышает size, поэтому поврежденная дельта не исчерпает память.
+func decodePatch(base, patch []byte, size int64) ([]byte, error) {
+	options := []zstd.DOption{
+		zstd.WithDecoderMaxWindow(uint64(patchWindowSize(maxPatchSize * 2))),
+		zstd.WithDecoderConcurrency(1),
+	}
+	if base != nil {
+		options = append(options, zstd.WithDecoderDictRaw(deltaDictID, base))
+	}
+
+	decoder, err := zstd.NewReader(bytes.NewReader(patch), options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create patch decoder: %w", err)
+	}
+	defer decoder.Close()
+
+	data, err := io.ReadAll(io.LimitReader(decoder, size+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) != size {
+		return nil, fmt.Errorf("decoded size does not match expected %d", size)
+	}
+	return data, nil
+}
+
+// patchWindowSize возвращает размер окна, покрывающий словарь и данные
+func patchWindowSize(n int) int {
+	size := zstd.MinWindowSize
+	if n > size {
+		size = 1 << bits.Len(uint(n-1))
+	}
+	if size > zstd.MaxWindowSize {
+		size = zstd.MaxWindowSize
+	}
+	return size
+}
+
+// scanDeltaFiles собирает информацию о файлах распакованного пакета
+func scanDeltaFiles(root string) (map[string]deltaFileInfo, error) {
+	files := make(map[string]deltaFileInfo)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		checksum, err := fileChecksum(path)
+		if err != nil {
+			return err
+		}
+
+		files[filepath.ToSlash(relPath)] = deltaFileInfo{
+			mode:     info.Mode(),
+			size:     info.Size(),
+			checksum: checksum,
+		}
+		return nil
+	})
+	return files, err
+}
+
+// scanDeltaDirs собирает директории распакованного пакета
+func scanDeltaDirs(root string) ([]types.DeltaFile, error) {
+	var dirs []types.DeltaFile
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root || !info.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		dirs = append(dirs, types.DeltaFile{
+			Path: filepath.ToSlash(relPath),
+			Op:   types.DeltaDir,
+			Mode: uint32(info.Mode().Perm()),
+		})
+		return nil
+	})
+	return dirs, err
+}
+
+// readDeltaMetadata читает метаданные распакованного пакета, если они есть
+func readDeltaMetadata(dir string) *types.PackageMetadata {
+	data, err := os.ReadFile(filepath.Join(dir, ".criage-metadata.json"))
+	if err != nil {
+		return nil
+	}
+	var metadata types.PackageMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil
+	}
+	return &metadata
+}
+
+// writeTarBytes записывает данные в tar архив как обычный файл
+func writeTarBytes(tarWriter *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{
+		Name:     name,
+		Mode:     0644,
+		Size:     int64(len(data)),
+		ModTime:  time.Now(),
+		Typeflag: tar.TypeReg,
+	}
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tarWriter.Write(data)
+	return err
+}
+
+// fileChecksum вычисляет SHA-256 файла в hex представлении
+func fileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
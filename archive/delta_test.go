@@ -0,0 +1,208 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+// writeFiles создает файлы с содержимым в директории dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// buildArchive упаковывает директорию source в архив tar.zst с метаданными
+func buildArchive(t *testing.T, manager *Manager, source, output string) {
+	t.Helper()
+	metadata := &types.PackageMetadata{CreatedBy: "criage"}
+	if err := manager.CreateArchiveWithMetadata(source, output, types.FormatTarZst, nil, nil, metadata); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeltaRoundTrip(t *testing.T) {
+	manager := newTestManager(t)
+	dir := t.TempDir()
+	large := strings.Repeat("criage delta payload line\n", 4096)
+
+	baseSource := filepath.Join(dir, "base")
+	writeFiles(t, baseSource, map[string]string{
+		"same.txt":    "unchanged",
+		"lib/data.db": large,
+		"removed.txt": "old",
+	})
+	targetSource := filepath.Join(dir, "target")
+	writeFiles(t, targetSource, map[string]string{
+		"same.txt":    "unchanged",
+		"lib/data.db": large + "appended line\n",
+		"added.txt":   "new",
+	})
+	if err := os.MkdirAll(filepath.Join(targetSource, "cache", "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	basePath := filepath.Join(dir, "pkg-1.0.0.tar.zst")
+	targetPath := filepath.Join(dir, "pkg-1.1.0.tar.zst")
+	deltaPath := filepath.Join(dir, "pkg.delta")
+	buildArchive(t, manager, baseSource, basePath)
+	buildArchive(t, manager, targetSource, targetPath)
+
+	manifest, err := manager.CreateDelta(basePath, types.FormatTarZst, targetPath, types.FormatTarZst, deltaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops := make(map[string]types.DeltaOperation)
+	for _, entry := range manifest.Files {
+		ops[entry.Path] = entry.Op
+	}
+	want := map[string]types.DeltaOperation{
+		"same.txt":    types.DeltaKeep,
+		"lib/data.db": types.DeltaPatch,
+		"added.txt":   types.DeltaAdd,
+		"cache/empty": types.DeltaDir,
+	}
+	for path, op := range want {
+		if ops[path] != op {
+			t.Errorf("op for %s = %q, want %q", path, ops[path], op)
+		}
+	}
+
+	destDir := filepath.Join(dir, "out")
+	if _, err := manager.ApplyDelta(basePath, types.FormatTarZst, deltaPath, destDir); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedDir := filepath.Join(dir, "expected")
+	if err := manager.ExtractArchive(targetPath, expectedDir, types.FormatTarZst); err != nil {
+		t.Fatal(err)
+	}
+	expected, err := scanDeltaFiles(expectedDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := scanDeltaFiles(destDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored) != len(expected) {
+		t.Errorf("restored %d files, want %d", len(restored), len(expected))
+	}
+	for path, info := range expected {
+		if restored[path].checksum != info.checksum {
+			t.Errorf("%s differs from target version", path)
+		}
+	}
+	if info, err := os.Stat(filepath.Join(destDir, "cache", "empty")); err != nil || !info.IsDir() {
+		t.Errorf("empty directory not restored: %v", err)
+	}
+}
+
+func TestApplyDeltaBaseMismatch(t *testing.T) {
+	manager := newTestManager(t)
+	dir := t.TempDir()
+
+	for name, content := range map[string]string{"base": "1", "target": "2", "other": "3"} {
+		writeFiles(t, filepath.Join(dir, name), map[string]string{"file.txt": content})
+		buildArchive(t, manager, filepath.Join(dir, name), filepath.Join(dir, name+".tar.zst"))
+	}
+
+	deltaPath := filepath.Join(dir, "pkg.delta")
+	if _, err := manager.CreateDelta(filepath.Join(dir, "base.tar.zst"), types.FormatTarZst, filepath.Join(dir, "target.tar.zst"), types.FormatTarZst, deltaPath); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := manager.ApplyDelta(filepath.Join(dir, "other.tar.zst"), types.FormatTarZst, deltaPath, filepath.Join(dir, "out"))
+	if err == nil || !strings.Contains(err.Error(), "base archive checksum mismatch") {
+		t.Errorf("ApplyDelta() = %v, want base checksum mismatch", err)
+	}
+}
+
+func TestApplyDeltaRejectsCorruptedPayload(t *testing.T) {
+	manager := newTestManager(t)
+	dir := t.TempDir()
+	writeFiles(t, filepath.Join(dir, "base"), map[string]string{"file.txt": "base"})
+	basePath := filepath.Join(dir, "base.tar.zst")
+	buildArchive(t, manager, filepath.Join(dir, "base"), basePath)
+	baseChecksum, err := fileChecksum(basePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := []byte("restored content")
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+	bomb := manager.zstdEncoder.EncodeAll(make([]byte, 8<<20), nil)
+
+	tests := []struct {
+		name     string
+		payload  []byte
+		size     int64
+		checksum string
+	}{
+		{"garbage", []byte("not a zstd frame"), int64(len(content)), checksum},
+		{"checksum", manager.zstdEncoder.EncodeAll([]byte("other content"), nil), int64(len("other content")), checksum},
+		{"oversized", bomb, int64(len(content)), checksum},
+		{"size limit", manager.zstdEncoder.EncodeAll(content, nil), maxDeltaFileSize + 1, checksum},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deltaPath := filepath.Join(t.TempDir(), "pkg.delta")
+			writeTestDelta(t, deltaPath, &types.DeltaManifest{
+				FormatVersion: DeltaFormatVersion,
+				BaseChecksum:  baseChecksum,
+				Files: []types.DeltaFile{{
+					Path:     "file.txt",
+					Op:       types.DeltaAdd,
+					Mode:     0644,
+					Size:     tt.size,
+					Checksum: tt.checksum,
+					Payload:  deltaPayloadDir + "/000000",
+				}},
+			}, tt.payload)
+
+			if _, err := manager.ApplyDelta(basePath, types.FormatTarZst, deltaPath, t.TempDir()); err == nil {
+				t.Error("ApplyDelta() accepted corrupted payload")
+			}
+		})
+	}
+}
+
+// writeTestDelta записывает дельту с одним файлом данных
+func writeTestDelta(t *testing.T, path string, manifest *types.DeltaManifest, payload []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	tarWriter := tar.NewWriter(&buf)
+	if err := writeTarBytes(tarWriter, manifest.Files[0].Payload, payload); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeTarBytes(tarWriter, deltaManifestName, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
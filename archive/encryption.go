@@ -0,0 +1,85 @@
+package archive
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/criage-oss/criage-common/encryption"
+)
+
+// EncryptArchive шифрует готовый архив для получателей из конфигурации.
+// Формат исходного архива сохраняется внутри и определяется при извлечении.
+func (m *Manager) EncryptArchive(archivePath, outputPath string) error {
+	if len(m.config.EncryptionRecipients) == 0 {
+		return fmt.Errorf("failed to encrypt archive: %w", encryption.ErrNoRecipients)
+	}
+
+	recipients, err := encryption.ParseRecipients(m.config.EncryptionRecipients)
+	if err != nil {
+		return fmt.Errorf("failed to parse encryption recipients: %w", err)
+	}
+
+	return m.EncryptArchiveFor(archivePath, outputPath, recipients)
+}
+
+// EncryptArchiveFor шифрует готовый архив для указанных получателей
+func (m *Manager) EncryptArchiveFor(archivePath, outputPath string, recipients []*encryption.Recipient) error {
+	encrypted, err := encryption.IsEncryptedFile(archivePath)
+	if err != nil {
+		return err
+	}
+	if encrypted {
+		return fmt.Errorf("archive %s is already encrypted", archivePath)
+	}
+
+	if err := encryption.EncryptFile(archivePath, outputPath, recipients); err != nil {
+		os.Remove(outputPath)
+		return fmt.Errorf("failed to encrypt archive: %w", err)
+	}
+	return nil
+}
+
+// decryptIfEncrypted расшифровывает зашифрованный архив во временный файл.
+// Для незашифрованного архива возвращает пустой путь.
+func (m *Manager) decryptIfEncrypted(archivePath string) (string, error) {
+	encrypted, err := encryption.IsEncryptedFile(archivePath)
+	if err != nil || !encrypted {
+		// Ошибку открытия файла вернет распаковщик
+		return "", nil
+	}
+
+	identities, err := m.loadIdentities()
+	if err != nil {
+		return "", err
+	}
+
+	temp, err := os.CreateTemp("", "criage-decrypted-*")
+	if err != nil {
+		return "", err
+	}
+	temp.Close()
+
+	if err := encryption.DecryptFile(archivePath, temp.Name(), identities); err != nil {
+		os.Remove(temp.Name())
+		return "", fmt.Errorf("failed to decrypt archive: %w", err)
+	}
+
+	return temp.Name(), nil
+}
+
+// loadIdentities загружает закрытые ключи из файлов конфигурации
+func (m *Manager) loadIdentities() ([]*encryption.Identity, error) {
+	if len(m.config.IdentityFiles) == 0 {
+		return nil, fmt.Errorf("archive is encrypted but no identity files are configured: %w", encryption.ErrNoIdentity)
+	}
+
+	var identities []*encryption.Identity
+	for _, path := range m.config.IdentityFiles {
+		loaded, err := encryption.LoadIdentities(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load identity file: %w", err)
+		}
+		identities = append(identities, loaded...)
+	}
+	return identities, nil
+}
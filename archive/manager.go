@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"archive/zip"
 	"compress/gzip"
+	"crypto/ed25519"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -16,6 +17,7 @@ import (
 	"github.com/ulikunitz/xz"
 
 	"github.com/criage-oss/criage-common/config"
+	"github.com/criage-oss/criage-common/signing"
 	"github.com/criage-oss/criage-common/types"
 )
 
@@ -126,6 +128,17 @@ func (m *Manager) ExtractArchive(archivePath, destDir string, format types.Archi
 		return fmt.Errorf("failed to create destination directory: %w", err)
 	}
 
+	// Зашифрованный пакет расшифровывается во временный файл
+	decryptedPath, err := m.decryptIfEncrypted(archivePath)
+	if err != nil {
+		return err
+	}
+	if decryptedPath != "" {
+		defer os.Remove(decryptedPath)
+		archivePath = decryptedPath
+		format = m.detectCriageFormat(decryptedPath)
+	}
+
 	switch format {
 	case types.FormatZip:
 		return m.extractZip(archivePath, destDir)
@@ -270,8 +283,22 @@ func (m *Manager) extractFile(src io.Reader, destPath string, mode os.FileMode)
 	return err
 }
 
-// ExtractMetadataFromArchive извлекает метаданные из архива
+// ExtractMetadataFromArchive извлекает метаданные из архива и проверяет их
+// совместимость с версией менеджера. Для несовместимого пакета возвращается
+// *types.IncompatibleError с прочитанными метаданными.
 func (m *Manager) ExtractMetadataFromArchive(archivePath string, format types.ArchiveFormat) (*types.PackageMetadata, error) {
+	metadata, err := m.ReadMetadataFromArchive(archivePath, format)
+	if err != nil {
+		return nil, err
+	}
+	if err := metadata.CheckCompatibility(m.version); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// ReadMetadataFromArchive извлекает метаданные из архива без проверки совместимости
+func (m *Manager) ReadMetadataFromArchive(archivePath string, format types.ArchiveFormat) (*types.PackageMetadata, error) {
 	// Создаем временную директорию
 	tempDir, err := os.MkdirTemp("", "criage-metadata-*")
 	if err != nil {
@@ -325,8 +352,77 @@ func (m *Manager) createMetadataFromManifest(manifestPath string) (*types.Packag
 	}, nil
 }
 
-// CreateArchiveWithMetadata создает архив с встроенными метаданными
+// CreateArchiveWithMetadata создает архив с встроенными метаданными.
+// Манифест пакета в metadata проверяется до начала сборки. Манифест файлов и корень дерева Меркла записываются в metadata.
 func (m *Manager) CreateArchiveWithMetadata(sourceDir, outputPath string, format types.ArchiveFormat, includeFiles, excludeFiles []string, metadata *types.PackageMetadata) error {
+	if err := validateMetadata(metadata); err != nil {
+		return err
+	}
+	if err := m.fillFileManifest(sourceDir, includeFiles, excludeFiles, metadata); err != nil {
+		return err
+	}
+	metadataData, err := signing.EncodeMetadata(metadata)
+	if err != nil {
+		return err
+	}
+	return m.createPackage(sourceDir, outputPath, format, includeFiles, excludeFiles, metadataData, nil)
+}
+
+// CreateSignedArchive создает архив с метаданными и встроенной подписью ed25519.
+// Подпись покрывает точное содержимое файла .criage-metadata.json в архиве.
+func (m *Manager) CreateSignedArchive(sourceDir, outputPath string, format types.ArchiveFormat, includeFiles, excludeFiles []string, metadata *types.PackageMetadata, privateKey ed25519.PrivateKey) (*types.PackageSignature, error) {
+	if err := validateMetadata(metadata); err != nil {
+		return nil, err
+	}
+	if err := m.fillFileManifest(sourceDir, includeFiles, excludeFiles, metadata); err != nil {
+		return nil, err
+	}
+
+	metadataData, err := signing.EncodeMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := signing.Sign(metadataData, privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign package: %w", err)
+	}
+
+	if err := m.createPackage(sourceDir, outputPath, format, includeFiles, excludeFiles, metadataData, signature); err != nil {
+		return nil, err
+	}
+	return signature, nil
+}
+
+// validateMetadata проверяет метаданные перед сборкой архива. Манифест сборки
+// архиватор не использует (формат передается аргументом), поэтому проверяется
+// только манифест пакета, который читают при установке.
+func validateMetadata(metadata *types.PackageMetadata) error {
+	if metadata == nil {
+		return fmt.Errorf("package metadata is required")
+	}
+	if metadata.PackageManifest == nil {
+		return nil
+	}
+	return metadata.PackageManifest.Validate().Err()
+}
+
+// fillFileManifest строит манифест файлов для последующей проверки установки
+func (m *Manager) fillFileManifest(sourceDir string, includeFiles, excludeFiles []string, metadata *types.PackageMetadata) error {
+	files, err := m.BuildFileManifest(sourceDir, includeFiles, excludeFiles)
+	if err != nil {
+		return fmt.Errorf("failed to build file manifest: %w", err)
+	}
+	root, err := MerkleRoot(files)
+	if err != nil {
+		return err
+	}
+	metadata.Files = files
+	metadata.MerkleRoot = root
+	return nil
+}
+
+// createPackage создает архив с файлом метаданных metadataData и, при наличии, подписью
+func (m *Manager) createPackage(sourceDir, outputPath string, format types.ArchiveFormat, includeFiles, excludeFiles []string, metadataData []byte, signature *types.PackageSignature) error {
 	// Создаем временный файл метаданных
 	tempDir, err := os.MkdirTemp("", "criage-build-*")
 	if err != nil {
@@ -335,31 +431,46 @@ func (m *Manager) CreateArchiveWithMetadata(sourceDir, outputPath string, format
 	defer os.RemoveAll(tempDir)
 
 	metadataFile := filepath.Join(tempDir, ".criage-metadata.json")
-	metadataData, err := json.MarshalIndent(metadata, "", "  ")
-	if err != nil {
+	if err := os.WriteFile(metadataFile, metadataData, 0600); err != nil {
 		return err
 	}
 
-	if err := os.WriteFile(metadataFile, metadataData, 0600); err != nil {
-		return err
+	extraFiles := []archiveFile{{source: metadataFile, name: ".criage-metadata.json"}}
+
+	if signature != nil {
+		signatureFile := filepath.Join(tempDir, SignatureFileName)
+		signatureData, err := json.MarshalIndent(signature, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(signatureFile, signatureData, 0600); err != nil {
+			return err
+		}
+		extraFiles = append(extraFiles, archiveFile{source: signatureFile, name: SignatureFileName})
 	}
 
 	// Создаем архив
-	return m.createArchive(sourceDir, outputPath, format, includeFiles, excludeFiles, metadataFile)
+	return m.createArchive(sourceDir, outputPath, format, includeFiles, excludeFiles, extraFiles)
+}
+
+// archiveFile служебный файл, добавляемый в корень архива
+type archiveFile struct {
+	source string
+	name   string
 }
 
 // createArchive создает архив
-func (m *Manager) createArchive(sourceDir, outputPath string, format types.ArchiveFormat, includeFiles, excludeFiles []string, metadataFile string) error {
+func (m *Manager) createArchive(sourceDir, outputPath string, format types.ArchiveFormat, includeFiles, excludeFiles []string, extraFiles []archiveFile) error {
 	switch format {
 	case types.FormatZip:
-		return m.createZipArchive(sourceDir, outputPath, includeFiles, excludeFiles, metadataFile)
+		return m.createZipArchive(sourceDir, outputPath, includeFiles, excludeFiles, extraFiles)
 	default:
-		return m.createTarArchive(sourceDir, outputPath, format, includeFiles, excludeFiles, metadataFile)
+		return m.createTarArchive(sourceDir, outputPath, format, includeFiles, excludeFiles, extraFiles)
 	}
 }
 
 // createTarArchive создает tar архив с сжатием
-func (m *Manager) createTarArchive(sourceDir, outputPath string, format types.ArchiveFormat, includeFiles, excludeFiles []string, metadataFile string) error {
+func (m *Manager) createTarArchive(sourceDir, outputPath string, format types.ArchiveFormat, includeFiles, excludeFiles []string, extraFiles []archiveFile) error {
 	file, err := os.Create(outputPath)
 	if err != nil {
 		return err
@@ -367,7 +478,7 @@ func (m *Manager) createTarArchive(sourceDir, outputPath string, format types.Ar
 	defer file.Close()
 
 	// Создаем компрессор
-	var writer io.Writer
+	var writer io.WriteCloser
 	switch format {
 	case types.FormatTarZst:
 		// Для zstd используем encoder напрямую
@@ -383,19 +494,20 @@ func (m *Manager) createTarArchive(sourceDir, outputPath string, format types.Ar
 	case types.FormatTarGZ:
 		writer = gzip.NewWriter(file)
 	default:
-		writer = file
+		writer = nopWriteCloser{file}
 	}
 
 	tarWriter := tar.NewWriter(writer)
-	defer tarWriter.Close()
 
-	// Добавляем метаданные
-	if err := m.addFileToTar(tarWriter, metadataFile, ".criage-metadata.json"); err != nil {
-		return err
+	// Добавляем метаданные и подпись
+	for _, extra := range extraFiles {
+		if err := m.addFileToTar(tarWriter, extra.source, extra.name); err != nil {
+			return err
+		}
 	}
 
 	// Добавляем файлы из источника
-	return filepath.Walk(sourceDir, func(path string, info os.FileInfo, err error) error {
+	err = filepath.Walk(sourceDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -420,10 +532,29 @@ func (m *Manager) createTarArchive(sourceDir, outputPath string, format types.Ar
 
 		return m.addFileToTar(tarWriter, path, relPath)
 	})
+	if err != nil {
+		return err
+	}
+
+	// Закрываем tar и компрессор, чтобы дописать завершающие блоки
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return file.Close()
 }
 
+// nopWriteCloser оборачивает io.Writer без операции закрытия
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
 // createZipArchive создает ZIP архив
-func (m *Manager) createZipArchive(sourceDir, outputPath string, includeFiles, excludeFiles []string, metadataFile string) error {
+func (m *Manager) createZipArchive(sourceDir, outputPath string, includeFiles, excludeFiles []string, extraFiles []archiveFile) error {
 	file, err := os.Create(outputPath)
 	if err != nil {
 		return err
@@ -433,9 +564,11 @@ func (m *Manager) createZipArchive(sourceDir, outputPath string, includeFiles, e
 	zipWriter := zip.NewWriter(file)
 	defer zipWriter.Close()
 
-	// Добавляем метаданные
-	if err := m.addFileToZip(zipWriter, metadataFile, ".criage-metadata.json"); err != nil {
-		return err
+	// Добавляем метаданные и подпись
+	for _, extra := range extraFiles {
+		if err := m.addFileToZip(zipWriter, extra.source, extra.name); err != nil {
+			return err
+		}
 	}
 
 	// Добавляем файлы из источника
@@ -498,17 +631,26 @@ func (m *Manager) addFileToTar(tarWriter *tar.Writer, sourcePath, archivePath st
 	return err
 }
 
-// addFileToZip добавляет файл в ZIP архив
+// addFileToZip добавляет файл в ZIP архив. Права доступа сохраняются в заголовке,
+// чтобы исполняемые файлы оставались исполняемыми после распаковки.
 func (m *Manager) addFileToZip(zipWriter *zip.Writer, sourcePath, archivePath string) error {
 	info, err := os.Stat(sourcePath)
 	if err != nil {
 		return err
 	}
 
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = filepath.ToSlash(archivePath)
+
 	if info.IsDir() {
-		_, err := zipWriter.Create(archivePath + "/")
+		header.Name += "/"
+		_, err := zipWriter.CreateHeader(header)
 		return err
 	}
+	header.Method = zip.Deflate
 
 	file, err := os.Open(sourcePath)
 	if err != nil {
@@ -516,7 +658,7 @@ func (m *Manager) addFileToZip(zipWriter *zip.Writer, sourcePath, archivePath st
 	}
 	defer file.Close()
 
-	writer, err := zipWriter.Create(archivePath)
+	writer, err := zipWriter.CreateHeader(header)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,78 @@
+package archive
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+func TestExtractMetadataFromArchiveIncompatible(t *testing.T) {
+	manager := newTestManager(t)
+	output := filepath.Join(t.TempDir(), "package.tar.zst")
+	metadata := &types.PackageMetadata{
+		CreatedBy:       "criage",
+		Version:         "1.0.0",
+		PackageManifest: &types.PackageManifest{Name: "app", Version: "2.1.0", MinVersion: "1.4.0"},
+	}
+	if err := manager.CreateArchiveWithMetadata(writeTestSource(t), output, types.FormatTarZst, nil, nil, metadata); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := manager.ExtractMetadataFromArchive(output, types.FormatTarZst)
+	var incompatible *types.IncompatibleError
+	if !errors.As(err, &incompatible) {
+		t.Fatalf("ExtractMetadataFromArchive() = %v, want IncompatibleError", err)
+	}
+	if incompatible.Package != "app" || incompatible.Version != "2.1.0" || incompatible.Required != "1.4.0" {
+		t.Errorf("IncompatibleError = %+v", incompatible)
+	}
+	if incompatible.Metadata == nil || incompatible.Metadata.MerkleRoot == "" {
+		t.Error("IncompatibleError does not carry the archive metadata")
+	}
+
+	// Без проверки совместимости метаданные читаются
+	if _, err := manager.ReadMetadataFromArchive(output, types.FormatTarZst); err != nil {
+		t.Errorf("ReadMetadataFromArchive() = %v", err)
+	}
+
+	// Совместимая версия менеджера принимает архив
+	newer, err := NewManager(manager.config, "1.4.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newer.ExtractMetadataFromArchive(output, types.FormatTarZst); err != nil {
+		t.Errorf("ExtractMetadataFromArchive() with criage 1.4.0 = %v", err)
+	}
+}
+
+func TestCreateArchiveWithMetadataValidation(t *testing.T) {
+	manager := newTestManager(t)
+	source := writeTestSource(t)
+	output := filepath.Join(t.TempDir(), "package.tar.zst")
+
+	// Формат сжатия передается аргументом и в манифесте сборки не обязателен
+	metadata := &types.PackageMetadata{
+		CreatedBy:       "criage",
+		PackageManifest: &types.PackageManifest{Name: "app", Version: "1.0.0"},
+		BuildManifest:   &types.BuildManifest{Name: "app", Version: "1.0.0"},
+	}
+	if err := manager.CreateArchiveWithMetadata(source, output, types.FormatTarZst, nil, nil, metadata); err != nil {
+		t.Errorf("CreateArchiveWithMetadata() = %v", err)
+	}
+
+	invalid := &types.PackageMetadata{PackageManifest: &types.PackageManifest{Name: "App", Version: "1.0.0"}}
+	err := manager.CreateArchiveWithMetadata(source, output, types.FormatTarZst, nil, nil, invalid)
+	var diagnosticsErr *types.DiagnosticsError
+	if !errors.As(err, &diagnosticsErr) {
+		t.Errorf("CreateArchiveWithMetadata() with invalid name = %v, want DiagnosticsError", err)
+	}
+
+	if err := manager.CreateArchiveWithMetadata(source, output, types.FormatTarZst, nil, nil, nil); err == nil {
+		t.Error("CreateArchiveWithMetadata() accepted nil metadata")
+	}
+	if _, err := manager.CreateSignedArchive(source, output, types.FormatTarZst, nil, nil, nil, nil); err == nil {
+		t.Error("CreateSignedArchive() accepted nil metadata")
+	}
+}
@@ -0,0 +1,266 @@
+package archive
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+// Префиксы узлов дерева Меркла (защита от подмены листа внутренним узлом)
+const (
+	merkleLeafPrefix = 0x00
+	merkleNodePrefix = 0x01
+)
+
+// executableBit бит исполнения для владельца. Остальные биты прав зависят от
+// umask при распаковке, поэтому при проверке установки не сравниваются.
+const executableBit = 0100
+
+// VerifyResult результат проверки установленного пакета
+type VerifyResult struct {
+	Modified []string `json:"modified,omitempty"`
+	Missing  []string `json:"missing,omitempty"`
+	// Файлы, ставшие исполняемыми или переставшие быть исполняемыми
+	ModeChanged []string `json:"modeChanged,omitempty"`
+
+	// Файлы, отсутствующие в манифесте (созданы после установки)
+	Extra []string `json:"extra,omitempty"`
+}
+
+// OK возвращает true, если файлы пакета не изменены
+func (r *VerifyResult) OK() bool {
+	return len(r.Modified) == 0 && len(r.Missing) == 0 && len(r.ModeChanged) == 0
+}
+
+// BuildFileManifest строит манифест файлов, которые попадут в архив
+func (m *Manager) BuildFileManifest(sourceDir string, includeFiles, excludeFiles []string) ([]types.FileManifestEntry, error) {
+	var entries []types.FileManifestEntry
+
+	err := filepath.Walk(sourceDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path == sourceDir {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(sourceDir, path)
+		if err != nil {
+			return err
+		}
+
+		// Те же фильтры, что и при создании архива
+		if m.shouldExclude(relPath, includeFiles, excludeFiles) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		checksum, err := fileChecksum(path)
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, types.FileManifestEntry{
+			Path:   filepath.ToSlash(relPath),
+			Size:   info.Size(),
+			Mode:   uint32(info.Mode().Perm()),
+			SHA256: checksum,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries, nil
+}
+
+// MerkleRoot вычисляет корень дерева Меркла над манифестом файлов.
+// Листья упорядочиваются по пути, непарный узел переносится на уровень выше.
+func MerkleRoot(entries []types.FileManifestEntry) (string, error) {
+	sorted := make([]types.FileManifestEntry, len(entries))
+	copy(sorted, entries)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+
+	if len(sorted) == 0 {
+		sum := sha256.Sum256(nil)
+		return hex.EncodeToString(sum[:]), nil
+	}
+
+	level := make([][]byte, 0, len(sorted))
+	for i, entry := range sorted {
+		if i > 0 && sorted[i-1].Path == entry.Path {
+			return "", fmt.Errorf("duplicate path in file manifest: %s", entry.Path)
+		}
+		leaf, err := merkleLeaf(entry)
+		if err != nil {
+			return "", err
+		}
+		level = append(level, leaf)
+	}
+
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			hash := sha256.New()
+			hash.Write([]byte{merkleNodePrefix})
+			hash.Write(level[i])
+			hash.Write(level[i+1])
+			next = append(next, hash.Sum(nil))
+		}
+		level = next
+	}
+
+	return hex.EncodeToString(level[0]), nil
+}
+
+// merkleLeaf вычисляет хеш листа для записи манифеста
+func merkleLeaf(entry types.FileManifestEntry) ([]byte, error) {
+	content, err := hex.DecodeString(entry.SHA256)
+	if err != nil || len(content) != sha256.Size {
+		return nil, fmt.Errorf("invalid sha256 for %s: %q", entry.Path, entry.SHA256)
+	}
+
+	var sizeMode [12]byte
+	binary.BigEndian.PutUint64(sizeMode[:8], uint64(entry.Size))
+	binary.BigEndian.PutUint32(sizeMode[8:], entry.Mode)
+
+	hash := sha256.New()
+	hash.Write([]byte{merkleLeafPrefix})
+	hash.Write([]byte(entry.Path))
+	hash.Write([]byte{0})
+	hash.Write(sizeMode[:])
+	hash.Write(content)
+	return hash.Sum(nil), nil
+}
+
+// VerifyInstallDir проверяет директорию установки по манифесту файлов из метаданных.
+// Из прав доступа сравнивается только признак исполняемого файла.
+func VerifyInstallDir(installDir string, metadata *types.PackageMetadata) (*VerifyResult, error) {
+	if metadata == nil || len(metadata.Files) == 0 || metadata.MerkleRoot == "" {
+		return nil, fmt.Errorf("package metadata has no file manifest")
+	}
+
+	// Манифест не должен расходиться с корнем, иначе метаданные подменены
+	root, err := MerkleRoot(metadata.Files)
+	if err != nil {
+		return nil, err
+	}
+	if root != metadata.MerkleRoot {
+		return nil, fmt.Errorf("file manifest does not match merkle root: expected %s, got %s", metadata.MerkleRoot, root)
+	}
+
+	// Пути манифеста не должны выходить за пределы директории установки
+	for _, entry := range metadata.Files {
+		if err := validateManifestPath(entry.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &VerifyResult{}
+	known := make(map[string]bool, len(metadata.Files))
+
+	for _, entry := range metadata.Files {
+		known[entry.Path] = true
+		path := filepath.Join(installDir, filepath.FromSlash(entry.Path))
+
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			result.Missing = append(result.Missing, entry.Path)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if info.Size() != entry.Size {
+			result.Modified = append(result.Modified, entry.Path)
+			continue
+		}
+
+		checksum, err := fileChecksum(path)
+		if err != nil {
+			return nil, err
+		}
+		if checksum != entry.SHA256 {
+			result.Modified = append(result.Modified, entry.Path)
+			continue
+		}
+
+		if uint32(info.Mode().Perm())&executableBit != entry.Mode&executableBit {
+			result.ModeChanged = append(result.ModeChanged, entry.Path)
+		}
+	}
+
+	err = filepath.Walk(installDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(installDir, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if !known[relPath] && relPath != ".criage-metadata.json" && relPath != SignatureFileName {
+			result.Extra = append(result.Extra, relPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// validateManifestPath проверяет, что путь манифеста относительный, записан
+// через "/" в нормализованном виде и не содержит переходов ".."
+func validateManifestPath(name string) error {
+	switch {
+	case name == "" || name == ".":
+		return fmt.Errorf("empty path in file manifest")
+	case path.IsAbs(name) || filepath.IsAbs(name) || filepath.VolumeName(name) != "":
+		return fmt.Errorf("absolute path in file manifest: %s", name)
+	case strings.Contains(name, "\\"):
+		return fmt.Errorf("invalid path in file manifest: %s", name)
+	case name == ".." || strings.HasPrefix(name, "../") || strings.Contains(name, "/../") || strings.HasSuffix(name, "/.."):
+		return fmt.Errorf("path escapes install directory in file manifest: %s", name)
+	case path.Clean(name) != name:
+		return fmt.Errorf("path is not clean in file manifest: %s", name)
+	}
+	return nil
+}
+
+// VerifyInstallation проверяет директорию установки по архиву, из которого она была установлена
+func (m *Manager) VerifyInstallation(archivePath string, format types.ArchiveFormat, installDir string) (*VerifyResult, error) {
+	metadata, err := m.ExtractMetadataFromArchive(archivePath, format)
+	if err != nil {
+		return nil, err
+	}
+	return VerifyInstallDir(installDir, metadata)
+}
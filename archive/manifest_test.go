@@ -0,0 +1,154 @@
+package archive
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/criage-oss/criage-common/config"
+	"github.com/criage-oss/criage-common/types"
+)
+
+// newTestManager создает менеджер архивов с настройками по умолчанию
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	manager, err := NewManager(config.DefaultConfig(), "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return manager
+}
+
+// writeTestSource создает исходную директорию с обычным и исполняемым файлом
+func writeTestSource(t *testing.T) string {
+	t.Helper()
+	source := filepath.Join(t.TempDir(), "src")
+	if err := os.MkdirAll(filepath.Join(source, "bin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(source, "README"), []byte("readme"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(source, "bin", "run"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return source
+}
+
+func TestZipArchiveKeepsModes(t *testing.T) {
+	manager := newTestManager(t)
+	source := writeTestSource(t)
+	output := filepath.Join(t.TempDir(), "package.zip")
+
+	metadata := &types.PackageMetadata{CreatedBy: "criage"}
+	if err := manager.CreateArchiveWithMetadata(source, output, types.FormatZip, nil, nil, metadata); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := zip.OpenReader(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	for _, file := range reader.File {
+		if file.Name == "bin/run" && file.Mode().Perm() != 0755 {
+			t.Errorf("bin/run mode in archive = %v, want %v", file.Mode().Perm(), os.FileMode(0755))
+		}
+		if strings.Contains(file.Name, "\\") {
+			t.Errorf("archive entry %q uses backslashes", file.Name)
+		}
+	}
+
+	installDir := t.TempDir()
+	if err := manager.ExtractArchive(output, installDir, types.FormatZip); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(filepath.Join(installDir, "bin", "run"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&executableBit == 0 {
+		t.Errorf("extracted bin/run mode = %v, want executable", info.Mode().Perm())
+	}
+
+	result, err := VerifyInstallDir(installDir, metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.OK() {
+		t.Errorf("VerifyInstallDir() = %+v, want no changes", result)
+	}
+}
+
+func TestVerifyInstallDirModes(t *testing.T) {
+	manager := newTestManager(t)
+	installDir := writeTestSource(t)
+	files, err := manager.BuildFileManifest(installDir, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := MerkleRoot(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata := &types.PackageMetadata{Files: files, MerkleRoot: root}
+
+	// Права, урезанные umask, изменением не считаются
+	if err := os.Chmod(filepath.Join(installDir, "README"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(installDir, "bin", "run"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	result, err := VerifyInstallDir(installDir, metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.ModeChanged) != 0 {
+		t.Errorf("ModeChanged = %v, want none", result.ModeChanged)
+	}
+
+	// Потеря признака исполняемого файла считается изменением
+	if err := os.Chmod(filepath.Join(installDir, "bin", "run"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err = VerifyInstallDir(installDir, metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.ModeChanged) != 1 || result.ModeChanged[0] != "bin/run" {
+		t.Errorf("ModeChanged = %v, want [bin/run]", result.ModeChanged)
+	}
+}
+
+func TestVerifyInstallDirRejectsUnsafePaths(t *testing.T) {
+	installDir := t.TempDir()
+
+	tests := []string{
+		"../outside",
+		"lib/../../outside",
+		"/etc/passwd",
+		"lib/./file",
+		"lib\\file",
+		"",
+	}
+	for _, name := range tests {
+		t.Run(name, func(t *testing.T) {
+			files := []types.FileManifestEntry{{
+				Path:   name,
+				Size:   0,
+				Mode:   0644,
+				SHA256: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			}}
+			root, err := MerkleRoot(files)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := VerifyInstallDir(installDir, &types.PackageMetadata{Files: files, MerkleRoot: root}); err == nil {
+				t.Errorf("VerifyInstallDir() accepted path %q", name)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/criage-oss/criage-common/signing"
+	"github.com/criage-oss/criage-common/types"
+)
+
+// SignatureFileName имя файла встроенной подписи в архиве
+const SignatureFileName = ".criage-signature.json"
+
+// VerifyArchiveSignature проверяет подпись архива из репозитория repoName.
+// Используется встроенная подпись, при ее отсутствии - отсоединенная <archive>.sig.
+// Подпись проверяется по точному содержимому файла метаданных, после чего
+// содержимое архива сверяется с подписанным манифестом файлов.
+func (m *Manager) VerifyArchiveSignature(archivePath string, format types.ArchiveFormat, store *signing.TrustStore, repoName string) (*types.PackageMetadata, error) {
+	tempDir, err := os.MkdirTemp("", "criage-verify-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempDir)
+
+	if err := m.ExtractArchive(archivePath, tempDir, format); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(tempDir, ".criage-metadata.json"))
+	if err != nil {
+		return nil, fmt.Errorf("no metadata found in archive: %w", err)
+	}
+
+	signature, err := readArchiveSignature(archivePath, tempDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Verify(repoName, data, signature); err != nil {
+		return nil, err
+	}
+
+	var metadata types.PackageMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, err
+	}
+
+	// Подпись покрывает манифест, поэтому содержимое должно ему соответствовать
+	if signature != nil && metadata.MerkleRoot == "" {
+		return nil, fmt.Errorf("signed package metadata has no merkle root")
+	}
+	if metadata.MerkleRoot != "" {
+		result, err := VerifyInstallDir(tempDir, &metadata)
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Modified) > 0 || len(result.Missing) > 0 || len(result.Extra) > 0 {
+			return nil, fmt.Errorf("archive contents do not match file manifest: modified %v, missing %v, extra %v",
+				result.Modified, result.Missing, result.Extra)
+		}
+	}
+
+	return &metadata, nil
+}
+
+// readArchiveSignature ищет встроенную подпись в распакованном архиве, затем отсоединенную
+func readArchiveSignature(archivePath, extractedDir string) (*types.PackageSignature, error) {
+	data, err := os.ReadFile(filepath.Join(extractedDir, SignatureFileName))
+	if err == nil {
+		return signing.ParseSignature(data)
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	signature, err := signing.ReadDetachedSignature(archivePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return signature, err
+}
@@ -0,0 +1,141 @@
+package archive
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/criage-oss/criage-common/signing"
+	"github.com/criage-oss/criage-common/types"
+)
+
+// signedTestArchive создает подписанный архив и хранилище доверия с ключом подписи
+func signedTestArchive(t *testing.T, manager *Manager) (string, *signing.TrustStore) {
+	t.Helper()
+	publicKey, privateKey, err := signing.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(t.TempDir(), "package.tar.zst")
+	metadata := &types.PackageMetadata{CreatedBy: "criage"}
+	if _, err := manager.CreateSignedArchive(writeTestSource(t), output, types.FormatTarZst, nil, nil, metadata, privateKey); err != nil {
+		t.Fatal(err)
+	}
+	return output, newTestTrustStore(t, publicKey)
+}
+
+// newTestTrustStore создает хранилище доверия репозитория official с закрепленным ключом
+func newTestTrustStore(t *testing.T, publicKey ed25519.PublicKey) *signing.TrustStore {
+	t.Helper()
+	store, err := signing.NewTrustStore([]types.Repository{{
+		Name:        "official",
+		SigningKeys: []string{signing.EncodePublicKey(publicKey)},
+	}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+// repack распаковывает архив, изменяет содержимое функцией modify и упаковывает заново
+func repack(t *testing.T, manager *Manager, archivePath string, modify func(dir string)) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "contents")
+	if err := manager.ExtractArchive(archivePath, dir, types.FormatTarZst); err != nil {
+		t.Fatal(err)
+	}
+	modify(dir)
+
+	output := filepath.Join(t.TempDir(), "repacked.tar.zst")
+	if err := manager.createArchive(dir, output, types.FormatTarZst, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	return output
+}
+
+func TestVerifyArchiveSignature(t *testing.T) {
+	manager := newTestManager(t)
+	archivePath, store := signedTestArchive(t, manager)
+
+	metadata, err := manager.VerifyArchiveSignature(archivePath, types.FormatTarZst, store, "official")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.CreatedBy != "criage" || metadata.MerkleRoot == "" {
+		t.Errorf("metadata = %+v", metadata)
+	}
+
+	// Подпись другого ключа не принимается
+	otherKey, _, _ := signing.GenerateKey()
+	_, err = manager.VerifyArchiveSignature(archivePath, types.FormatTarZst, newTestTrustStore(t, otherKey), "official")
+	if !errors.Is(err, signing.ErrUntrustedKey) {
+		t.Errorf("VerifyArchiveSignature() with other key = %v, want %v", err, signing.ErrUntrustedKey)
+	}
+}
+
+func TestVerifyArchiveSignatureDetached(t *testing.T) {
+	manager := newTestManager(t)
+	publicKey, privateKey, _ := signing.GenerateKey()
+
+	output := filepath.Join(t.TempDir(), "package.tar.zst")
+	metadata := &types.PackageMetadata{CreatedBy: "criage"}
+	if err := manager.CreateArchiveWithMetadata(writeTestSource(t), output, types.FormatTarZst, nil, nil, metadata); err != nil {
+		t.Fatal(err)
+	}
+	data, err := signing.EncodeMetadata(metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := signing.Sign(data, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := signing.WriteDetachedSignature(output, signature); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := manager.VerifyArchiveSignature(output, types.FormatTarZst, newTestTrustStore(t, publicKey), "official"); err != nil {
+		t.Errorf("VerifyArchiveSignature() = %v", err)
+	}
+}
+
+func TestVerifyArchiveSignatureRejectsTampering(t *testing.T) {
+	manager := newTestManager(t)
+	archivePath, store := signedTestArchive(t, manager)
+
+	tests := []struct {
+		name   string
+		modify func(dir string)
+		want   error
+	}{
+		{"stripped signature", func(dir string) {
+			os.Remove(filepath.Join(dir, SignatureFileName))
+		}, signing.ErrSignatureRequired},
+		{"modified metadata", func(dir string) {
+			path := filepath.Join(dir, ".criage-metadata.json")
+			data, _ := os.ReadFile(path)
+			os.WriteFile(path, append(data, '\n'), 0644)
+		}, signing.ErrDigestMismatch},
+		{"modified file", func(dir string) {
+			os.WriteFile(filepath.Join(dir, "README"), []byte("README"), 0644)
+		}, nil},
+		{"added file", func(dir string) {
+			os.WriteFile(filepath.Join(dir, "extra"), []byte("extra"), 0644)
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := repack(t, manager, archivePath, tt.modify)
+			_, err := manager.VerifyArchiveSignature(tampered, types.FormatTarZst, store, "official")
+			if err == nil {
+				t.Fatal("VerifyArchiveSignature() accepted tampered archive")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("VerifyArchiveSignature() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -8,26 +8,45 @@ import (
 	"github.com/criage-oss/criage-common/types"
 )
 
+// CurrentVersion текущая версия формата файлов конфигурации
+const CurrentVersion = 2
+
 // Config основная конфигурация Criage
 type Config struct {
+	// Версия формата файла
+	ConfigVersion int `json:"configVersion" yaml:"configVersion"`
+
 	// Пути
 	InstallPath string `json:"installPath" yaml:"installPath"`
 	CachePath   string `json:"cachePath" yaml:"cachePath"`
 	TempPath    string `json:"tempPath" yaml:"tempPath"`
 	ConfigPath  string `json:"configPath" yaml:"configPath"`
+	KeyringPath string `json:"keyringPath" yaml:"keyringPath"`
 
 	// Сетевые настройки
 	Timeout        int    `json:"timeout" yaml:"timeout"`
 	MaxConnections int    `json:"maxConnections" yaml:"maxConnections"`
 	UserAgent      string `json:"userAgent" yaml:"userAgent"`
 
-	// Репозитории
+	// Прокси, сертификаты и повторы запросов
+	Network NetworkConfig `json:"network" yaml:"network"`
+
+	// Репозитории; Region - регион клиента для выбора ближайших зеркал
 	Repositories []types.Repository `json:"repositories" yaml:"repositories"`
+	Region       string             `json:"region,omitempty" yaml:"region,omitempty"`
+
+	// Шифрование пакетов
+	EncryptionRecipients []string `json:"encryptionRecipients,omitempty" yaml:"encryptionRecipients,omitempty"`
+	IdentityFiles        []string `json:"identityFiles,omitempty" yaml:"identityFiles,omitempty"`
 
 	// Сжатие
 	CompressionLevel int    `json:"compressionLevel" yaml:"compressionLevel"`
 	PreferredFormat  string `json:"preferredFormat" yaml:"preferredFormat"`
 
+	// Профили: активный профиль и именованные переопределения
+	Profile  string             `json:"profile,omitempty" yaml:"profile,omitempty"`
+	Profiles map[string]Profile `json:"profiles,omitempty" yaml:"profiles,omitempty"`
+
 	// Другие настройки
 	Parallel    bool   `json:"parallel" yaml:"parallel"`
 	MaxParallel int    `json:"maxParallel" yaml:"maxParallel"`
@@ -41,13 +60,16 @@ func DefaultConfig() *Config {
 	criageDir := filepath.Join(homeDir, ".criage")
 
 	return &Config{
+		ConfigVersion:    CurrentVersion,
 		InstallPath:      filepath.Join(criageDir, "packages"),
 		CachePath:        filepath.Join(criageDir, "cache"),
 		TempPath:         filepath.Join(criageDir, "tmp"),
 		ConfigPath:       filepath.Join(criageDir, "config.yaml"),
+		KeyringPath:      filepath.Join(criageDir, "keys", "keyring.json"),
 		Timeout:          30,
 		MaxConnections:   10,
 		UserAgent:        "Criage/1.0.0",
+		Network:          DefaultNetworkConfig(),
 		Repositories:     defaultRepositories(),
 		CompressionLevel: types.CompressionNormal,
 		PreferredFormat:  "tar.zst",
@@ -72,6 +94,9 @@ func defaultRepositories() []types.Repository {
 
 // ServerConfig конфигурация для сервера репозитория
 type ServerConfig struct {
+	// Версия формата файла
+	ConfigVersion int `json:"configVersion" yaml:"configVersion"`
+
 	// Сервер
 	Host string `json:"host" yaml:"host"`
 	Port int    `json:"port" yaml:"port"`
@@ -101,12 +126,13 @@ type ServerConfig struct {
 // DefaultServerConfig возвращает конфигурацию сервера по умолчанию
 func DefaultServerConfig() *ServerConfig {
 	return &ServerConfig{
-		Host:        "0.0.0.0",
-		Port:        8080,
-		StoragePath: "./packages",
-		IndexPath:   "./index.json",
-		AuthEnabled: false,
-		MaxFileSize: 100 * 1024 * 1024, // 100MB
+		ConfigVersion: CurrentVersion,
+		Host:          "0.0.0.0",
+		Port:          8080,
+		StoragePath:   "./packages",
+		IndexPath:     "./index.json",
+		AuthEnabled:   false,
+		MaxFileSize:   100 * 1024 * 1024, // 100MB
 		AllowedFormats: []string{
 			"tar.zst", "tar.lz4", "tar.xz",
 			"tar.gz", "zip", "criage",
@@ -120,6 +146,9 @@ func DefaultServerConfig() *ServerConfig {
 
 // MCPConfig конфигурация для MCP сервера
 type MCPConfig struct {
+	// Версия формата файла
+	ConfigVersion int `json:"configVersion" yaml:"configVersion"`
+
 	// Пути к исполняемым файлам
 	CriageClientPath string `json:"criageClientPath" yaml:"criageClientPath"`
 	ConfigPath       string `json:"configPath" yaml:"configPath"`
@@ -140,6 +169,7 @@ type MCPConfig struct {
 // DefaultMCPConfig возвращает конфигурацию MCP сервера по умолчанию
 func DefaultMCPConfig() *MCPConfig {
 	return &MCPConfig{
+		ConfigVersion:    CurrentVersion,
 		CriageClientPath: "criage",
 		ConfigPath:       "",
 		MaxConcurrency:   3,
@@ -0,0 +1,392 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+// Префиксы переменных окружения для конфигураций клиента, сервера и MCP
+const (
+	EnvPrefix       = "CRIAGE_"
+	ServerEnvPrefix = "CRIAGE_SERVER_"
+	MCPEnvPrefix    = "CRIAGE_MCP_"
+)
+
+// EnvVarError ошибка преобразования значения переменной окружения
+type EnvVarError struct {
+	Name  string
+	Value string
+	Err   error
+}
+
+func (e *EnvVarError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Name, e.Err)
+}
+
+func (e *EnvVarError) Unwrap() error {
+	return e.Err
+}
+
+// EnvError все ошибки разбора переменных окружения
+type EnvError struct {
+	Errors []*EnvVarError
+}
+
+func (e *EnvError) Error() string {
+	lines := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		lines[i] = err.Error()
+	}
+	return "invalid environment variables:\n  - " + strings.Join(lines, "\n  - ")
+}
+
+// EnvName возвращает имя переменной окружения для поля: "cachePath" -> "CRIAGE_CACHE_PATH"
+func EnvName(prefix, field string) string {
+	var b strings.Builder
+	b.WriteString(prefix)
+	for i, r := range field {
+		if unicode.IsUpper(r) && i > 0 {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// ApplyEnv накладывает переменные CRIAGE_* на конфигурацию клиента.
+//
+// Списки задаются через запятую или как JSON-массив, вложенные структуры -
+// через имя поля (CRIAGE_NETWORK_PROXY), элементы списков структур - по индексу:
+// CRIAGE_REPOSITORIES_0_URL изменяет первый репозиторий, а индекс, равный
+// длине списка, добавляет новый. Список целиком можно задать JSON-массивом в
+// CRIAGE_REPOSITORIES. environ nil означает окружение процесса.
+func ApplyEnv(cfg *Config, environ []string) error {
+	_, err := applyEnv(environ, EnvPrefix, cfg)
+	return err
+}
+
+// ApplyServerEnv накладывает переменные CRIAGE_SERVER_* на конфигурацию сервера
+func ApplyServerEnv(cfg *ServerConfig, environ []string) error {
+	_, err := applyEnv(environ, ServerEnvPrefix, cfg)
+	return err
+}
+
+// ApplyMCPEnv накладывает переменные CRIAGE_MCP_* на конфигурацию MCP сервера
+func ApplyMCPEnv(cfg *MCPConfig, environ []string) error {
+	_, err := applyEnv(environ, MCPEnvPrefix, cfg)
+	return err
+}
+
+// applyEnv накладывает переменные окружения на структуру target.
+// Возвращает соответствие поля верхнего уровня и переменной, из которой оно изменено.
+func applyEnv(environ []string, prefix string, target any) (map[string]string, error) {
+	d := &envDecoder{
+		env:     environMap(environ),
+		applied: make(map[string]string),
+	}
+	d.decodeStruct(reflect.ValueOf(target).Elem(), prefix, "")
+
+	if len(d.errors) > 0 {
+		return nil, &EnvError{Errors: d.errors}
+	}
+	return d.applied, nil
+}
+
+// envDecoder разбирает переменные окружения в поля структуры
+type envDecoder struct {
+	env     map[string]string
+	applied map[string]string
+	errors  []*EnvVarError
+}
+
+// decodeStruct обходит поля структуры; top - поле верхнего уровня для учета происхождения
+func (d *envDecoder) decodeStruct(v reflect.Value, prefix, top string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, ok := fieldName(t.Field(i))
+		if !ok {
+			continue
+		}
+		fieldTop := top
+		if fieldTop == "" {
+			fieldTop = field
+		}
+		d.decodeField(v.Field(i), EnvName(prefix, field), fieldTop)
+	}
+}
+
+// decodeField разбирает значение поля из переменной name и вложенных переменных
+func (d *envDecoder) decodeField(v reflect.Value, name, top string) {
+	switch {
+	case v.Type() == durationType:
+		d.decodeValue(v, name, top)
+	case v.Kind() == reflect.Struct:
+		d.decodeStruct(v, name+"_", top)
+	case v.Kind() == reflect.Pointer && v.Type().Elem().Kind() == reflect.Struct:
+		if !d.hasPrefix(name + "_") {
+			return
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		d.decodeStruct(v.Elem(), name+"_", top)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Struct:
+		d.decodeValue(v, name, top)
+		d.decodeIndexed(v, name, top)
+	default:
+		d.decodeValue(v, name, top)
+	}
+}
+
+// decodeValue присваивает значение переменной name, если она задана
+func (d *envDecoder) decodeValue(v reflect.Value, name, top string) {
+	value, ok := d.env[name]
+	if !ok {
+		return
+	}
+	if err := setValue(v, value); err != nil {
+		d.errors = append(d.errors, &EnvVarError{Name: name, Value: value, Err: err})
+		return
+	}
+	d.markApplied(top, name)
+}
+
+// decodeIndexed разбирает элементы списка структур вида NAME_<индекс>_FIELD
+func (d *envDecoder) decodeIndexed(v reflect.Value, name, top string) {
+	indexes := d.indexes(name + "_")
+	for _, index := range indexes {
+		elemPrefix := fmt.Sprintf("%s_%d_", name, index)
+		if index > v.Len() {
+			d.errors = append(d.errors, &EnvVarError{
+				Name: elemPrefix + "*",
+				Err:  fmt.Errorf("index %d skips entries: the list has %d entries", index, v.Len()),
+			})
+			continue
+		}
+		if index == v.Len() {
+			v.Set(reflect.Append(v, newElement(v.Type().Elem())))
+		}
+		d.decodeStruct(v.Index(index), elemPrefix, top)
+	}
+}
+
+// indexes возвращает отсортированные индексы переменных вида PREFIX<индекс>_...
+func (d *envDecoder) indexes(prefix string) []int {
+	seen := make(map[int]bool)
+	for key := range d.env {
+		rest, ok := strings.CutPrefix(key, prefix)
+		if !ok {
+			continue
+		}
+		digits, _, ok := strings.Cut(rest, "_")
+		if !ok {
+			continue
+		}
+		if index, err := strconv.Atoi(digits); err == nil && index >= 0 {
+			seen[index] = true
+		}
+	}
+
+	indexes := make([]int, 0, len(seen))
+	for index := range seen {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// hasPrefix проверяет, задана ли хотя бы одна переменная с префиксом
+func (d *envDecoder) hasPrefix(prefix string) bool {
+	for key := range d.env {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// markApplied запоминает первую переменную, изменившую поле верхнего уровня
+func (d *envDecoder) markApplied(top, name string) {
+	if _, ok := d.applied[top]; !ok {
+		d.applied[top] = name
+	}
+}
+
+// newElement возвращает новый элемент списка со значениями по умолчанию
+func newElement(t reflect.Type) reflect.Value {
+	elem := reflect.New(t).Elem()
+	if repo, ok := elem.Addr().Interface().(*types.Repository); ok {
+		repo.Enabled = true
+	}
+	return elem
+}
+
+// durationType тип длительности, задаваемой как "30s" или числом секунд
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setValue преобразует строку в значение поля
+func setValue(v reflect.Value, value string) error {
+	trimmed := strings.TrimSpace(value)
+
+	if v.Type() == durationType {
+		if seconds, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+			v.SetInt(int64(time.Duration(seconds) * time.Second))
+			return nil
+		}
+		duration, err := time.ParseDuration(trimmed)
+		if err != nil {
+			return fmt.Errorf("expected a duration such as \"30s\", got %q", value)
+		}
+		v.SetInt(int64(duration))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		if err := setValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(trimmed)
+		if err != nil {
+			return fmt.Errorf("expected a boolean, got %q", value)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(trimmed, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("expected an integer, got %q", value)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(trimmed, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("expected a non-negative integer, got %q", value)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(trimmed, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("expected a number, got %q", value)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		return setList(v, trimmed)
+	case reflect.Map:
+		return setMap(v, trimmed)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// setList разбирает JSON-массив или значения через запятую
+func setList(v reflect.Value, value string) error {
+	if strings.HasPrefix(value, "[") {
+		list := reflect.New(v.Type())
+		if err := json.Unmarshal([]byte(value), list.Interface()); err != nil {
+			return fmt.Errorf("invalid JSON list: %w", err)
+		}
+		v.Set(list.Elem())
+		return nil
+	}
+
+	if v.Type().Elem().Kind() == reflect.Struct {
+		return fmt.Errorf("expected a JSON list, got %q", value)
+	}
+
+	items := splitList(value)
+	list := reflect.MakeSlice(v.Type(), len(items), len(items))
+	for i, item := range items {
+		if err := setValue(list.Index(i), item); err != nil {
+			return fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+	v.Set(list)
+	return nil
+}
+
+// setMap разбирает JSON-объект или пары "key=value" через запятую
+func setMap(v reflect.Value, value string) error {
+	if v.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	if strings.HasPrefix(value, "{") {
+		m := reflect.New(v.Type())
+		if err := json.Unmarshal([]byte(value), m.Interface()); err != nil {
+			return fmt.Errorf("invalid JSON object: %w", err)
+		}
+		v.Set(m.Elem())
+		return nil
+	}
+
+	m := reflect.MakeMap(v.Type())
+	for _, item := range splitList(value) {
+		key, raw, ok := strings.Cut(item, "=")
+		if !ok {
+			return fmt.Errorf("expected key=value pairs, got %q", item)
+		}
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if err := setValue(elem, raw); err != nil {
+			return fmt.Errorf("key %s: %w", key, err)
+		}
+		m.SetMapIndex(reflect.ValueOf(strings.TrimSpace(key)), elem)
+	}
+	v.Set(m)
+	return nil
+}
+
+// splitList разбирает список, разделенный запятыми
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// environMap преобразует список "KEY=value" в карту
+func environMap(environ []string) map[string]string {
+	if environ == nil {
+		environ = os.Environ()
+	}
+	env := make(map[string]string, len(environ))
+	for _, entry := range environ {
+		if key, value, ok := strings.Cut(entry, "="); ok {
+			env[key] = value
+		}
+	}
+	return env
+}
+
+// fieldName возвращает имя поля конфигурации из тега json
+func fieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+func TestEnvName(t *testing.T) {
+	tests := map[string]string{
+		"cachePath":      "CRIAGE_CACHE_PATH",
+		"timeout":        "CRIAGE_TIMEOUT",
+		"maxConnections": "CRIAGE_MAX_CONNECTIONS",
+	}
+	for field, want := range tests {
+		if got := EnvName(EnvPrefix, field); got != want {
+			t.Errorf("EnvName(%q) = %q, want %q", field, got, want)
+		}
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	cfg := DefaultConfig()
+	err := ApplyEnv(cfg, []string{
+		"CRIAGE_TIMEOUT=60",
+		"CRIAGE_DEBUG=true",
+		"CRIAGE_NETWORK_NO_PROXY=localhost, .corp.example.com",
+		"CRIAGE_NETWORK_RETRIES=5",
+		"UNRELATED=1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Timeout != 60 || !cfg.Debug {
+		t.Errorf("timeout=%d debug=%v", cfg.Timeout, cfg.Debug)
+	}
+	if want := []string{"localhost", ".corp.example.com"}; !reflect.DeepEqual(cfg.Network.NoProxy, want) {
+		t.Errorf("noProxy = %v, want %v", cfg.Network.NoProxy, want)
+	}
+	if cfg.Network.Retries != 5 {
+		t.Errorf("retries = %v, want 5", cfg.Network.Retries)
+	}
+}
+
+func TestApplyEnvIndexedRepositories(t *testing.T) {
+	cfg := DefaultConfig()
+	err := ApplyEnv(cfg, []string{
+		"CRIAGE_REPOSITORIES_0_AUTH_TOKEN=token",
+		"CRIAGE_REPOSITORIES_1_NAME=internal",
+		"CRIAGE_REPOSITORIES_1_URL=https://internal.example.com",
+		"CRIAGE_REPOSITORIES_1_PRIORITY=200",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Repositories) != 2 {
+		t.Fatalf("repositories = %+v, want 2", cfg.Repositories)
+	}
+	if official := cfg.Repositories[0]; official.Name != "official" || official.AuthToken != "token" {
+		t.Errorf("repositories[0] = %+v", official)
+	}
+	want := types.Repository{Name: "internal", URL: "https://internal.example.com", Priority: 200, Enabled: true}
+	if !reflect.DeepEqual(cfg.Repositories[1], want) {
+		t.Errorf("repositories[1] = %+v, want %+v", cfg.Repositories[1], want)
+	}
+
+	// Список целиком задается JSON-массивом
+	cfg = DefaultConfig()
+	if err := ApplyEnv(cfg, []string{`CRIAGE_REPOSITORIES=[{"name":"only","url":"https://only.example.com"}]`}); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Repositories) != 1 || cfg.Repositories[0].Name != "only" {
+		t.Errorf("repositories = %+v", cfg.Repositories)
+	}
+}
+
+func TestApplyEnvSkippedIndex(t *testing.T) {
+	cfg := DefaultConfig()
+	err := ApplyEnv(cfg, []string{"CRIAGE_REPOSITORIES_3_URL=https://example.com"})
+
+	var envErr *EnvError
+	if !errors.As(err, &envErr) || len(envErr.Errors) != 1 {
+		t.Fatalf("ApplyEnv() = %v, want one EnvError", err)
+	}
+	if got := envErr.Errors[0]; got.Name != "CRIAGE_REPOSITORIES_3_*" || !strings.Contains(got.Error(), "skips entries") {
+		t.Errorf("error = %v", got)
+	}
+	if len(cfg.Repositories) != 1 {
+		t.Errorf("repositories = %+v, want unchanged", cfg.Repositories)
+	}
+}
+
+func TestApplyEnvErrors(t *testing.T) {
+	cfg := DefaultConfig()
+	err := ApplyEnv(cfg, []string{
+		"CRIAGE_TIMEOUT=soon",
+		"CRIAGE_PARALLEL=maybe",
+		"CRIAGE_REPOSITORIES=official",
+		"CRIAGE_MAX_CONNECTIONS=20",
+	})
+
+	var envErr *EnvError
+	if !errors.As(err, &envErr) {
+		t.Fatalf("ApplyEnv() = %v, want EnvError", err)
+	}
+	got := make(map[string]string)
+	for _, varErr := range envErr.Errors {
+		got[varErr.Name] = varErr.Err.Error()
+	}
+	want := map[string]string{
+		"CRIAGE_TIMEOUT":      `expected an integer, got "soon"`,
+		"CRIAGE_PARALLEL":     `expected a boolean, got "maybe"`,
+		"CRIAGE_REPOSITORIES": `expected a JSON list, got "official"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("errors = %v, want %v", got, want)
+	}
+	for name := range want {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("Error() does not mention %s:\n%s", name, err)
+		}
+	}
+}
+
+// envTarget структура с типами, которых нет в конфигурации клиента
+type envTarget struct {
+	Interval time.Duration     `json:"interval"`
+	Limits   map[string]int    `json:"limits"`
+	Labels   map[string]string `json:"labels"`
+	Ports    []int             `json:"ports"`
+	Ratio    float64           `json:"ratio"`
+	Size     uint              `json:"size"`
+	Skipped  string            `json:"-"`
+}
+
+func TestApplyEnvTypes(t *testing.T) {
+	var target envTarget
+	applied, err := applyEnv([]string{
+		"TEST_INTERVAL=1m30s",
+		"TEST_LIMITS=cpu=2, memory=512",
+		`TEST_LABELS={"team":"core"}`,
+		"TEST_PORTS=[80,443]",
+		"TEST_RATIO=0.5",
+		"TEST_SIZE=42",
+		"TEST_SKIPPED=value",
+	}, "TEST_", &target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := envTarget{
+		Interval: 90 * time.Second,
+		Limits:   map[string]int{"cpu": 2, "memory": 512},
+		Labels:   map[string]string{"team": "core"},
+		Ports:    []int{80, 443},
+		Ratio:    0.5,
+		Size:     42,
+	}
+	if !reflect.DeepEqual(target, want) {
+		t.Errorf("target = %+v, want %+v", target, want)
+	}
+	if applied["interval"] != "TEST_INTERVAL" || len(applied) != 6 {
+		t.Errorf("applied = %v", applied)
+	}
+
+	// Длительность задается и числом секунд
+	if _, err := applyEnv([]string{"TEST_INTERVAL=45"}, "TEST_", &target); err != nil || target.Interval != 45*time.Second {
+		t.Errorf("interval = %v, err = %v", target.Interval, err)
+	}
+
+	_, err = applyEnv([]string{
+		"TEST_INTERVAL=later",
+		"TEST_LIMITS=cpu",
+		"TEST_PORTS=80,http",
+		"TEST_SIZE=-1",
+	}, "TEST_", &target)
+	var envErr *EnvError
+	if !errors.As(err, &envErr) || len(envErr.Errors) != 4 {
+		t.Fatalf("applyEnv() = %v, want 4 errors", err)
+	}
+	for _, want := range []string{
+		`TEST_INTERVAL: expected a duration such as "30s", got "later"`,
+		`TEST_LIMITS: expected key=value pairs, got "cpu"`,
+		`TEST_PORTS: item 1: expected an integer, got "http"`,
+		`TEST_SIZE: expected a non-negative integer, got "-1"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Error() does not contain %q:\n%s", want, err)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Права на файл конфигурации и его директорию: файл может содержать учетные данные
+const (
+	FileMode = 0600
+	DirMode  = 0700
+)
+
+// Format формат файла конфигурации
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+)
+
+// DetectFormat определяет формат по расширению файла, а при его отсутствии -
+// по содержимому: документ, начинающийся с "{", считается JSON
+func DetectFormat(path string, data []byte) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		return FormatJSON
+	}
+	return FormatYAML
+}
+
+// LoadConfig загружает и проверяет конфигурацию клиента; отсутствующие
+// в файле поля сохраняют значения по умолчанию. Файлы старых версий после
+// успешной проверки обновляются на месте с сохранением резервной копии.
+func LoadConfig(path string) (*Config, error) {
+	cfg := DefaultConfig()
+	validate := func() error {
+		cfg.ConfigPath = path
+		return cfg.Validate()
+	}
+	if _, err := loadFile(path, KindClient, cfg, validate); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadServerConfig загружает конфигурацию сервера поверх значений по умолчанию и проверяет ее
+func LoadServerConfig(path string) (*ServerConfig, error) {
+	cfg := DefaultServerConfig()
+	if _, err := loadFile(path, KindServer, cfg, cfg.Validate); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadMCPConfig загружает конфигурацию MCP сервера поверх значений по умолчанию и проверяет ее
+func LoadMCPConfig(path string) (*MCPConfig, error) {
+	cfg := DefaultMCPConfig()
+	if _, err := loadFile(path, KindMCP, cfg, cfg.Validate); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// SaveConfig записывает конфигурацию клиента атомарно с правами 0600
+func SaveConfig(path string, cfg *Config) error {
+	return saveFile(path, cfg)
+}
+
+// SaveServerConfig записывает конфигурацию сервера атомарно с правами 0600
+func SaveServerConfig(path string, cfg *ServerConfig) error {
+	return saveFile(path, cfg)
+}
+
+// SaveMCPConfig записывает конфигурацию MCP сервера атомарно с правами 0600
+func SaveMCPConfig(path string, cfg *MCPConfig) error {
+	return saveFile(path, cfg)
+}
+
+// loadFile читает файл, обновляет его до текущей версии, накладывает значения
+// на target и проверяет их функцией validate. Обновленный файл записывается
+// на диск только после успешной проверки.
+func loadFile(path string, kind Kind, target any, validate func() error) (*MigrationResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	migrated, result, err := migrateData(path, data, kind)
+	if err != nil {
+		return nil, err
+	}
+	if err := decode(path, migrated, target); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if err := validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if result.Migrated() {
+		if err := persistMigration(path, data, migrated, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// decodeFile обновляет содержимое файла data до текущей версии в памяти
+// и накладывает значения на target
+func decodeFile(path string, data []byte, kind Kind, target any) error {
+	data, _, err := migrateData(path, data, kind)
+	if err != nil {
+		return err
+	}
+	if err := decode(path, data, target); err != nil {
+		return fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return nil
+}
+
+// decode разбирает YAML или JSON поверх значений target
+func decode(path string, data []byte, target any) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	if DetectFormat(path, data) == FormatJSON {
+		return decodeJSON(data, target)
+	}
+	return yaml.Unmarshal(data, target)
+}
+
+// decodeJSON разбирает JSON поверх значений target. Заданные в документе срезы
+// заменяются целиком, как при разборе YAML: encoding/json переиспользует элементы
+// существующего среза, и записи унаследовали бы поля значений по умолчанию.
+func decodeJSON(data []byte, target any) error {
+	resetSlices(data, reflect.ValueOf(target))
+	return json.Unmarshal(data, target)
+}
+
+// resetSlices обнуляет поля-срезы структуры v, заданные в JSON-объекте data,
+// и рекурсивно обрабатывает вложенные структуры
+func resetSlices(data []byte, v reflect.Value) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, ok := fieldName(t.Field(i))
+		if !ok {
+			continue
+		}
+		raw, ok := jsonField(object, name)
+		if !ok {
+			continue
+		}
+
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.Slice:
+			field.SetZero()
+		case reflect.Struct, reflect.Pointer:
+			resetSlices(raw, field)
+		}
+	}
+}
+
+// jsonField ищет значение поля так же, как encoding/json: сначала точное
+// совпадение имени, затем без учета регистра
+func jsonField(object map[string]json.RawMessage, name string) (json.RawMessage, bool) {
+	if raw, ok := object[name]; ok {
+		return raw, true
+	}
+	for key, raw := range object {
+		if strings.EqualFold(key, name) {
+			return raw, true
+		}
+	}
+	return nil, false
+}
+
+// encode сериализует значение в формате, соответствующем расширению path
+func encode(path string, value any) ([]byte, error) {
+	if DetectFormat(path, nil) == FormatJSON {
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// saveFile сериализует значение и атомарно записывает его
+func saveFile(path string, value any) error {
+	data, err := encode(path, value)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	return writeFile(path, data)
+}
+
+// writeFile записывает данные во временный файл и переименовывает его
+func writeFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, DirMode); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	temp, err := os.CreateTemp(dir, "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	if err := temp.Chmod(FileMode); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
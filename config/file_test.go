@@ -0,0 +1,171 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+// writeConfigFile записывает файл конфигурации во временную директорию
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), FileMode); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigJSONMatchesYAML(t *testing.T) {
+	yamlPath := writeConfigFile(t, "config.yaml", `configVersion: 2
+timeout: 45
+repositories:
+  - name: mine
+    url: https://mine.example.com
+  - name: other
+    url: https://other.example.com
+    priority: 5
+    enabled: true
+network:
+  noProxy: [localhost]
+`)
+	jsonPath := writeConfigFile(t, "config.json", `{
+  "configVersion": 2,
+  "timeout": 45,
+  "repositories": [
+    {"name": "mine", "url": "https://mine.example.com"},
+    {"name": "other", "url": "https://other.example.com", "priority": 5, "enabled": true}
+  ],
+  "network": {"noProxy": ["localhost"]}
+}`)
+
+	fromYAML, err := LoadConfig(yamlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromJSON, err := LoadConfig(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fromJSON.ConfigPath = fromYAML.ConfigPath
+	if !reflect.DeepEqual(fromYAML, fromJSON) {
+		t.Errorf("JSON and YAML differ:\nyaml: %+v\njson: %+v", fromYAML, fromJSON)
+	}
+	if repo := fromJSON.Repositories[0]; repo.Priority != 0 || repo.Enabled {
+		t.Errorf("repository mine inherited defaults: priority=%d enabled=%v", repo.Priority, repo.Enabled)
+	}
+	if fromJSON.MaxConnections != DefaultConfig().MaxConnections {
+		t.Errorf("maxConnections = %d, want default", fromJSON.MaxConnections)
+	}
+}
+
+func TestLoadConfigKeepsDefaultsForMissingSlices(t *testing.T) {
+	path := writeConfigFile(t, "config.json", `{"configVersion": 2, "timeout": 10}`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg.Repositories, DefaultConfig().Repositories) {
+		t.Errorf("repositories = %+v, want defaults", cfg.Repositories)
+	}
+}
+
+func TestSaveLoadConfig(t *testing.T) {
+	for _, name := range []string{"config.yaml", "config.json"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "nested", name)
+			cfg := DefaultConfig()
+			cfg.Timeout = 90
+			cfg.Repositories = append(cfg.Repositories, types.Repository{
+				Name: "internal", URL: "https://internal.example.com", Priority: 200, Enabled: true,
+				Mirrors: []types.Mirror{{URL: "https://mirror.example.com", Region: "eu"}},
+			})
+			cfg.ConfigPath = path
+
+			if err := SaveConfig(path, cfg); err != nil {
+				t.Fatal(err)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != FileMode {
+				t.Errorf("file mode = %v, want %v", info.Mode().Perm(), os.FileMode(FileMode))
+			}
+
+			loaded, err := LoadConfig(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(loaded, cfg) {
+				t.Errorf("loaded config differs:\ngot:  %+v\nwant: %+v", loaded, cfg)
+			}
+		})
+	}
+}
+
+func TestSaveLoadServerAndMCPConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	server := DefaultServerConfig()
+	server.Port = 9090
+	serverPath := filepath.Join(dir, "server.json")
+	if err := SaveServerConfig(serverPath, server); err != nil {
+		t.Fatal(err)
+	}
+	loadedServer, err := LoadServerConfig(serverPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loadedServer, server) {
+		t.Errorf("server config differs:\ngot:  %+v\nwant: %+v", loadedServer, server)
+	}
+
+	mcp := DefaultMCPConfig()
+	mcpPath := filepath.Join(dir, "mcp.yaml")
+	if err := SaveMCPConfig(mcpPath, mcp); err != nil {
+		t.Fatal(err)
+	}
+	loadedMCP, err := LoadMCPConfig(mcpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loadedMCP, mcp) {
+		t.Errorf("MCP config differs:\ngot:  %+v\nwant: %+v", loadedMCP, mcp)
+	}
+}
+
+func TestOverridesReplaceRepositories(t *testing.T) {
+	userPath := writeConfigFile(t, "config.yaml", `configVersion: 2
+repositories:
+  - name: private
+    url: https://private.example.com
+    priority: 50
+    enabled: true
+    authToken: secret
+    password: hunter2
+`)
+	loader := &Loader{
+		UserPath: userPath,
+		Environ:  []string{},
+		Overrides: map[string]any{
+			"repositories": []map[string]any{{"name": "public", "url": "https://public.example.com"}},
+		},
+	}
+	result, err := loader.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []types.Repository{{Name: "public", URL: "https://public.example.com"}}
+	if !reflect.DeepEqual(result.Config.Repositories, want) {
+		t.Errorf("repositories = %+v, want %+v", result.Config.Repositories, want)
+	}
+	if result.Origin("repositories").Layer != LayerOverride {
+		t.Errorf("origin = %v, want override", result.Origin("repositories"))
+	}
+}
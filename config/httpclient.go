@@ -0,0 +1,219 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+// Задержки между повторами запросов
+var (
+	// RetryBackoff задержка перед первым повтором, далее она удваивается
+	RetryBackoff = 500 * time.Millisecond
+	// MaxRetryBackoff наибольшая задержка, в том числе из заголовка Retry-After
+	MaxRetryBackoff = 30 * time.Second
+)
+
+// HTTPClient возвращает HTTP-клиент с общими сетевыми настройками.
+// Клиент следует создавать один раз и переиспользовать.
+func (c *Config) HTTPClient() (*http.Client, error) {
+	return c.RepositoryHTTPClient(types.Repository{})
+}
+
+// RepositoryHTTPClient возвращает HTTP-клиент для репозитория: таймаут, число
+// повторов, сертификат CA и клиентский сертификат репозитория переопределяют
+// общие настройки. Таймаут ограничивает установку соединения и ожидание
+// ответа в каждой попытке, но не загрузку тела ответа; нулевой таймаут
+// сохраняет стандартные ограничения http.DefaultTransport.
+func (c *Config) RepositoryHTTPClient(repo types.Repository) (*http.Client, error) {
+	proxy, err := c.Network.ProxyFunc()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := c.tlsConfig(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := c.RepositoryTimeout(repo)
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
+	transport.TLSClientConfig = tlsConfig
+	if timeout > 0 {
+		transport.DialContext = (&net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}).DialContext
+		transport.TLSHandshakeTimeout = timeout
+		transport.ResponseHeaderTimeout = timeout
+	}
+	if c.MaxConnections > 0 {
+		transport.MaxConnsPerHost = c.MaxConnections
+		transport.MaxIdleConnsPerHost = c.MaxConnections
+	}
+
+	return &http.Client{
+		Transport: &clientTransport{
+			base:      transport,
+			userAgent: c.UserAgent,
+			retries:   c.RepositoryRetries(repo),
+		},
+	}, nil
+}
+
+// RepositoryTimeout возвращает таймаут запросов к репозиторию
+func (c *Config) RepositoryTimeout(repo types.Repository) time.Duration {
+	if repo.Timeout > 0 {
+		return time.Duration(repo.Timeout) * time.Second
+	}
+	return time.Duration(c.Timeout) * time.Second
+}
+
+// RepositoryRetries возвращает число повторов запросов к репозиторию
+func (c *Config) RepositoryRetries(repo types.Repository) int {
+	if repo.Retries != nil {
+		return *repo.Retries
+	}
+	return c.Network.Retries
+}
+
+// tlsConfig собирает настройки TLS из общих файлов сертификатов и файлов репозитория
+func (c *Config) tlsConfig(repo types.Repository) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	caFiles := append([]string(nil), c.Network.CAFiles...)
+	if repo.CAFile != "" {
+		caFiles = append(caFiles, repo.CAFile)
+	}
+	if len(caFiles) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		for _, path := range caFiles {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA file: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(data) {
+				return nil, fmt.Errorf("no PEM certificates found in CA file %s", path)
+			}
+		}
+		config.RootCAs = pool
+	}
+
+	certFile, keyFile := c.Network.CertFile, c.Network.KeyFile
+	if repo.CertFile != "" {
+		certFile, keyFile = repo.CertFile, repo.KeyFile
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// clientTransport добавляет User-Agent и повторяет идемпотентные запросы
+type clientTransport struct {
+	base      http.RoundTripper
+	userAgent string
+	retries   int
+}
+
+func (t *clientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.userAgent != "" && req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+
+	retries := t.retries
+	if !retryable(req) {
+		retries = 0
+	}
+
+	backoff := RetryBackoff
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(req)
+		if attempt >= retries || req.Context().Err() != nil || !shouldRetry(resp, err) {
+			return resp, err
+		}
+
+		delay := backoff
+		if resp != nil {
+			if after, ok := retryAfter(resp); ok {
+				delay = after
+			}
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		delay = min(delay, MaxRetryBackoff)
+		backoff = min(backoff*2, MaxRetryBackoff)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryable проверяет, что запрос идемпотентен и его тело можно отправить повторно
+func retryable(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// shouldRetry проверяет, стоит ли повторить запрос после ответа или ошибки.
+// Ошибки проверки сертификатов не повторяются.
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		var verifyErr *tls.CertificateVerificationError
+		var alert tls.AlertError
+		return !errors.As(err, &verifyErr) && !errors.As(err, &alert)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter разбирает заголовок Retry-After в секундах или в виде даты
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,286 @@
+package config
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+// setBackoff задает задержки повторов на время теста
+func setBackoff(t *testing.T, backoff, maxBackoff time.Duration) {
+	t.Helper()
+	oldBackoff, oldMax := RetryBackoff, MaxRetryBackoff
+	RetryBackoff, MaxRetryBackoff = backoff, maxBackoff
+	t.Cleanup(func() { RetryBackoff, MaxRetryBackoff = oldBackoff, oldMax })
+}
+
+// testClient создает HTTP-клиент с заданным числом повторов и без прокси
+func testClient(t *testing.T, retries int) *http.Client {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.UserAgent = "criage-test"
+	cfg.Network = NetworkConfig{Proxy: ProxyDirect, Retries: retries}
+	client, err := cfg.HTTPClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// failingServer отвечает status первые failures запросов, затем 200
+func failingServer(t *testing.T, failures int32, status int, header http.Header) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) <= failures {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(status)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Write([]byte(r.UserAgent() + " " + string(body)))
+	}))
+	t.Cleanup(server.Close)
+	return server, &attempts
+}
+
+func TestHTTPClientRetries(t *testing.T) {
+	setBackoff(t, time.Millisecond, 10*time.Millisecond)
+
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout} {
+		server, attempts := failingServer(t, 2, status, nil)
+		resp, err := testClient(t, 3).Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || attempts.Load() != 3 {
+			t.Errorf("status %d: got %d after %d attempts, want 200 after 3", status, resp.StatusCode, attempts.Load())
+		}
+		if !strings.HasPrefix(string(body), "criage-test") {
+			t.Errorf("User-Agent not set: %q", body)
+		}
+	}
+}
+
+func TestHTTPClientRetriesExhausted(t *testing.T) {
+	setBackoff(t, time.Millisecond, 10*time.Millisecond)
+
+	server, attempts := failingServer(t, 10, http.StatusServiceUnavailable, nil)
+	resp, err := testClient(t, 2).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || attempts.Load() != 3 {
+		t.Errorf("got %d after %d attempts, want 503 after 3", resp.StatusCode, attempts.Load())
+	}
+}
+
+func TestHTTPClientNoRetry(t *testing.T) {
+	setBackoff(t, time.Millisecond, 10*time.Millisecond)
+
+	tests := []struct {
+		name    string
+		status  int
+		retries int
+		request func(client *http.Client, url string) (*http.Response, error)
+	}{
+		{"not found", http.StatusNotFound, 3, func(c *http.Client, url string) (*http.Response, error) { return c.Get(url) }},
+		{"retries disabled", http.StatusServiceUnavailable, 0, func(c *http.Client, url string) (*http.Response, error) { return c.Get(url) }},
+		{"post", http.StatusServiceUnavailable, 3, func(c *http.Client, url string) (*http.Response, error) {
+			return c.Post(url, "text/plain", strings.NewReader("data"))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, attempts := failingServer(t, 1, tt.status, nil)
+			resp, err := tt.request(testClient(t, tt.retries), server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.status || attempts.Load() != 1 {
+				t.Errorf("got %d after %d attempts, want %d after 1", resp.StatusCode, attempts.Load(), tt.status)
+			}
+		})
+	}
+}
+
+func TestHTTPClientRetriesBody(t *testing.T) {
+	setBackoff(t, time.Millisecond, 10*time.Millisecond)
+
+	server, attempts := failingServer(t, 1, http.StatusBadGateway, nil)
+	req, err := http.NewRequest(http.MethodPut, server.URL, strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := testClient(t, 1).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if attempts.Load() != 2 || string(body) != "criage-test payload" {
+		t.Errorf("body = %q after %d attempts, want payload resent", body, attempts.Load())
+	}
+}
+
+func TestHTTPClientRetryAfter(t *testing.T) {
+	// Большая задержка по умолчанию: тест завершится быстро, только если
+	// используется Retry-After
+	setBackoff(t, time.Hour, time.Hour)
+
+	server, attempts := failingServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"0"}})
+	resp, err := testClient(t, 1).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || attempts.Load() != 2 {
+		t.Errorf("got %d after %d attempts, want 200 after 2", resp.StatusCode, attempts.Load())
+	}
+}
+
+func TestHTTPClientRetryAfterCapped(t *testing.T) {
+	setBackoff(t, time.Millisecond, 20*time.Millisecond)
+
+	server, attempts := failingServer(t, 1, http.StatusServiceUnavailable, http.Header{"Retry-After": {"3600"}})
+	start := time.Now()
+	resp, err := testClient(t, 1).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Retry-After not capped by MaxRetryBackoff: waited %v", elapsed)
+	}
+	if attempts.Load() != 2 {
+		t.Errorf("attempts = %d, want 2", attempts.Load())
+	}
+}
+
+func TestHTTPClientBackoff(t *testing.T) {
+	setBackoff(t, 20*time.Millisecond, 30*time.Millisecond)
+
+	// Задержки 20 и 30 мс (удвоение ограничено MaxRetryBackoff)
+	server, _ := failingServer(t, 2, http.StatusServiceUnavailable, nil)
+	start := time.Now()
+	resp, err := testClient(t, 2).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("retries took %v, want at least 50ms of backoff", elapsed)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), 0, true},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if tt.value != "" {
+			resp.Header.Set("Retry-After", tt.value)
+		}
+		got, ok := retryAfter(resp)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": {time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)}}}
+	if got, ok := retryAfter(resp); !ok || got < 58*time.Minute || got > time.Hour {
+		t.Errorf("retryAfter(date) = %v, %v, want about 1h", got, ok)
+	}
+}
+
+func TestHTTPClientProxy(t *testing.T) {
+	var proxied atomic.Value
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Store(r.URL.String())
+		w.Write([]byte("via proxy"))
+	}))
+	defer proxy.Close()
+
+	cfg := DefaultConfig()
+	cfg.Network = NetworkConfig{Proxy: proxy.URL}
+	client, err := cfg.HTTPClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := client.Get("http://packages.example.invalid/index.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "via proxy" || proxied.Load() != "http://packages.example.invalid/index.json" {
+		t.Errorf("request not sent through proxy: body %q, proxied %v", body, proxied.Load())
+	}
+}
+
+func TestRepositoryHTTPClientTimeouts(t *testing.T) {
+	defaults := http.DefaultTransport.(*http.Transport)
+
+	tests := []struct {
+		name    string
+		timeout int
+		repo    types.Repository
+		want    time.Duration
+	}{
+		{"default transport limits", 0, types.Repository{}, defaults.TLSHandshakeTimeout},
+		{"config timeout", 20, types.Repository{}, 20 * time.Second},
+		{"repository timeout", 20, types.Repository{Timeout: 5}, 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Timeout = tt.timeout
+			client, err := cfg.RepositoryHTTPClient(tt.repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			transport := client.Transport.(*clientTransport).base.(*http.Transport)
+			if transport.TLSHandshakeTimeout != tt.want {
+				t.Errorf("TLSHandshakeTimeout = %v, want %v", transport.TLSHandshakeTimeout, tt.want)
+			}
+			if transport.DialContext == nil {
+				t.Error("DialContext not set")
+			}
+		})
+	}
+}
+
+func TestRepositoryRetries(t *testing.T) {
+	cfg := DefaultConfig()
+	if got := cfg.RepositoryRetries(types.Repository{}); got != DefaultRetries {
+		t.Errorf("RepositoryRetries = %d, want %d", got, DefaultRetries)
+	}
+	zero := 0
+	if got := cfg.RepositoryRetries(types.Repository{Retries: &zero}); got != 0 {
+		t.Errorf("RepositoryRetries with retries: 0 = %d, want 0", got)
+	}
+}
@@ -0,0 +1,293 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Layer уровень, из которого взято значение настройки.
+// Уровни перечислены по возрастанию приоритета.
+type Layer string
+
+const (
+	LayerDefault  Layer = "default"
+	LayerSystem   Layer = "system"
+	LayerUser     Layer = "user"
+	LayerProject  Layer = "project"
+	LayerProfile  Layer = "profile"
+	LayerEnv      Layer = "env"
+	LayerOverride Layer = "override"
+)
+
+// Origin происхождение значения настройки
+type Origin struct {
+	Layer Layer `json:"layer"`
+	// Source файл или переменная окружения, пусто для значений по умолчанию
+	Source string `json:"source,omitempty"`
+}
+
+func (o Origin) String() string {
+	if o.Source == "" {
+		return string(o.Layer)
+	}
+	return fmt.Sprintf("%s (%s)", o.Layer, o.Source)
+}
+
+// FieldOrigin действующее значение настройки и его происхождение
+type FieldOrigin struct {
+	Field  string `json:"field"`
+	Value  any    `json:"value"`
+	Origin Origin `json:"origin"`
+}
+
+// LayeredConfig действующая конфигурация с происхождением значений
+type LayeredConfig struct {
+	Config *Config
+	// Origins происхождение значений по имени поля (json-тег)
+	Origins map[string]Origin
+	// Files загруженные файлы конфигурации в порядке применения
+	Files []string
+	// Warnings секреты в открытом виде в файлах, доступных всем пользователям
+	Warnings []SecretWarning
+}
+
+// Origin возвращает происхождение значения поля
+func (l *LayeredConfig) Origin(field string) Origin {
+	if origin, ok := l.Origins[field]; ok {
+		return origin
+	}
+	return Origin{Layer: LayerDefault}
+}
+
+// Fields возвращает все поля конфигурации с их значениями и происхождением.
+// Секреты в значениях скрыты.
+func (l *LayeredConfig) Fields() []FieldOrigin {
+	v := reflect.ValueOf(l.Config.Redacted()).Elem()
+	t := v.Type()
+
+	fields := make([]FieldOrigin, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, ok := fieldName(t.Field(i))
+		if !ok {
+			continue
+		}
+		fields = append(fields, FieldOrigin{
+			Field:  name,
+			Value:  v.Field(i).Interface(),
+			Origin: l.Origin(name),
+		})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+	return fields
+}
+
+// Loader загружает конфигурацию по уровням: значения по умолчанию,
+// системный, пользовательский и проектный файлы, выбранный профиль,
+// переменные окружения CRIAGE_* и явные переопределения. Отсутствующие
+// файлы пропускаются.
+type Loader struct {
+	SystemPath  string
+	UserPath    string
+	ProjectPath string
+
+	// Environ переменные окружения в виде "KEY=value" (nil - окружение процесса)
+	Environ []string
+	// Overrides явные значения по имени поля (json-тег), например {"timeout": 60}
+	Overrides map[string]any
+	// Profile профиль; если не задан - переменная CRIAGE_PROFILE или поле profile файла
+	Profile string
+	// AllowProjectSecrets разрешает ссылки file: и helper: в проектном файле.
+	// По умолчанию такой файл отклоняется: он берется из текущей директории и
+	// может прийти вместе с чужим репозиторием.
+	AllowProjectSecrets bool
+}
+
+// NewLoader создает загрузчик со стандартными путями
+func NewLoader() *Loader {
+	homeDir, _ := os.UserHomeDir()
+	return &Loader{
+		SystemPath:  SystemConfigPath(),
+		UserPath:    filepath.Join(homeDir, ".criage", "config.yaml"),
+		ProjectPath: filepath.Join(".criage", "config.yaml"),
+	}
+}
+
+// SystemConfigPath возвращает путь к системному файлу конфигурации
+func SystemConfigPath() string {
+	if runtime.GOOS == "windows" {
+		if programData := os.Getenv("ProgramData"); programData != "" {
+			return filepath.Join(programData, "criage", "config.yaml")
+		}
+	}
+	return filepath.Join("/etc", "criage", "config.yaml")
+}
+
+// LoadLayered загружает конфигурацию со стандартными уровнями
+func LoadLayered() (*LayeredConfig, error) {
+	return NewLoader().Load()
+}
+
+// Load применяет уровни по возрастанию приоритета и проверяет результат
+func (l *Loader) Load() (*LayeredConfig, error) {
+	result := &LayeredConfig{
+		Config:  DefaultConfig(),
+		Origins: make(map[string]Origin),
+	}
+
+	files := []struct {
+		layer Layer
+		path  string
+	}{
+		{LayerSystem, l.SystemPath},
+		{LayerUser, l.UserPath},
+		{LayerProject, l.ProjectPath},
+	}
+	for _, file := range files {
+		if file.path == "" {
+			continue
+		}
+		trusted := file.layer != LayerProject || l.AllowProjectSecrets
+		loaded, err := result.applyFile(file.layer, file.path, trusted)
+		if err != nil {
+			return nil, err
+		}
+		if loaded {
+			result.Files = append(result.Files, file.path)
+		}
+	}
+
+	profile, profileOrigin := l.profileName(result)
+	fields, err := result.Config.applyProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range fields {
+		result.Origins[field] = Origin{Layer: LayerProfile, Source: profile}
+	}
+
+	applied, err := applyEnv(l.Environ, EnvPrefix, result.Config)
+	if err != nil {
+		return nil, err
+	}
+	for field, name := range applied {
+		result.Origins[field] = Origin{Layer: LayerEnv, Source: name}
+	}
+
+	if err := result.applyOverrides(l.Overrides); err != nil {
+		return nil, err
+	}
+	if profile != "" {
+		result.Config.Profile = profile
+		result.Origins["profile"] = profileOrigin
+	}
+
+	if _, ok := result.Origins["configPath"]; !ok && l.UserPath != "" {
+		result.Config.ConfigPath = l.UserPath
+	}
+
+	if err := result.Config.Validate(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// profileName выбирает профиль: аргумент загрузчика, CRIAGE_PROFILE или поле profile файлов
+func (l *Loader) profileName(result *LayeredConfig) (string, Origin) {
+	if l.Profile != "" {
+		return l.Profile, Origin{Layer: LayerOverride}
+	}
+	if name := environMap(l.Environ)[ProfileEnv]; name != "" {
+		return name, Origin{Layer: LayerEnv, Source: ProfileEnv}
+	}
+	return result.Config.Profile, result.Origin("profile")
+}
+
+// applyFile накладывает файл уровня layer; false - файла нет.
+// Недоверенный файл не может ссылаться на локальные файлы и программы учетных данных.
+func (l *LayeredConfig) applyFile(layer Layer, path string, trusted bool) (bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s config: %w", layer, err)
+	}
+
+	// Файлы старых версий обновляются только в памяти
+	data, _, err = migrateData(path, data, KindClient)
+	if err != nil {
+		return false, err
+	}
+
+	if !trusted {
+		fields, err := localSecretReferences(path, data)
+		if err != nil {
+			return false, err
+		}
+		if len(fields) > 0 {
+			return false, fmt.Errorf("%w: %s sets %s", ErrProjectSecretReference, path, strings.Join(fields, ", "))
+		}
+	}
+
+	var keys map[string]any
+	if err := decode(path, data, &keys); err != nil {
+		return false, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if err := decode(path, data, l.Config); err != nil {
+		return false, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	for key := range keys {
+		l.Origins[key] = Origin{Layer: layer, Source: path}
+	}
+
+	if info, err := os.Stat(path); err == nil && worldReadable(info.Mode()) {
+		warnings, err := literalSecrets(path, data)
+		if err != nil {
+			return false, err
+		}
+		l.Warnings = append(l.Warnings, warnings...)
+	}
+	return true, nil
+}
+
+// applyOverrides накладывает явные значения полей
+func (l *LayeredConfig) applyOverrides(overrides map[string]any) error {
+	if len(overrides) == 0 {
+		return nil
+	}
+
+	known := make(map[string]bool)
+	t := reflect.TypeOf(l.Config).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		if name, ok := fieldName(t.Field(i)); ok {
+			known[name] = true
+		}
+	}
+	for field := range overrides {
+		if !known[field] {
+			return fmt.Errorf("unknown config field %q in overrides", field)
+		}
+	}
+
+	data, err := json.Marshal(overrides)
+	if err != nil {
+		return fmt.Errorf("failed to encode config overrides: %w", err)
+	}
+	if err := decodeJSON(data, l.Config); err != nil {
+		return fmt.Errorf("invalid config overrides: %w", err)
+	}
+
+	for field := range overrides {
+		l.Origins[field] = Origin{Layer: LayerOverride}
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+// Kind вид файла конфигурации, определяющий набор миграций
+type Kind string
+
+const (
+	KindClient Kind = "client"
+	KindServer Kind = "server"
+	KindMCP    Kind = "mcp"
+)
+
+// LegacyVersion версия файлов, созданных до появления поля configVersion
+const LegacyVersion = 1
+
+// Migration шаг обновления файла с версии From до From+1.
+// Apply изменяет разобранный документ на месте.
+type Migration struct {
+	From        int
+	Description string
+	Apply       func(doc map[string]any) error
+}
+
+// MigrationResult результат обновления файла конфигурации
+type MigrationResult struct {
+	From    int
+	To      int
+	Applied []string
+	// Backup копия исходного файла, пусто если файл не перезаписывался
+	Backup string
+}
+
+// Migrated проверяет, был ли обновлен документ
+func (r *MigrationResult) Migrated() bool {
+	return r.From != r.To
+}
+
+// migrations шаги обновления по видам конфигурации
+var migrations = map[Kind][]Migration{
+	KindClient: {
+		{From: 1, Description: "normalize preferredFormat aliases", Apply: migrateClientV1},
+	},
+	KindServer: {
+		{From: 1, Description: "normalize allowedFormats aliases", Apply: migrateServerV1},
+	},
+	KindMCP: {
+		{From: 1, Description: "add configVersion", Apply: func(map[string]any) error { return nil }},
+	},
+}
+
+// Migrations возвращает шаги обновления для вида конфигурации
+func Migrations(kind Kind) []Migration {
+	return migrations[kind]
+}
+
+// Migrate обновляет разобранный документ до CurrentVersion, применяя шаги по порядку
+func Migrate(doc map[string]any, kind Kind) (*MigrationResult, error) {
+	from, err := documentVersion(doc)
+	if err != nil {
+		return nil, err
+	}
+	if from > CurrentVersion {
+		return nil, fmt.Errorf("config version %d is newer than supported version %d", from, CurrentVersion)
+	}
+
+	result := &MigrationResult{From: from, To: from}
+	steps := Migrations(kind)
+	for result.To < CurrentVersion {
+		step, ok := findMigration(steps, result.To)
+		if !ok {
+			return nil, fmt.Errorf("no %s config migration from version %d", kind, result.To)
+		}
+		if err := step.Apply(doc); err != nil {
+			return nil, fmt.Errorf("failed to migrate %s config from version %d: %w", kind, step.From, err)
+		}
+		result.To = step.From + 1
+		result.Applied = append(result.Applied, step.Description)
+		doc["configVersion"] = result.To
+	}
+	return result, nil
+}
+
+// MigrateFile обновляет файл конфигурации на месте, сохраняя копию исходного
+// файла рядом с ним (<path>.v<версия>.bak). Файл перезаписывается только если
+// обновленная конфигурация проходит проверку. Обновленный файл сериализуется
+// заново: комментарии и порядок полей не сохраняются и остаются только в копии.
+func MigrateFile(path string, kind Kind) (*MigrationResult, error) {
+	target, validate := kindConfig(kind)
+	return loadFile(path, kind, target, validate)
+}
+
+// kindConfig возвращает значения по умолчанию для вида конфигурации и их проверку
+func kindConfig(kind Kind) (any, func() error) {
+	switch kind {
+	case KindServer:
+		cfg := DefaultServerConfig()
+		return cfg, cfg.Validate
+	case KindMCP:
+		cfg := DefaultMCPConfig()
+		return cfg, cfg.Validate
+	default:
+		cfg := DefaultConfig()
+		return cfg, cfg.Validate
+	}
+}
+
+// migrateData обновляет содержимое файла в памяти и возвращает данные для разбора
+func migrateData(path string, data []byte, kind Kind) ([]byte, *MigrationResult, error) {
+	var doc map[string]any
+	if err := decode(path, data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if doc == nil {
+		// Пустой файл: значения по умолчанию уже текущей версии
+		return data, &MigrationResult{From: CurrentVersion, To: CurrentVersion}, nil
+	}
+
+	result, err := Migrate(doc, kind)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if !result.Migrated() {
+		return data, result, nil
+	}
+
+	migrated, err := encode(path, doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode migrated config: %w", err)
+	}
+	return migrated, result, nil
+}
+
+// persistMigration записывает обновленный файл, сохраняя копию исходного.
+// Файл только для чтения (например, системный) остается обновленным лишь в памяти.
+func persistMigration(path string, original, migrated []byte, result *MigrationResult) error {
+	backup, err := backupFile(path, original, result.From)
+	if err == nil {
+		err = writeFile(path, migrated)
+	}
+	switch {
+	case err == nil:
+		result.Backup = backup
+	case errors.Is(err, fs.ErrPermission):
+	default:
+		return err
+	}
+	return nil
+}
+
+// backupFile сохраняет исходный файл; существующая копия не перезаписывается
+func backupFile(path string, data []byte, version int) (string, error) {
+	backup := fmt.Sprintf("%s.v%d.bak", path, version)
+	if _, err := os.Stat(backup); err == nil {
+		return backup, nil
+	}
+	if err := os.WriteFile(backup, data, FileMode); err != nil {
+		return "", fmt.Errorf("failed to back up config: %w", err)
+	}
+	return backup, nil
+}
+
+// documentVersion возвращает configVersion документа; без поля - LegacyVersion
+func documentVersion(doc map[string]any) (int, error) {
+	raw, ok := doc["configVersion"]
+	if !ok || raw == nil {
+		return LegacyVersion, nil
+	}
+
+	var version int
+	switch v := raw.(type) {
+	case int:
+		version = v
+	case int64:
+		version = int(v)
+	case uint64:
+		version = int(v)
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, fmt.Errorf("invalid configVersion %v", v)
+		}
+		version = int(v)
+	default:
+		return 0, fmt.Errorf("invalid configVersion %v", raw)
+	}
+	if version < LegacyVersion {
+		return 0, fmt.Errorf("invalid configVersion %d", version)
+	}
+	return version, nil
+}
+
+// findMigration ищет шаг обновления с версии from
+func findMigration(steps []Migration, from int) (Migration, bool) {
+	for _, step := range steps {
+		if step.From == from {
+			return step, true
+		}
+	}
+	return Migration{}, false
+}
+
+// migrateClientV1 заменяет синонимы формата ("zstd", "tgz") на имена ArchiveFormat
+func migrateClientV1(doc map[string]any) error {
+	if value, ok := doc["preferredFormat"].(string); ok {
+		if format, ok := types.NormalizeArchiveFormat(value); ok {
+			doc["preferredFormat"] = string(format)
+		}
+	}
+	return nil
+}
+
+// migrateServerV1 заменяет синонимы в allowedFormats и убирает повторы
+func migrateServerV1(doc map[string]any) error {
+	list, ok := doc["allowedFormats"].([]any)
+	if !ok {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	formats := make([]any, 0, len(list))
+	for _, item := range list {
+		value, ok := item.(string)
+		if !ok {
+			return fmt.Errorf("allowedFormats contains a non-string value %v", item)
+		}
+		if format, ok := types.NormalizeArchiveFormat(value); ok {
+			value = string(format)
+		} else {
+			value = strings.ToLower(strings.TrimSpace(value))
+		}
+		if !seen[value] {
+			seen[value] = true
+			formats = append(formats, value)
+		}
+	}
+	doc["allowedFormats"] = formats
+	return nil
+}
@@ -0,0 +1,263 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMigrationsCoverAllVersions(t *testing.T) {
+	for _, kind := range []Kind{KindClient, KindServer, KindMCP} {
+		for version := LegacyVersion; version < CurrentVersion; version++ {
+			if _, ok := findMigration(Migrations(kind), version); !ok {
+				t.Errorf("%s: no migration from version %d", kind, version)
+			}
+		}
+	}
+}
+
+func TestMigrateClientV1(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"zstd", "tar.zst"},
+		{"zst", "tar.zst"},
+		{"tgz", "tar.gz"},
+		{"gzip", "tar.gz"},
+		{"xz", "tar.xz"},
+		{"lz4", "tar.lz4"},
+		{"tar.zst", "tar.zst"},
+		{"ZIP", "zip"},
+		{"rar", "rar"},
+	}
+
+	for _, tt := range tests {
+		doc := map[string]any{"preferredFormat": tt.format}
+		result, err := Migrate(doc, KindClient)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.format, err)
+		}
+		if got := doc["preferredFormat"]; got != tt.want {
+			t.Errorf("%s: preferredFormat = %v, want %s", tt.format, got, tt.want)
+		}
+		if result.From != LegacyVersion || result.To != CurrentVersion {
+			t.Errorf("%s: migrated %d -> %d, want %d -> %d", tt.format, result.From, result.To, LegacyVersion, CurrentVersion)
+		}
+		if doc["configVersion"] != CurrentVersion {
+			t.Errorf("%s: configVersion = %v, want %d", tt.format, doc["configVersion"], CurrentVersion)
+		}
+	}
+}
+
+func TestMigrateServerV1(t *testing.T) {
+	doc := map[string]any{
+		"allowedFormats": []any{"tgz", "tar.gz", "criage", "ZIP", "zstd"},
+	}
+	result, err := Migrate(doc, KindServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []any{"tar.gz", "criage", "zip", "tar.zst"}
+	if got := doc["allowedFormats"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("allowedFormats = %v, want %v", got, want)
+	}
+	if len(result.Applied) != 1 {
+		t.Errorf("applied %d migrations, want 1", len(result.Applied))
+	}
+}
+
+func TestMigrateServerV1RejectsNonStringFormat(t *testing.T) {
+	doc := map[string]any{"allowedFormats": []any{"zip", 42}}
+	if _, err := Migrate(doc, KindServer); err == nil {
+		t.Fatal("expected an error for a non-string format")
+	}
+}
+
+func TestMigrateMCPV1(t *testing.T) {
+	doc := map[string]any{"maxConcurrency": 5}
+	result, err := Migrate(doc, KindMCP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Migrated() || doc["configVersion"] != CurrentVersion {
+		t.Errorf("configVersion = %v, want %d", doc["configVersion"], CurrentVersion)
+	}
+	if doc["maxConcurrency"] != 5 {
+		t.Errorf("maxConcurrency changed to %v", doc["maxConcurrency"])
+	}
+}
+
+func TestMigrateCurrentVersion(t *testing.T) {
+	doc := map[string]any{"configVersion": CurrentVersion, "preferredFormat": "zstd"}
+	result, err := Migrate(doc, KindClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Migrated() {
+		t.Errorf("current config migrated %d -> %d", result.From, result.To)
+	}
+	if doc["preferredFormat"] != "zstd" {
+		t.Errorf("current config modified: preferredFormat = %v", doc["preferredFormat"])
+	}
+}
+
+func TestMigrateVersionValues(t *testing.T) {
+	tests := []struct {
+		version any
+		wantErr string
+	}{
+		{float64(CurrentVersion), ""},
+		{CurrentVersion + 1, "newer than supported"},
+		{0, "invalid configVersion"},
+		{1.5, "invalid configVersion"},
+		{"two", "invalid configVersion"},
+	}
+
+	for _, tt := range tests {
+		_, err := Migrate(map[string]any{"configVersion": tt.version}, KindClient)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%v: unexpected error: %v", tt.version, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%v: error = %v, want %q", tt.version, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLoadConfigMigratesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	original := []byte("timeout: 45\npreferredFormat: zstd\n")
+	if err := os.WriteFile(path, original, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.PreferredFormat != "tar.zst" || cfg.Timeout != 45 || cfg.ConfigVersion != CurrentVersion {
+		t.Errorf("loaded preferredFormat=%q timeout=%d configVersion=%d", cfg.PreferredFormat, cfg.Timeout, cfg.ConfigVersion)
+	}
+
+	backup, err := os.ReadFile(path + ".v1.bak")
+	if err != nil {
+		t.Fatalf("backup not created: %v", err)
+	}
+	if !bytes.Equal(backup, original) {
+		t.Errorf("backup = %q, want %q", backup, original)
+	}
+
+	migrated, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(migrated), "configVersion: 2") || !strings.Contains(string(migrated), "preferredFormat: tar.zst") {
+		t.Errorf("file not migrated:\n%s", migrated)
+	}
+
+	// Повторная загрузка не изменяет файл
+	if _, err := LoadConfig(path); err != nil {
+		t.Fatal(err)
+	}
+	again, _ := os.ReadFile(path)
+	if !bytes.Equal(again, migrated) {
+		t.Error("current config rewritten on second load")
+	}
+}
+
+func TestLoadServerConfigMigratesJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.json")
+	if err := os.WriteFile(path, []byte(`{"port": 9090, "allowedFormats": ["tgz", "zip"]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadServerConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg.AllowedFormats, []string{"tar.gz", "zip"}) {
+		t.Errorf("allowedFormats = %v", cfg.AllowedFormats)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		t.Errorf("migrated JSON file written in another format:\n%s", data)
+	}
+	if _, err := os.Stat(path + ".v1.bak"); err != nil {
+		t.Errorf("backup not created: %v", err)
+	}
+}
+
+func TestMigrateFileKeepsExistingBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp.yaml")
+	if err := os.WriteFile(path+".v1.bak", []byte("first\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("timeout: 10\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := MigrateFile(path, KindMCP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Backup != path+".v1.bak" {
+		t.Errorf("backup = %q", result.Backup)
+	}
+	if backup, _ := os.ReadFile(result.Backup); string(backup) != "first\n" {
+		t.Errorf("existing backup overwritten: %q", backup)
+	}
+}
+
+func TestLayeredLoaderMigratesInMemory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	original := []byte("preferredFormat: tgz\n")
+	if err := os.WriteFile(path, original, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := &Loader{UserPath: path, Environ: []string{}}
+	layered, err := loader.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if layered.Config.PreferredFormat != "tar.gz" {
+		t.Errorf("preferredFormat = %q, want tar.gz", layered.Config.PreferredFormat)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !bytes.Equal(data, original) {
+		t.Error("layered loader rewrote the file")
+	}
+	if _, err := os.Stat(path + ".v1.bak"); !os.IsNotExist(err) {
+		t.Error("layered loader created a backup")
+	}
+}
+
+func TestInvalidLegacyFileNotRewritten(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	original := []byte("# комментарий\ntimeout: -5\npreferredFormat: zstd\n")
+	if err := os.WriteFile(path, original, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatal("LoadConfig accepted invalid legacy config")
+	}
+	if _, err := MigrateFile(path, KindClient); err == nil {
+		t.Fatal("MigrateFile accepted invalid legacy config")
+	}
+
+	data, _ := os.ReadFile(path)
+	if !bytes.Equal(data, original) {
+		t.Errorf("invalid config rewritten:\n%s", data)
+	}
+	if _, err := os.Stat(path + ".v1.bak"); !os.IsNotExist(err) {
+		t.Error("backup created for invalid config")
+	}
+}
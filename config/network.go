@@ -0,0 +1,235 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ProxyDirect значение прокси, отключающее все прокси, в том числе
+// заданные переменными HTTP_PROXY и HTTPS_PROXY
+const ProxyDirect = "direct"
+
+// DefaultRetries число повторов запроса по умолчанию
+const DefaultRetries = 3
+
+// NetworkConfig сетевые настройки клиента: прокси, сертификаты и повторы
+type NetworkConfig struct {
+	// Proxy прокси для всех запросов: http://, https://, socks5:// или socks5h://.
+	// Пустое значение - прокси из переменных окружения, "direct" - без прокси.
+	Proxy string `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+	// HTTPSProxy прокси для https-запросов, если он отличается от Proxy
+	HTTPSProxy string `json:"httpsProxy,omitempty" yaml:"httpsProxy,omitempty"`
+	// NoProxy узлы, к которым прокси не применяется: "*", "example.com"
+	// (вместе с поддоменами), ".example.com" (только поддомены), IP-адреса,
+	// подсети CIDR; к любому варианту можно добавить ":порт"
+	NoProxy []string `json:"noProxy,omitempty" yaml:"noProxy,omitempty"`
+
+	// CAFiles дополнительные сертификаты CA в формате PEM к системным
+	CAFiles []string `json:"caFiles,omitempty" yaml:"caFiles,omitempty"`
+	// CertFile и KeyFile клиентский сертификат для mTLS
+	CertFile string `json:"certFile,omitempty" yaml:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty" yaml:"keyFile,omitempty"`
+
+	// Retries число повторов запроса при сетевых ошибках и ответах 429, 502-504
+	Retries int `json:"retries" yaml:"retries"`
+}
+
+// DefaultNetworkConfig возвращает сетевые настройки по умолчанию
+func DefaultNetworkConfig() NetworkConfig {
+	return NetworkConfig{Retries: DefaultRetries}
+}
+
+// ProxyFunc возвращает функцию выбора прокси для http.Transport
+func (n *NetworkConfig) ProxyFunc() (func(*http.Request) (*url.URL, error), error) {
+	rules, err := parseNoProxy(n.NoProxy)
+	if err != nil {
+		return nil, err
+	}
+
+	httpValue, httpsValue := n.Proxy, n.HTTPSProxy
+	if httpsValue == "" {
+		httpsValue = httpValue
+	}
+	proxy, err := parseProxy(httpValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy: %w", err)
+	}
+	httpsProxy, err := parseProxy(httpsValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid httpsProxy: %w", err)
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		if rules.match(req.URL) {
+			return nil, nil
+		}
+		value, selected := httpValue, proxy
+		if req.URL.Scheme == "https" {
+			value, selected = httpsValue, httpsProxy
+		}
+		if strings.TrimSpace(value) == "" {
+			return http.ProxyFromEnvironment(req)
+		}
+		if selected == nil || isLoopback(req.URL.Hostname()) {
+			return nil, nil
+		}
+		return selected, nil
+	}, nil
+}
+
+// Redacted возвращает копию настроек со скрытыми паролями в адресах прокси
+func (n NetworkConfig) Redacted() NetworkConfig {
+	n.Proxy = redactProxy(n.Proxy)
+	n.HTTPSProxy = redactProxy(n.HTTPSProxy)
+	return n
+}
+
+// parseProxy разбирает адрес прокси; схема по умолчанию - http.
+// Пустое значение и "direct" возвращают nil.
+func parseProxy(value string) (*url.URL, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == ProxyDirect {
+		return nil, nil
+	}
+	if !strings.Contains(value, "://") {
+		value = "http://" + value
+	}
+
+	u, err := url.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, expected http, https, socks5 or socks5h", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("proxy %q has no host", u.Redacted())
+	}
+	return u, nil
+}
+
+// redactProxy скрывает пароль в адресе прокси
+func redactProxy(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return value
+	}
+	if _, ok := u.User.Password(); !ok {
+		return value
+	}
+	user := url.User(u.User.Username()).String()
+	u.User = nil
+	return u.Scheme + "://" + user + ":" + RedactedValue + "@" + strings.TrimPrefix(u.String(), u.Scheme+"://")
+}
+
+// noProxyRule правило исключения из списка NoProxy
+type noProxyRule struct {
+	all    bool
+	ip     net.IP
+	subnet *net.IPNet
+	// domain с точкой в начале; exact - совпадение и с самим доменом
+	domain string
+	exact  bool
+	port   string
+}
+
+// noProxyRules список правил исключения
+type noProxyRules []noProxyRule
+
+// parseNoProxy разбирает список NoProxy
+func parseNoProxy(entries []string) (noProxyRules, error) {
+	var rules noProxyRules
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+			continue
+		case entry == "*":
+			rules = append(rules, noProxyRule{all: true})
+			continue
+		}
+
+		if _, subnet, err := net.ParseCIDR(entry); err == nil {
+			rules = append(rules, noProxyRule{subnet: subnet})
+			continue
+		}
+
+		host, port := entry, ""
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			host, port = h, p
+		}
+		if ip := net.ParseIP(strings.Trim(host, "[]")); ip != nil {
+			rules = append(rules, noProxyRule{ip: ip, port: port})
+			continue
+		}
+
+		host = strings.TrimPrefix(host, "*")
+		rule := noProxyRule{domain: host, port: port}
+		if !strings.HasPrefix(host, ".") {
+			rule.domain, rule.exact = "."+host, true
+		}
+		if rule.domain == "." {
+			return nil, fmt.Errorf("invalid noProxy entry %q", entry)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// match проверяет, исключен ли адрес из проксирования
+func (r noProxyRules) match(u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if port == "" {
+		port = defaultPort(u.Scheme)
+	}
+	ip := net.ParseIP(host)
+
+	for _, rule := range r {
+		if rule.port != "" && rule.port != port {
+			continue
+		}
+		switch {
+		case rule.all:
+			return true
+		case rule.subnet != nil:
+			if ip != nil && rule.subnet.Contains(ip) {
+				return true
+			}
+		case rule.ip != nil:
+			if ip != nil && rule.ip.Equal(ip) {
+				return true
+			}
+		case ip == nil:
+			if strings.HasSuffix(host, rule.domain) || (rule.exact && host == rule.domain[1:]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// defaultPort порт схемы по умолчанию
+func defaultPort(scheme string) string {
+	switch scheme {
+	case "https":
+		return "443"
+	case "http":
+		return "80"
+	}
+	return ""
+}
+
+// isLoopback проверяет, что узел локальный: к нему прокси не применяется
+func isLoopback(host string) bool {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
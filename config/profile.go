@@ -0,0 +1,181 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+// Профили конфигурации
+const (
+	// ProfileEnv переменная окружения, выбирающая профиль
+	ProfileEnv = "CRIAGE_PROFILE"
+	// ProfileDefault базовый профиль: конфигурация без переопределений
+	ProfileDefault = "default"
+)
+
+// Profile именованный набор переопределений конфигурации, например
+// "internal-mirror", "public" или "offline". Пустые поля не изменяют
+// базовую конфигурацию; поля называются так же, как в Config. Поля-указатели
+// применяются, если заданы, даже с нулевым значением.
+type Profile struct {
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+
+	// Пути
+	InstallPath string `json:"installPath,omitempty" yaml:"installPath,omitempty"`
+	CachePath   string `json:"cachePath,omitempty" yaml:"cachePath,omitempty"`
+	TempPath    string `json:"tempPath,omitempty" yaml:"tempPath,omitempty"`
+	KeyringPath string `json:"keyringPath,omitempty" yaml:"keyringPath,omitempty"`
+
+	// Сетевые настройки
+	Timeout        *int   `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	MaxConnections *int   `json:"maxConnections,omitempty" yaml:"maxConnections,omitempty"`
+	UserAgent      string `json:"userAgent,omitempty" yaml:"userAgent,omitempty"`
+	// Network дополняет сетевые настройки: заданные поля заменяют базовые
+	Network *ProfileNetwork `json:"network,omitempty" yaml:"network,omitempty"`
+
+	// Repositories заменяет список репозиториев
+	Repositories []types.Repository `json:"repositories,omitempty" yaml:"repositories,omitempty"`
+	Region       string             `json:"region,omitempty" yaml:"region,omitempty"`
+	// Offline отключает все репозитории
+	Offline bool `json:"offline,omitempty" yaml:"offline,omitempty"`
+}
+
+// ProfileNetwork сетевые настройки профиля. Заданные поля, в том числе
+// нулевые и пустые (retries: 0, noProxy: []), заменяют базовые; отсутствующие
+// сохраняют базовые значения. Поля называются так же, как в NetworkConfig.
+type ProfileNetwork struct {
+	Proxy      *string   `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+	HTTPSProxy *string   `json:"httpsProxy,omitempty" yaml:"httpsProxy,omitempty"`
+	NoProxy    *[]string `json:"noProxy,omitempty" yaml:"noProxy,omitempty"`
+	CAFiles    *[]string `json:"caFiles,omitempty" yaml:"caFiles,omitempty"`
+	CertFile   *string   `json:"certFile,omitempty" yaml:"certFile,omitempty"`
+	KeyFile    *string   `json:"keyFile,omitempty" yaml:"keyFile,omitempty"`
+	Retries    *int      `json:"retries,omitempty" yaml:"retries,omitempty"`
+}
+
+// Apply возвращает сетевые настройки base с наложенными полями профиля
+func (p *ProfileNetwork) Apply(base NetworkConfig) NetworkConfig {
+	overlay(reflect.ValueOf(&base).Elem(), reflect.ValueOf(p).Elem())
+	return base
+}
+
+// Redacted возвращает копию настроек со скрытыми паролями в адресах прокси
+func (p ProfileNetwork) Redacted() ProfileNetwork {
+	if p.Proxy != nil {
+		proxy := redactProxy(*p.Proxy)
+		p.Proxy = &proxy
+	}
+	if p.HTTPSProxy != nil {
+		proxy := redactProxy(*p.HTTPSProxy)
+		p.HTTPSProxy = &proxy
+	}
+	return p
+}
+
+// ProfileNames возвращает имена профилей по алфавиту
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyProfile накладывает профиль name на конфигурацию и делает его активным.
+// Пустое имя и ProfileDefault оставляют базовую конфигурацию.
+func (c *Config) ApplyProfile(name string) error {
+	_, err := c.applyProfile(name)
+	return err
+}
+
+// applyProfile накладывает профиль и возвращает имена измененных полей
+func (c *Config) applyProfile(name string) ([]string, error) {
+	if name == "" || name == ProfileDefault {
+		return nil, nil
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		available := strings.Join(append([]string{ProfileDefault}, c.ProfileNames()...), ", ")
+		return nil, fmt.Errorf("unknown profile %q, available: %s", name, available)
+	}
+
+	var applied []string
+	target := reflect.ValueOf(c).Elem()
+	source := reflect.ValueOf(profile)
+	for i := 0; i < source.NumField(); i++ {
+		field, ok := fieldName(source.Type().Field(i))
+		if !ok {
+			continue
+		}
+		dest, ok := configField(target, field)
+		if !ok {
+			continue
+		}
+
+		value := source.Field(i)
+		switch {
+		case value.IsZero():
+			continue
+		case value.Kind() == reflect.Pointer && dest.Kind() == reflect.Struct:
+			overlay(dest, value.Elem())
+		case value.Kind() == reflect.Pointer && dest.Kind() != reflect.Pointer:
+			dest.Set(value.Elem())
+		case value.Kind() == reflect.Slice:
+			// Копия, чтобы изменения конфигурации не затрагивали профиль
+			dest.Set(reflect.AppendSlice(reflect.MakeSlice(value.Type(), 0, value.Len()), value))
+		default:
+			dest.Set(value)
+		}
+		applied = append(applied, field)
+	}
+
+	if profile.Offline {
+		c.Repositories = []types.Repository{}
+		applied = append(applied, "repositories")
+	}
+
+	c.Profile = name
+	applied = append(applied, "profile")
+	return applied, nil
+}
+
+// overlay копирует в структуру dest заданные поля-указатели структуры src
+func overlay(dest, src reflect.Value) {
+	for i := 0; i < src.NumField(); i++ {
+		value := src.Field(i)
+		if value.IsNil() {
+			continue
+		}
+		name, ok := fieldName(src.Type().Field(i))
+		if !ok {
+			continue
+		}
+		field, ok := configField(dest, name)
+		if !ok {
+			continue
+		}
+		value = value.Elem()
+		if value.Kind() == reflect.Slice {
+			// Копия, чтобы изменения конфигурации не затрагивали профиль
+			value = reflect.AppendSlice(reflect.MakeSlice(value.Type(), 0, value.Len()), value)
+		}
+		field.Set(value)
+	}
+}
+
+// configField ищет поле конфигурации по имени из тега json
+func configField(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if field, ok := fieldName(t.Field(i)); ok && field == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
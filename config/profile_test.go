@@ -0,0 +1,195 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// profileConfig конфигурация с сетевыми настройками и профилями для тестов
+const profileConfig = `configVersion: 2
+timeout: 30
+network:
+  proxy: http://proxy.example.com:3128
+  noProxy: [localhost, .corp.example.com]
+  retries: 5
+repositories:
+  - name: main
+    url: https://packages.example.com
+    enabled: true
+profile: public
+profiles:
+  public:
+    timeout: 0
+  internal-mirror:
+    region: eu
+    network:
+      noProxy: []
+      retries: 0
+    repositories:
+      - name: mirror
+        url: https://mirror.internal
+        enabled: true
+  offline:
+    offline: true
+`
+
+func TestApplyProfile(t *testing.T) {
+	cfg, err := LoadConfig(writeConfigFile(t, "config.yaml", profileConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ApplyProfile("internal-mirror"); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Profile != "internal-mirror" || cfg.Region != "eu" {
+		t.Errorf("profile = %q, region = %q", cfg.Profile, cfg.Region)
+	}
+	if len(cfg.Repositories) != 1 || cfg.Repositories[0].Name != "mirror" {
+		t.Errorf("repositories = %+v, want mirror only", cfg.Repositories)
+	}
+	// Нулевые и пустые значения профиля заменяют базовые
+	if cfg.Network.Retries != 0 {
+		t.Errorf("network.retries = %d, want 0", cfg.Network.Retries)
+	}
+	if len(cfg.Network.NoProxy) != 0 {
+		t.Errorf("network.noProxy = %v, want empty", cfg.Network.NoProxy)
+	}
+	// Незаданные поля профиля сохраняют базовые значения
+	if cfg.Network.Proxy != "http://proxy.example.com:3128" || cfg.Timeout != 30 {
+		t.Errorf("proxy = %q, timeout = %d, want base values", cfg.Network.Proxy, cfg.Timeout)
+	}
+
+	// Изменения конфигурации не затрагивают профиль
+	cfg.Repositories[0].Name = "changed"
+	if name := cfg.Profiles["internal-mirror"].Repositories[0].Name; name != "mirror" {
+		t.Errorf("profile repository changed to %q", name)
+	}
+}
+
+func TestApplyProfileZeroTimeout(t *testing.T) {
+	cfg, err := LoadConfig(writeConfigFile(t, "config.yaml", profileConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ApplyProfile("public"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Timeout != 0 {
+		t.Errorf("timeout = %d, want 0", cfg.Timeout)
+	}
+}
+
+func TestApplyProfileDefaultAndUnknown(t *testing.T) {
+	cfg, err := LoadConfig(writeConfigFile(t, "config.yaml", profileConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := *cfg
+
+	for _, name := range []string{"", ProfileDefault} {
+		if err := cfg.ApplyProfile(name); err != nil {
+			t.Errorf("ApplyProfile(%q) error = %v", name, err)
+		}
+		if !reflect.DeepEqual(*cfg, base) {
+			t.Errorf("ApplyProfile(%q) changed the base configuration", name)
+		}
+	}
+
+	err = cfg.ApplyProfile("missing")
+	if err == nil {
+		t.Fatal("ApplyProfile(missing) succeeded")
+	}
+	if !strings.Contains(err.Error(), "default, internal-mirror, offline, public") {
+		t.Errorf("error does not list available profiles: %v", err)
+	}
+}
+
+func TestApplyProfileOffline(t *testing.T) {
+	cfg, err := LoadConfig(writeConfigFile(t, "config.yaml", profileConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ApplyProfile("offline"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Repositories == nil || len(cfg.Repositories) != 0 {
+		t.Errorf("repositories = %#v, want empty list", cfg.Repositories)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("offline configuration invalid: %v", err)
+	}
+}
+
+func TestLoaderProfilePrecedence(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", profileConfig)
+
+	tests := []struct {
+		name    string
+		profile string
+		environ []string
+		want    string
+		layer   Layer
+	}{
+		{"file", "", []string{}, "public", LayerUser},
+		{"environment", "", []string{ProfileEnv + "=offline"}, "offline", LayerEnv},
+		{"argument", "internal-mirror", []string{ProfileEnv + "=offline"}, "internal-mirror", LayerOverride},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader := &Loader{UserPath: path, Environ: tt.environ, Profile: tt.profile}
+			layered, err := loader.Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if layered.Config.Profile != tt.want {
+				t.Errorf("profile = %q, want %q", layered.Config.Profile, tt.want)
+			}
+			if origin := layered.Origin("profile"); origin.Layer != tt.layer {
+				t.Errorf("profile origin = %v, want %v", origin.Layer, tt.layer)
+			}
+		})
+	}
+}
+
+func TestLoaderProfileOrigins(t *testing.T) {
+	loader := &Loader{UserPath: writeConfigFile(t, "config.yaml", profileConfig), Environ: []string{}, Profile: "internal-mirror"}
+	layered, err := loader.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"network", "region", "repositories"} {
+		if origin := layered.Origin(field); origin.Layer != LayerProfile || origin.Source != "internal-mirror" {
+			t.Errorf("%s origin = %+v, want profile internal-mirror", field, origin)
+		}
+	}
+	if origin := layered.Origin("timeout"); origin.Layer != LayerUser {
+		t.Errorf("timeout origin = %+v, want user file", origin)
+	}
+}
+
+func TestLoaderUnknownProfile(t *testing.T) {
+	loader := &Loader{UserPath: writeConfigFile(t, "config.yaml", profileConfig), Environ: []string{ProfileEnv + "=missing"}}
+	if _, err := loader.Load(); err == nil {
+		t.Error("Load accepted an unknown profile from CRIAGE_PROFILE")
+	}
+}
+
+func TestValidateProfileNetwork(t *testing.T) {
+	cfg := DefaultConfig()
+	retries := -1
+	proxy := "ftp://proxy.example.com"
+	cfg.Profiles = map[string]Profile{"bad": {Network: &ProfileNetwork{Retries: &retries, Proxy: &proxy}}}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate accepted invalid profile network")
+	}
+	for _, field := range []string{"profiles.bad.network.retries", "profiles.bad.network.proxy"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("error does not mention %s: %v", field, err)
+		}
+	}
+}
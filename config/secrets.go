@@ -0,0 +1,584 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+// Ссылки на секреты вместо значений в открытом виде:
+//
+//	env:VAR          переменная окружения
+//	file:/path       содержимое файла (без завершающего перевода строки)
+//	netrc            запись ~/.netrc для хоста репозитория
+//	helper:command   внешняя программа учетных данных
+const (
+	SecretEnvPrefix    = "env:"
+	SecretFilePrefix   = "file:"
+	SecretNetrc        = "netrc"
+	SecretHelperPrefix = "helper:"
+)
+
+// RedactedValue значение, которым заменяются секреты при выводе
+const RedactedValue = "********"
+
+// DefaultHelperTimeout ограничение времени работы программы учетных данных
+const DefaultHelperTimeout = 30 * time.Second
+
+// ErrProjectSecretReference проектный файл ссылается на локальный файл или программу учетных данных
+var ErrProjectSecretReference = errors.New("file: and helper: secret references are only allowed in system and user config")
+
+// IsSecretReference проверяет, является ли значение ссылкой на секрет
+func IsSecretReference(value string) bool {
+	return value == SecretNetrc ||
+		strings.HasPrefix(value, SecretEnvPrefix) ||
+		strings.HasPrefix(value, SecretFilePrefix) ||
+		strings.HasPrefix(value, SecretHelperPrefix)
+}
+
+// RedactSecret скрывает секрет; ссылки на секреты не скрываются
+func RedactSecret(value string) string {
+	if value == "" || IsSecretReference(value) {
+		return value
+	}
+	return RedactedValue
+}
+
+// RedactRepository возвращает копию репозитория со скрытыми учетными данными
+func RedactRepository(repo types.Repository) types.Repository {
+	repo.AuthToken = RedactSecret(repo.AuthToken)
+	repo.Password = RedactSecret(repo.Password)
+	return repo
+}
+
+// secretField поле учетных данных репозитория
+type secretField int
+
+const (
+	fieldToken secretField = iota
+	fieldUsername
+	fieldPassword
+)
+
+// helperCredentials ответ программы учетных данных
+type helperCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Token    string `json:"token"`
+	// plain ответ в виде строки, а не JSON
+	plain string
+}
+
+// SecretResolver разрешает ссылки на секреты во время выполнения
+type SecretResolver struct {
+	// LookupEnv поиск переменных окружения (по умолчанию os.LookupEnv)
+	LookupEnv func(string) (string, bool)
+	// NetrcPath путь к файлу netrc (по умолчанию $NETRC или ~/.netrc)
+	NetrcPath string
+	// HelperTimeout ограничение времени работы программы учетных данных
+	HelperTimeout time.Duration
+
+	helpers map[string]*helperCredentials
+	netrc   []netrcEntry
+}
+
+// NewSecretResolver создает резолвер с окружением процесса
+func NewSecretResolver() *SecretResolver {
+	return &SecretResolver{
+		LookupEnv:     os.LookupEnv,
+		NetrcPath:     defaultNetrcPath(),
+		HelperTimeout: DefaultHelperTimeout,
+	}
+}
+
+// ResolveSecret разрешает ссылку на секрет; прочие значения возвращаются как есть
+func ResolveSecret(value string) (string, error) {
+	return NewSecretResolver().Resolve(value)
+}
+
+// ResolveRepository возвращает копию репозитория с разрешенными учетными данными
+func ResolveRepository(repo types.Repository) (types.Repository, error) {
+	return NewSecretResolver().ResolveRepository(repo)
+}
+
+// Resolve разрешает ссылку на секрет, не привязанную к репозиторию
+func (r *SecretResolver) Resolve(value string) (string, error) {
+	return r.resolve(value, "", fieldToken)
+}
+
+// ResolveRepository разрешает AuthToken, Username и Password репозитория
+func (r *SecretResolver) ResolveRepository(repo types.Repository) (types.Repository, error) {
+	fields := []struct {
+		name  string
+		field secretField
+		value *string
+	}{
+		{"authToken", fieldToken, &repo.AuthToken},
+		{"username", fieldUsername, &repo.Username},
+		{"password", fieldPassword, &repo.Password},
+	}
+	for _, f := range fields {
+		resolved, err := r.resolve(*f.value, repo.URL, f.field)
+		if err != nil {
+			return repo, fmt.Errorf("failed to resolve %s of repository %s: %w", f.name, repo.Name, err)
+		}
+		*f.value = resolved
+	}
+	return repo, nil
+}
+
+// resolve разрешает значение поля field для репозитория repoURL
+func (r *SecretResolver) resolve(value, repoURL string, field secretField) (string, error) {
+	switch {
+	case strings.HasPrefix(value, SecretEnvPrefix):
+		name := strings.TrimPrefix(value, SecretEnvPrefix)
+		lookup := r.LookupEnv
+		if lookup == nil {
+			lookup = os.LookupEnv
+		}
+		secret, ok := lookup(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return secret, nil
+
+	case strings.HasPrefix(value, SecretFilePrefix):
+		path := expandHome(strings.TrimPrefix(value, SecretFilePrefix))
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+
+	case value == SecretNetrc:
+		return r.resolveNetrc(repoURL, field)
+
+	case strings.HasPrefix(value, SecretHelperPrefix):
+		creds, err := r.runHelper(strings.TrimPrefix(value, SecretHelperPrefix), repoURL)
+		if err != nil {
+			return "", err
+		}
+		return creds.field(field), nil
+	}
+	return value, nil
+}
+
+// field возвращает значение поля из ответа программы учетных данных
+func (c *helperCredentials) field(field secretField) string {
+	if c.plain != "" {
+		return c.plain
+	}
+	switch field {
+	case fieldUsername:
+		return c.Username
+	case fieldPassword:
+		return c.Password
+	default:
+		if c.Token != "" {
+			return c.Token
+		}
+		return c.Password
+	}
+}
+
+// runHelper запускает программу учетных данных с аргументом "get" и адресом
+// репозитория на stdin. Программа выводит JSON {"username", "password", "token"}
+// или сам секрет одной строкой. Результат кэшируется на время жизни резолвера.
+func (r *SecretResolver) runHelper(command, repoURL string) (*helperCredentials, error) {
+	key := command + "\x00" + repoURL
+	if creds, ok := r.helpers[key]; ok {
+		return creds, nil
+	}
+
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, errors.New("credential helper command is empty")
+	}
+
+	timeout := r.HelperTimeout
+	if timeout <= 0 {
+		timeout = DefaultHelperTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, args[0], append(args[1:], "get")...)
+	cmd.Stdin = strings.NewReader(repoURL + "\n")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("credential helper %s failed: %w: %s", args[0], err, message)
+		}
+		return nil, fmt.Errorf("credential helper %s failed: %w", args[0], err)
+	}
+
+	creds := &helperCredentials{}
+	trimmed := bytes.TrimSpace(output)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, creds); err != nil {
+			return nil, fmt.Errorf("credential helper %s returned invalid JSON: %w", args[0], err)
+		}
+	} else {
+		creds.plain = string(trimmed)
+	}
+
+	if r.helpers == nil {
+		r.helpers = make(map[string]*helperCredentials)
+	}
+	r.helpers[key] = creds
+	return creds, nil
+}
+
+// netrcEntry запись файла netrc; пустой machine - запись default
+type netrcEntry struct {
+	machine  string
+	login    string
+	password string
+}
+
+// resolveNetrc ищет учетные данные хоста репозитория в netrc
+func (r *SecretResolver) resolveNetrc(repoURL string, field secretField) (string, error) {
+	u, err := url.Parse(repoURL)
+	if err != nil || u.Hostname() == "" {
+		return "", fmt.Errorf("netrc lookup requires a repository URL")
+	}
+
+	if r.netrc == nil {
+		data, err := os.ReadFile(r.NetrcPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read netrc: %w", err)
+		}
+		r.netrc = parseNetrc(data)
+	}
+
+	var fallback *netrcEntry
+	for i := range r.netrc {
+		entry := &r.netrc[i]
+		if entry.machine == u.Hostname() {
+			return entry.value(field), nil
+		}
+		if entry.machine == "" && fallback == nil {
+			fallback = entry
+		}
+	}
+	if fallback != nil {
+		return fallback.value(field), nil
+	}
+	return "", fmt.Errorf("no netrc entry for %s", u.Hostname())
+}
+
+// value возвращает логин или пароль записи
+func (e *netrcEntry) value(field secretField) string {
+	if field == fieldUsername {
+		return e.login
+	}
+	return e.password
+}
+
+// parseNetrc разбирает файл netrc
+func parseNetrc(data []byte) []netrcEntry {
+	var entries []netrcEntry
+	var current *netrcEntry
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	inMacro := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		// Тело macdef продолжается до пустой строки
+		if inMacro {
+			inMacro = strings.TrimSpace(line) != ""
+			continue
+		}
+
+		fields := strings.Fields(line)
+		for i := 0; i < len(fields); i++ {
+			if strings.HasPrefix(fields[i], "#") {
+				break
+			}
+			next := func() string {
+				if i+1 < len(fields) {
+					i++
+					return fields[i]
+				}
+				return ""
+			}
+
+			switch fields[i] {
+			case "machine":
+				entries = append(entries, netrcEntry{machine: next()})
+				current = &entries[len(entries)-1]
+			case "default":
+				entries = append(entries, netrcEntry{})
+				current = &entries[len(entries)-1]
+			case "login":
+				if value := next(); current != nil {
+					current.login = value
+				}
+			case "password":
+				if value := next(); current != nil {
+					current.password = value
+				}
+			case "account":
+				next()
+			case "macdef":
+				next()
+				inMacro = true
+				i = len(fields)
+			}
+		}
+	}
+	return entries
+}
+
+// defaultNetrcPath возвращает путь к netrc: $NETRC, ~/.netrc или ~/_netrc на Windows
+func defaultNetrcPath() string {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+	homeDir, _ := os.UserHomeDir()
+	if runtime.GOOS == "windows" {
+		return filepath.Join(homeDir, "_netrc")
+	}
+	return filepath.Join(homeDir, ".netrc")
+}
+
+// expandHome раскрывает "~/" в начале пути
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(homeDir, rest)
+		}
+	}
+	return path
+}
+
+// SecretWarning секрет записан открытым текстом в файле, доступном всем пользователям
+type SecretWarning struct {
+	Path  string
+	Field string
+}
+
+func (w SecretWarning) String() string {
+	return fmt.Sprintf("%s: %s is stored in plain text in a world-readable file; "+
+		"use an env:, file:, netrc or helper: reference or chmod 600", w.Path, w.Field)
+}
+
+// Поля, значения которых считаются секретами
+var secretKeys = map[string]bool{
+	"authToken": true,
+	"password":  true,
+}
+
+// CheckSecrets проверяет файл конфигурации: если он доступен на чтение всем
+// пользователям, возвращает предупреждения о секретах в открытом виде
+func CheckSecrets(path string) ([]SecretWarning, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !worldReadable(info.Mode()) {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return literalSecrets(path, data)
+}
+
+// literalSecrets ищет секреты в открытом виде в содержимом файла
+func literalSecrets(path string, data []byte) ([]SecretWarning, error) {
+	var warnings []SecretWarning
+	err := walkStrings(path, data, func(field, key, value string) {
+		if secretKeys[key] && value != "" && !IsSecretReference(value) {
+			warnings = append(warnings, SecretWarning{Path: path, Field: field})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return warnings, nil
+}
+
+// localSecretReferences возвращает поля учетных данных, которые ссылаются на
+// локальные файлы или программы (file:, helper:)
+func localSecretReferences(path string, data []byte) ([]string, error) {
+	var fields []string
+	err := walkStrings(path, data, func(field, key, value string) {
+		if !credentialKeys[key] {
+			return
+		}
+		if strings.HasPrefix(value, SecretFilePrefix) || strings.HasPrefix(value, SecretHelperPrefix) {
+			fields = append(fields, field)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// Поля учетных данных репозитория, принимающие ссылки на секреты
+var credentialKeys = map[string]bool{
+	"authToken": true,
+	"username":  true,
+	"password":  true,
+}
+
+// walkStrings вызывает fn для каждого строкового значения документа с путем к полю и его ключом
+func walkStrings(path string, data []byte, fn func(field, key, value string)) error {
+	var document any
+	if err := decode(path, data, &document); err != nil {
+		return fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	var walk func(node any, field string)
+	walk = func(node any, field string) {
+		switch node := node.(type) {
+		case map[string]any:
+			for _, key := range sortedKeys(node) {
+				child := joinField(field, key)
+				if value, ok := node[key].(string); ok {
+					fn(child, key, value)
+					continue
+				}
+				walk(node[key], child)
+			}
+		case []any:
+			for i, item := range node {
+				walk(item, fmt.Sprintf("%s[%d]", field, i))
+			}
+		}
+	}
+	walk(document, "")
+	return nil
+}
+
+// joinField объединяет путь к полю
+func joinField(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// sortedKeys возвращает ключи карты в порядке возрастания
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ResolvedRepositories возвращает включенные репозитории с разрешенными учетными данными
+func (c *Config) ResolvedRepositories() ([]types.Repository, error) {
+	resolver := NewSecretResolver()
+	var repos []types.Repository
+	for _, repo := range c.Repositories {
+		if !repo.Enabled {
+			continue
+		}
+		resolved, err := resolver.ResolveRepository(repo)
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, resolved)
+	}
+	return repos, nil
+}
+
+// Redacted возвращает копию конфигурации со скрытыми секретами для вывода и журналов
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redacted.Network = c.Network.Redacted()
+	redacted.Repositories = make([]types.Repository, len(c.Repositories))
+	for i, repo := range c.Repositories {
+		redacted.Repositories[i] = RedactRepository(repo)
+	}
+	if c.Profiles != nil {
+		redacted.Profiles = make(map[string]Profile, len(c.Profiles))
+		for name, profile := range c.Profiles {
+			repos := make([]types.Repository, len(profile.Repositories))
+			for i, repo := range profile.Repositories {
+				repos[i] = RedactRepository(repo)
+			}
+			if profile.Repositories == nil {
+				repos = nil
+			}
+			profile.Repositories = repos
+			if profile.Network != nil {
+				network := profile.Network.Redacted()
+				profile.Network = &network
+			}
+			redacted.Profiles[name] = profile
+		}
+	}
+	return &redacted
+}
+
+// String возвращает конфигурацию в виде JSON со скрытыми секретами
+func (c *Config) String() string {
+	return redactedJSON(c.Redacted())
+}
+
+// LogValue скрывает секреты при записи конфигурации в slog
+func (c *Config) LogValue() slog.Value {
+	return slog.StringValue(c.String())
+}
+
+// Redacted возвращает копию конфигурации сервера со скрытым токеном
+func (c *ServerConfig) Redacted() *ServerConfig {
+	redacted := *c
+	redacted.AuthToken = RedactSecret(c.AuthToken)
+	return &redacted
+}
+
+// String возвращает конфигурацию сервера в виде JSON со скрытыми секретами
+func (c *ServerConfig) String() string {
+	return redactedJSON(c.Redacted())
+}
+
+// LogValue скрывает секреты при записи конфигурации сервера в slog
+func (c *ServerConfig) LogValue() slog.Value {
+	return slog.StringValue(c.String())
+}
+
+// ResolvedAuthToken возвращает токен сервера с разрешенной ссылкой на секрет
+func (c *ServerConfig) ResolvedAuthToken() (string, error) {
+	return ResolveSecret(c.AuthToken)
+}
+
+// redactedJSON сериализует уже очищенную от секретов конфигурацию
+func redactedJSON(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("<invalid config: %v>", err)
+	}
+	return string(data)
+}
+
+// worldReadable проверяет, доступен ли файл на чтение всем пользователям
+func worldReadable(mode fs.FileMode) bool {
+	return runtime.GOOS != "windows" && mode.Perm()&0004 != 0
+}
@@ -0,0 +1,304 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/criage-oss/criage-common/encryption"
+	"github.com/criage-oss/criage-common/types"
+)
+
+// Максимальный уровень сжатия (zstd)
+const MaxCompressionLevel = 22
+
+// LogLevels допустимые уровни журналирования
+var LogLevels = []string{"debug", "info", "warn", "error"}
+
+// FieldError ошибка в значении поля конфигурации
+type FieldError struct {
+	// Field путь к полю, например "repositories[0].url"
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError все ошибки, найденные при проверке конфигурации
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		lines[i] = err.Error()
+	}
+	return "invalid config:\n  - " + strings.Join(lines, "\n  - ")
+}
+
+// validator накапливает ошибки полей
+type validator struct {
+	errors []FieldError
+}
+
+func (v *validator) addf(field, format string, args ...any) {
+	v.errors = append(v.errors, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.addf(field, "is required")
+	}
+}
+
+func (v *validator) min(field string, value, min int64) {
+	if value < min {
+		v.addf(field, "must be at least %d, got %d", min, value)
+	}
+}
+
+func (v *validator) between(field string, value, min, max int64) {
+	if value < min || value > max {
+		v.addf(field, "must be between %d and %d, got %d", min, max, value)
+	}
+}
+
+func (v *validator) url(field, value string) {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || (u.Host == "" && u.Scheme != "file") {
+		v.addf(field, "invalid URL %q", value)
+	}
+}
+
+func (v *validator) keyPair(field, certFile, keyFile string) {
+	switch {
+	case certFile != "" && keyFile == "":
+		v.addf(field+".keyFile", "is required when certFile is set")
+	case certFile == "" && keyFile != "":
+		v.addf(field+".certFile", "is required when keyFile is set")
+	}
+}
+
+func (v *validator) logLevel(field, value string) {
+	for _, level := range LogLevels {
+		if value == level {
+			return
+		}
+	}
+	v.addf(field, "unknown log level %q, expected one of %s", value, strings.Join(LogLevels, ", "))
+}
+
+func (v *validator) err() error {
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: v.errors}
+}
+
+// Validate проверяет конфигурацию клиента
+func (c *Config) Validate() error {
+	var v validator
+
+	v.between("configVersion", int64(c.ConfigVersion), LegacyVersion, CurrentVersion)
+	v.required("installPath", c.InstallPath)
+	v.required("cachePath", c.CachePath)
+	v.required("tempPath", c.TempPath)
+
+	v.min("timeout", int64(c.Timeout), 0)
+	v.min("maxConnections", int64(c.MaxConnections), 1)
+	v.min("maxParallel", int64(c.MaxParallel), 1)
+	validateNetwork(&v, "network", c.Network)
+	v.between("compressionLevel", int64(c.CompressionLevel), 0, MaxCompressionLevel)
+
+	if !types.ArchiveFormat(c.PreferredFormat).Valid() {
+		v.addf("preferredFormat", "unsupported archive format %q", c.PreferredFormat)
+	}
+
+	names := make(map[string]bool)
+	for i, repo := range c.Repositories {
+		field := fmt.Sprintf("repositories[%d]", i)
+		validateRepository(&v, field, repo)
+		if repo.Name != "" {
+			if names[repo.Name] {
+				v.addf(field+".name", "duplicate repository %q", repo.Name)
+			}
+			names[repo.Name] = true
+		}
+	}
+
+	if c.Profile != "" && c.Profile != ProfileDefault {
+		if _, ok := c.Profiles[c.Profile]; !ok {
+			v.addf("profile", "unknown profile %q", c.Profile)
+		}
+	}
+	for _, name := range c.ProfileNames() {
+		profile := c.Profiles[name]
+		field := "profiles." + name
+		if name == ProfileDefault {
+			v.addf(field, "profile name %q is reserved for the base configuration", name)
+		}
+		if profile.Timeout != nil {
+			v.min(field+".timeout", int64(*profile.Timeout), 0)
+		}
+		if profile.MaxConnections != nil {
+			v.min(field+".maxConnections", int64(*profile.MaxConnections), 1)
+		}
+		if profile.Network != nil {
+			validateNetwork(&v, field+".network", profile.Network.Apply(NetworkConfig{}))
+		}
+		for i, repo := range profile.Repositories {
+			validateRepository(&v, fmt.Sprintf("%s.repositories[%d]", field, i), repo)
+		}
+	}
+
+	for i, recipient := range c.EncryptionRecipients {
+		if _, err := encryption.ParseRecipient(recipient); err != nil {
+			v.addf(fmt.Sprintf("encryptionRecipients[%d]", i), "%v", err)
+		}
+	}
+	for i, path := range c.IdentityFiles {
+		v.required(fmt.Sprintf("identityFiles[%d]", i), path)
+	}
+
+	return v.err()
+}
+
+// validateRepository проверяет настройки репозитория
+func validateRepository(v *validator, field string, repo types.Repository) {
+	v.required(field+".name", repo.Name)
+	if strings.TrimSpace(repo.URL) == "" {
+		v.addf(field+".url", "is required")
+	} else {
+		v.url(field+".url", repo.URL)
+	}
+
+	switch repo.TrustMode {
+	case "", types.TrustModeStrict, types.TrustModeTOFU, types.TrustModeOff:
+	default:
+		v.addf(field+".trustMode", "unknown trust mode %q", repo.TrustMode)
+	}
+	if repo.RequireSignature && repo.TrustMode == types.TrustModeOff {
+		v.addf(field+".trustMode", "signature checks are off but requireSignature is set")
+	}
+	mirrors := make(map[string]bool, len(repo.Mirrors))
+	for i, mirror := range repo.Mirrors {
+		mirrorField := fmt.Sprintf("%s.mirrors[%d].url", field, i)
+		if strings.TrimSpace(mirror.URL) == "" {
+			v.addf(mirrorField, "is required")
+			continue
+		}
+		v.url(mirrorField, mirror.URL)
+		key := types.NormalizeMirrorURL(mirror.URL)
+		if mirrors[key] {
+			v.addf(mirrorField, "duplicate mirror %q", mirror.URL)
+		}
+		mirrors[key] = true
+	}
+	if repo.RevocationURL != "" {
+		v.url(field+".revocationUrl", repo.RevocationURL)
+	}
+	validateSecret(v, field+".authToken", repo.AuthToken)
+	validateSecret(v, field+".username", repo.Username)
+	validateSecret(v, field+".password", repo.Password)
+	if repo.Password != "" && repo.Username == "" {
+		v.addf(field+".username", "is required when password is set")
+	}
+
+	v.min(field+".timeout", int64(repo.Timeout), 0)
+	if repo.Retries != nil {
+		v.min(field+".retries", int64(*repo.Retries), 0)
+	}
+	v.keyPair(field, repo.CertFile, repo.KeyFile)
+}
+
+// validateNetwork проверяет адреса прокси, список исключений и сертификаты
+func validateNetwork(v *validator, field string, n NetworkConfig) {
+	if _, err := parseProxy(n.Proxy); err != nil {
+		v.addf(field+".proxy", "%v", err)
+	}
+	if _, err := parseProxy(n.HTTPSProxy); err != nil {
+		v.addf(field+".httpsProxy", "%v", err)
+	}
+	for i, entry := range n.NoProxy {
+		if _, err := parseNoProxy([]string{entry}); err != nil {
+			v.addf(fmt.Sprintf("%s.noProxy[%d]", field, i), "%v", err)
+		}
+	}
+	for i, path := range n.CAFiles {
+		v.required(fmt.Sprintf("%s.caFiles[%d]", field, i), path)
+	}
+	v.keyPair(field, n.CertFile, n.KeyFile)
+	v.min(field+".retries", int64(n.Retries), 0)
+}
+
+// Validate проверяет конфигурацию сервера
+func (c *ServerConfig) Validate() error {
+	var v validator
+
+	v.between("configVersion", int64(c.ConfigVersion), LegacyVersion, CurrentVersion)
+	v.between("port", int64(c.Port), 1, 65535)
+	v.required("storagePath", c.StoragePath)
+	v.required("indexPath", c.IndexPath)
+
+	if c.AuthEnabled && strings.TrimSpace(c.AuthToken) == "" {
+		v.addf("authToken", "is required when authEnabled is true")
+	}
+	validateSecret(&v, "authToken", c.AuthToken)
+
+	v.min("maxFileSize", c.MaxFileSize, 1)
+	v.min("rateLimit", int64(c.RateLimit), 0)
+
+	if len(c.AllowedFormats) == 0 {
+		v.addf("allowedFormats", "at least one format is required")
+	}
+	for i, format := range c.AllowedFormats {
+		if format != "criage" && !types.ArchiveFormat(format).Valid() {
+			v.addf(fmt.Sprintf("allowedFormats[%d]", i), "unsupported archive format %q", format)
+		}
+	}
+
+	v.logLevel("logLevel", c.LogLevel)
+
+	if c.CORSEnabled && len(c.CORSOrigins) == 0 {
+		v.addf("corsOrigins", "at least one origin is required when corsEnabled is true")
+	}
+	for i, origin := range c.CORSOrigins {
+		if origin != "*" {
+			v.url(fmt.Sprintf("corsOrigins[%d]", i), origin)
+		}
+	}
+
+	return v.err()
+}
+
+// Validate проверяет конфигурацию MCP сервера
+func (c *MCPConfig) Validate() error {
+	var v validator
+
+	v.between("configVersion", int64(c.ConfigVersion), LegacyVersion, CurrentVersion)
+	v.required("criageClientPath", c.CriageClientPath)
+	v.min("maxConcurrency", int64(c.MaxConcurrency), 1)
+	v.min("timeout", int64(c.Timeout), 0)
+	v.logLevel("logLevel", c.LogLevel)
+
+	for i, operation := range c.AllowedOperations {
+		v.required(fmt.Sprintf("allowedOperations[%d]", i), operation)
+	}
+	for i, path := range c.RestrictedPaths {
+		v.required(fmt.Sprintf("restrictedPaths[%d]", i), path)
+	}
+
+	return v.err()
+}
+
+// validateSecret проверяет синтаксис ссылки на секрет
+func validateSecret(v *validator, field, value string) {
+	for _, prefix := range []string{SecretEnvPrefix, SecretFilePrefix, SecretHelperPrefix} {
+		if value == prefix || (strings.HasPrefix(value, prefix) && strings.TrimSpace(value[len(prefix):]) == "") {
+			v.addf(field, "secret reference %q has no target", value)
+		}
+	}
+}
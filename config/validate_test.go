@@ -0,0 +1,107 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+// validationFields возвращает пути полей из *ValidationError
+func validationFields(t *testing.T, err error) []string {
+	t.Helper()
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("error = %v, want ValidationError", err)
+	}
+	fields := make([]string, len(validationErr.Errors))
+	for i, fieldErr := range validationErr.Errors {
+		fields[i] = fieldErr.Field
+	}
+	return fields
+}
+
+func TestDefaultConfigsAreValid(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Errorf("DefaultConfig: %v", err)
+	}
+	if err := DefaultServerConfig().Validate(); err != nil {
+		t.Errorf("DefaultServerConfig: %v", err)
+	}
+	if err := DefaultMCPConfig().Validate(); err != nil {
+		t.Errorf("DefaultMCPConfig: %v", err)
+	}
+}
+
+func TestConfigValidateAggregatesErrors(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.CachePath = " "
+	cfg.Timeout = -1
+	cfg.MaxParallel = 0
+	cfg.PreferredFormat = "rar"
+	cfg.Network.Proxy = "ftp://proxy.example.com"
+	cfg.Repositories = append(cfg.Repositories,
+		types.Repository{Name: "official", URL: "packages.example.com", Password: "secret"},
+		types.Repository{Name: "mirrored", URL: "https://example.com", Mirrors: []types.Mirror{
+			{URL: "https://m.example.com/"}, {URL: "https://m.example.com"},
+		}},
+	)
+	cfg.Profile = "ci"
+
+	want := []string{
+		"cachePath",
+		"timeout",
+		"maxParallel",
+		"network.proxy",
+		"preferredFormat",
+		"repositories[1].url",
+		"repositories[1].username",
+		"repositories[1].name",
+		"repositories[2].mirrors[1].url",
+		"profile",
+	}
+	got := validationFields(t, cfg.Validate())
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("fields = %v, want %v", got, want)
+	}
+}
+
+func TestServerConfigValidate(t *testing.T) {
+	cfg := DefaultServerConfig()
+	cfg.Port = 0
+	cfg.MaxFileSize = 0
+	cfg.AuthEnabled = true
+	cfg.AuthToken = ""
+	cfg.AllowedFormats = []string{"zip", "rar"}
+	cfg.LogLevel = "verbose"
+
+	want := []string{"port", "authToken", "maxFileSize", "allowedFormats[1]", "logLevel"}
+	got := validationFields(t, cfg.Validate())
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("fields = %v, want %v", got, want)
+	}
+}
+
+func TestMCPConfigValidate(t *testing.T) {
+	cfg := DefaultMCPConfig()
+	cfg.MaxConcurrency = 0
+	cfg.ConfigVersion = CurrentVersion + 1
+
+	want := []string{"configVersion", "maxConcurrency"}
+	got := validationFields(t, cfg.Validate())
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("fields = %v, want %v", got, want)
+	}
+}
+
+func TestValidationErrorMessage(t *testing.T) {
+	err := &ValidationError{Errors: []FieldError{
+		{Field: "timeout", Message: "must be at least 0, got -1"},
+		{Field: "repositories[0].url", Message: "is required"},
+	}}
+	want := "invalid config:\n  - timeout: must be at least 0, got -1\n  - repositories[0].url: is required"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
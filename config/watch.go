@@ -0,0 +1,308 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Значения по умолчанию для наблюдения за файлом
+const (
+	DefaultPollInterval = 2 * time.Second
+	DefaultDebounce     = 100 * time.Millisecond
+)
+
+// WatchOptions параметры наблюдения за файлом конфигурации
+type WatchOptions struct {
+	// PollInterval период опроса, если уведомления файловой системы недоступны
+	PollInterval time.Duration
+	// Debounce задержка перед перечитыванием после серии изменений
+	Debounce time.Duration
+	// ForcePolling не использовать уведомления файловой системы
+	ForcePolling bool
+	// OnError вызывается, если новая версия файла не загрузилась или не прошла
+	// проверку; при этом продолжает действовать предыдущая конфигурация
+	OnError func(error)
+}
+
+// Watcher следит за файлом конфигурации и атомарно публикует проверенные
+// версии подписчикам. Уведомления файловой системы (inotify и аналоги)
+// используются, когда доступны, иначе файл периодически опрашивается.
+type Watcher[T any] struct {
+	path    string
+	load    func(path string, data []byte) (*T, error)
+	options WatchOptions
+
+	current atomic.Pointer[T]
+	digest  [sha256.Size]byte
+
+	mu          sync.Mutex
+	subscribers map[int]func(*T)
+	nextID      int
+
+	notify  *fsnotify.Watcher
+	polling bool
+	reload  chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// NewServerConfigWatcher следит за конфигурацией сервера: файл и переменные CRIAGE_SERVER_*.
+// Файлы старых версий обновляются только в памяти: запись на диск вызвала бы
+// повторное уведомление об изменении.
+func NewServerConfigWatcher(path string, options WatchOptions) (*Watcher[ServerConfig], error) {
+	return NewWatcher(path, func(path string, data []byte) (*ServerConfig, error) {
+		cfg := DefaultServerConfig()
+		if err := decodeFile(path, data, KindServer, cfg); err != nil {
+			return nil, err
+		}
+		if err := ApplyServerEnv(cfg, nil); err != nil {
+			return nil, err
+		}
+		if err := cfg.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return cfg, nil
+	}, options)
+}
+
+// NewMCPConfigWatcher следит за конфигурацией MCP сервера: файл и переменные CRIAGE_MCP_*.
+// Файлы старых версий, как и для сервера, обновляются только в памяти.
+func NewMCPConfigWatcher(path string, options WatchOptions) (*Watcher[MCPConfig], error) {
+	return NewWatcher(path, func(path string, data []byte) (*MCPConfig, error) {
+		cfg := DefaultMCPConfig()
+		if err := decodeFile(path, data, KindMCP, cfg); err != nil {
+			return nil, err
+		}
+		if err := ApplyMCPEnv(cfg, nil); err != nil {
+			return nil, err
+		}
+		if err := cfg.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return cfg, nil
+	}, options)
+}
+
+// NewWatcher загружает файл функцией load и начинает следить за ним.
+// load получает прочитанное содержимое файла и не должна изменять файл.
+// Первая загрузка должна быть успешной.
+func NewWatcher[T any](path string, load func(path string, data []byte) (*T, error), options WatchOptions) (*Watcher[T], error) {
+	if options.PollInterval <= 0 {
+		options.PollInterval = DefaultPollInterval
+	}
+	if options.Debounce <= 0 {
+		options.Debounce = DefaultDebounce
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher[T]{
+		path:        absPath,
+		load:        load,
+		options:     options,
+		subscribers: make(map[int]func(*T)),
+		reload:      make(chan struct{}, 1),
+		done:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+	}
+
+	if _, err := w.loadFile(); err != nil {
+		return nil, err
+	}
+
+	if !options.ForcePolling {
+		w.notify, err = newNotifyWatcher(filepath.Dir(absPath))
+	}
+	w.polling = options.ForcePolling || err != nil
+
+	go w.run()
+	return w, nil
+}
+
+// newNotifyWatcher следит за директорией: редакторы и атомарная запись
+// заменяют файл, и наблюдение за самим файлом теряется
+func newNotifyWatcher(dir string) (*fsnotify.Watcher, error) {
+	notify, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := notify.Add(dir); err != nil {
+		notify.Close()
+		return nil, err
+	}
+	return notify, nil
+}
+
+// Current возвращает действующую конфигурацию
+func (w *Watcher[T]) Current() *T {
+	return w.current.Load()
+}
+
+// Polling сообщает, используется ли опрос вместо уведомлений файловой системы
+func (w *Watcher[T]) Polling() bool {
+	return w.polling
+}
+
+// Subscribe регистрирует обработчик новых версий конфигурации.
+// Обработчики вызываются последовательно из горутины наблюдателя.
+// Возвращает функцию отмены подписки.
+func (w *Watcher[T]) Subscribe(fn func(*T)) func() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	id := w.nextID
+	w.nextID++
+	w.subscribers[id] = fn
+
+	return func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.subscribers, id)
+	}
+}
+
+// Reload запрашивает перечитывание файла вне зависимости от уведомлений
+func (w *Watcher[T]) Reload() {
+	select {
+	case w.reload <- struct{}{}:
+	default:
+	}
+}
+
+// Close прекращает наблюдение
+func (w *Watcher[T]) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.done)
+		<-w.stopped
+		if w.notify != nil {
+			err = w.notify.Close()
+		}
+	})
+	return err
+}
+
+// run обрабатывает уведомления, опрос и запросы на перечитывание
+func (w *Watcher[T]) run() {
+	defer close(w.stopped)
+
+	var events chan fsnotify.Event
+	var errs chan error
+	if !w.polling {
+		events, errs = w.notify.Events, w.notify.Errors
+	}
+
+	var poll <-chan time.Time
+	if w.polling {
+		ticker := time.NewTicker(w.options.PollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	debounce := time.NewTimer(time.Hour)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if filepath.Clean(event.Name) == w.path && !event.Has(fsnotify.Chmod) {
+				debounce.Reset(w.options.Debounce)
+			}
+
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			w.reportError(fmt.Errorf("config watcher: %w", err))
+
+		case <-poll:
+			w.reloadFile()
+
+		case <-w.reload:
+			w.reloadFile()
+
+		case <-debounce.C:
+			w.reloadFile()
+		}
+	}
+}
+
+// reloadFile перечитывает файл и публикует новую версию, если содержимое изменилось
+func (w *Watcher[T]) reloadFile() {
+	cfg, err := w.loadFile()
+	if err != nil {
+		// Файл может временно отсутствовать во время замены
+		if !errors.Is(err, os.ErrNotExist) {
+			w.reportError(err)
+		}
+		return
+	}
+	if cfg == nil {
+		return
+	}
+
+	w.mu.Lock()
+	subscribers := make([]func(*T), 0, len(w.subscribers))
+	for id := 0; id < w.nextID; id++ {
+		if fn, ok := w.subscribers[id]; ok {
+			subscribers = append(subscribers, fn)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(cfg)
+	}
+}
+
+// loadFile загружает файл и делает его действующей конфигурацией. Разбирается
+// то же содержимое, от которого вычислен хеш. Возвращает nil без ошибки, если
+// содержимое не изменилось с прошлой попытки, поэтому ошибка в файле
+// сообщается один раз.
+func (w *Watcher[T]) loadFile() (*T, error) {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	digest := sha256.Sum256(data)
+	if w.current.Load() != nil && bytes.Equal(digest[:], w.digest[:]) {
+		return nil, nil
+	}
+	w.digest = digest
+
+	cfg, err := w.load(w.path, data)
+	if err != nil {
+		return nil, err
+	}
+	w.current.Store(cfg)
+	return cfg, nil
+}
+
+// reportError передает ошибку обработчику OnError
+func (w *Watcher[T]) reportError(err error) {
+	if w.options.OnError != nil {
+		w.options.OnError(err)
+	}
+}
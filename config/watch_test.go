@@ -0,0 +1,147 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// writeServerConfig записывает конфигурацию сервера текущей версии с портом port
+func writeServerConfig(t *testing.T, path string, port int) {
+	t.Helper()
+	data := fmt.Sprintf("configVersion: %d\nport: %d\n", CurrentVersion, port)
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServerConfigWatcherKeepsConfigOnInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.yaml")
+	writeServerConfig(t, path, 9090)
+
+	errs := make(chan error, 4)
+	watcher, err := NewServerConfigWatcher(path, WatchOptions{
+		ForcePolling: true,
+		PollInterval: time.Hour,
+		OnError:      func(err error) { errs <- err },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	updates := make(chan *ServerConfig, 4)
+	watcher.Subscribe(func(cfg *ServerConfig) { updates <- cfg })
+
+	// Недопустимый порт: действует прежняя конфигурация
+	writeServerConfig(t, path, 0)
+	watcher.Reload()
+	select {
+	case err := <-errs:
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("OnError(%v), want *ValidationError", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnError not called for invalid config")
+	}
+	select {
+	case cfg := <-updates:
+		t.Errorf("subscriber received invalid config with port %d", cfg.Port)
+	default:
+	}
+	if port := watcher.Current().Port; port != 9090 {
+		t.Errorf("Current().Port = %d, want 9090", port)
+	}
+
+	// Исправленный файл снова публикуется
+	writeServerConfig(t, path, 9091)
+	watcher.Reload()
+	select {
+	case cfg := <-updates:
+		if cfg.Port != 9091 {
+			t.Errorf("update port = %d, want 9091", cfg.Port)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no update after fixing config")
+	}
+	if port := watcher.Current().Port; port != 9091 {
+		t.Errorf("Current().Port = %d, want 9091", port)
+	}
+}
+
+func TestServerConfigWatcherMigratesInMemory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.json")
+	original := []byte(`{"port": 9090, "allowedFormats": ["tgz", "zip"]}`)
+	if err := os.WriteFile(path, original, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	watcher, err := NewServerConfigWatcher(path, WatchOptions{ForcePolling: true, PollInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	if got := watcher.Current().AllowedFormats; !reflect.DeepEqual(got, []string{"tar.gz", "zip"}) {
+		t.Errorf("allowedFormats = %v", got)
+	}
+
+	// Запись на диск вызвала бы повторное уведомление наблюдателя
+	data, _ := os.ReadFile(path)
+	if !bytes.Equal(data, original) {
+		t.Error("watcher rewrote the file")
+	}
+	if _, err := os.Stat(path + ".v1.bak"); !os.IsNotExist(err) {
+		t.Error("watcher created a backup")
+	}
+}
+
+func TestWatcherLoadsHashedContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "value.txt")
+	if err := os.WriteFile(path, []byte("first"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	watcher, err := NewWatcher(path, func(path string, data []byte) (*string, error) {
+		value := string(data)
+		return &value, nil
+	}, WatchOptions{ForcePolling: true, PollInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	if got := *watcher.Current(); got != "first" {
+		t.Errorf("Current() = %q, want %q", got, "first")
+	}
+
+	updates := make(chan string, 4)
+	watcher.Subscribe(func(value *string) { updates <- *value })
+
+	if err := os.WriteFile(path, []byte("second"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	watcher.Reload()
+	select {
+	case got := <-updates:
+		if got != "second" {
+			t.Errorf("update = %q, want %q", got, "second")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no update after reload")
+	}
+
+	// Неизменившееся содержимое повторно не публикуется
+	watcher.Reload()
+	select {
+	case got := <-updates:
+		t.Errorf("unexpected update %q", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,427 @@
+// Package encryption реализует аутентифицированное шифрование архивов пакетов:
+// ключ файла передается получателям через X25519, содержимое шифруется
+// потоком блоков ChaCha20-Poly1305 и может оборачивать архив любого формата.
+package encryption
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdh"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Magic сигнатура зашифрованного пакета
+var Magic = []byte("CRIAGE-ENC\x00\x01")
+
+const (
+	cipherName      = "chacha20poly1305"
+	stanzaType      = "x25519"
+	chunkSize       = 64 * 1024
+	fileKeySize     = 32
+	saltSize        = 16
+	maxHeaderLength = 1024 * 1024
+
+	wrapInfo    = "criage-x25519-wrap"
+	headerInfo  = "criage-header"
+	payloadInfo = "criage-payload"
+)
+
+var (
+	// ErrNotEncrypted данные не являются зашифрованным пакетом
+	ErrNotEncrypted = errors.New("data is not an encrypted package")
+	// ErrNoIdentity ни один из закрытых ключей не подходит к пакету
+	ErrNoIdentity = errors.New("no matching identity for encrypted package")
+	// ErrNoRecipients не задано ни одного получателя
+	ErrNoRecipients = errors.New("no recipients specified")
+	// ErrCorrupted данные повреждены или изменены
+	ErrCorrupted = errors.New("encrypted package is corrupted")
+)
+
+// header заголовок зашифрованного пакета
+type header struct {
+	Cipher     string   `json:"cipher"`
+	ChunkSize  int      `json:"chunkSize"`
+	Salt       string   `json:"salt"`
+	Recipients []stanza `json:"recipients"`
+}
+
+// stanza ключ файла, зашифрованный для одного получателя
+type stanza struct {
+	Type       string `json:"type"`
+	KeyID      string `json:"keyId"`
+	Ephemeral  string `json:"ephemeral"`
+	WrappedKey string `json:"wrappedKey"`
+}
+
+// IsEncrypted проверяет сигнатуру зашифрованного пакета
+func IsEncrypted(prefix []byte) bool {
+	return bytes.HasPrefix(prefix, Magic)
+}
+
+// IsEncryptedFile проверяет, является ли файл зашифрованным пакетом
+func IsEncryptedFile(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	prefix := make([]byte, len(Magic))
+	if _, err := io.ReadFull(file, prefix); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return false, nil
+		}
+		return false, err
+	}
+	return IsEncrypted(prefix), nil
+}
+
+// Encrypt шифрует src для получателей и записывает результат в dst
+func Encrypt(dst io.Writer, src io.Reader, recipients []*Recipient) error {
+	if len(recipients) == 0 {
+		return ErrNoRecipients
+	}
+
+	fileKey := make([]byte, fileKeySize)
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(fileKey); err != nil {
+		return err
+	}
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	hdr := header{
+		Cipher:    cipherName,
+		ChunkSize: chunkSize,
+		Salt:      base64.StdEncoding.EncodeToString(salt),
+	}
+	for _, recipient := range recipients {
+		s, err := wrapKey(fileKey, recipient)
+		if err != nil {
+			return err
+		}
+		hdr.Recipients = append(hdr.Recipients, s)
+	}
+
+	headerBytes, err := encodeHeader(&hdr)
+	if err != nil {
+		return err
+	}
+	mac := headerMAC(fileKey, salt, headerBytes)
+
+	if _, err := dst.Write(headerBytes); err != nil {
+		return err
+	}
+	if _, err := dst.Write(mac); err != nil {
+		return err
+	}
+
+	aead, err := chacha20poly1305.New(deriveKey(fileKey, salt, payloadInfo))
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReaderSize(src, chunkSize+1)
+	buffer := make([]byte, chunkSize)
+	var counter uint64
+
+	for {
+		n, err := io.ReadFull(reader, buffer)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+
+		last := n < chunkSize
+		if !last {
+			// Полный блок последний, если за ним нет данных
+			if _, peekErr := reader.Peek(1); peekErr == io.EOF {
+				last = true
+			} else if peekErr != nil {
+				return peekErr
+			}
+		}
+
+		sealed := aead.Seal(nil, chunkNonce(counter, last), buffer[:n], nil)
+		if _, err := dst.Write(sealed); err != nil {
+			return err
+		}
+
+		if last {
+			return nil
+		}
+		counter++
+	}
+}
+
+// Decrypt расшифровывает src одним из закрытых ключей и записывает результат в dst.
+// При ошибке часть данных уже может быть записана в dst и должна быть отброшена.
+func Decrypt(dst io.Writer, src io.Reader, identities []*Identity) error {
+	reader := bufio.NewReaderSize(src, chunkSize+chacha20poly1305.Overhead+1)
+
+	hdr, headerBytes, err := readHeader(reader)
+	if err != nil {
+		return err
+	}
+	if hdr.Cipher != cipherName {
+		return fmt.Errorf("unsupported cipher: %s", hdr.Cipher)
+	}
+	if hdr.ChunkSize <= 0 || hdr.ChunkSize > 16*chunkSize {
+		return fmt.Errorf("%w: invalid chunk size %d", ErrCorrupted, hdr.ChunkSize)
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(hdr.Salt)
+	if err != nil || len(salt) != saltSize {
+		return fmt.Errorf("%w: invalid salt", ErrCorrupted)
+	}
+
+	fileKey, err := unwrapKey(hdr, identities)
+	if err != nil {
+		return err
+	}
+
+	mac := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(reader, mac); err != nil {
+		return fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+	if !hmac.Equal(mac, headerMAC(fileKey, salt, headerBytes)) {
+		return fmt.Errorf("%w: header authentication failed", ErrCorrupted)
+	}
+
+	aead, err := chacha20poly1305.New(deriveKey(fileKey, salt, payloadInfo))
+	if err != nil {
+		return err
+	}
+
+	buffer := make([]byte, hdr.ChunkSize+aead.Overhead())
+	var counter uint64
+
+	for {
+		n, err := io.ReadFull(reader, buffer)
+		if err == io.EOF {
+			return fmt.Errorf("%w: missing final chunk", ErrCorrupted)
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return err
+		}
+
+		last := n < len(buffer)
+		if !last {
+			if _, peekErr := reader.Peek(1); peekErr == io.EOF {
+				last = true
+			} else if peekErr != nil {
+				return peekErr
+			}
+		}
+
+		plain, err := aead.Open(nil, chunkNonce(counter, last), buffer[:n], nil)
+		if err != nil {
+			return fmt.Errorf("%w: chunk %d authentication failed", ErrCorrupted, counter)
+		}
+		if _, err := dst.Write(plain); err != nil {
+			return err
+		}
+
+		if last {
+			return nil
+		}
+		counter++
+	}
+}
+
+// EncryptFile шифрует файл inputPath в outputPath
+func EncryptFile(inputPath, outputPath string, recipients []*Recipient) error {
+	input, err := os.Open(inputPath)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	output, err := os.OpenFile(outputPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+
+	if err := Encrypt(output, input, recipients); err != nil {
+		return err
+	}
+	return output.Close()
+}
+
+// DecryptFile расшифровывает файл inputPath в outputPath.
+// При ошибке частично записанный outputPath удаляется.
+func DecryptFile(inputPath, outputPath string, identities []*Identity) error {
+	input, err := os.Open(inputPath)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	output, err := os.OpenFile(outputPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	if err := Decrypt(output, input, identities); err != nil {
+		output.Close()
+		os.Remove(outputPath)
+		return err
+	}
+	return output.Close()
+}
+
+// wrapKey шифрует ключ файла для получателя через эфемерный ключ X25519
+func wrapKey(fileKey []byte, recipient *Recipient) (stanza, error) {
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return stanza{}, err
+	}
+
+	shared, err := ephemeral.ECDH(recipient.key)
+	if err != nil {
+		return stanza{}, err
+	}
+
+	aead, err := chacha20poly1305.New(wrapKeyFor(shared, ephemeral.PublicKey(), recipient.key))
+	if err != nil {
+		return stanza{}, err
+	}
+	wrapped := aead.Seal(nil, make([]byte, aead.NonceSize()), fileKey, nil)
+
+	return stanza{
+		Type:       stanzaType,
+		KeyID:      recipient.KeyID(),
+		Ephemeral:  base64.StdEncoding.EncodeToString(ephemeral.PublicKey().Bytes()),
+		WrappedKey: base64.StdEncoding.EncodeToString(wrapped),
+	}, nil
+}
+
+// unwrapKey находит получателя среди закрытых ключей и расшифровывает ключ файла
+func unwrapKey(hdr *header, identities []*Identity) ([]byte, error) {
+	for _, s := range hdr.Recipients {
+		if s.Type != stanzaType {
+			continue
+		}
+
+		ephemeralBytes, err := base64.StdEncoding.DecodeString(s.Ephemeral)
+		if err != nil {
+			continue
+		}
+		ephemeral, err := ecdh.X25519().NewPublicKey(ephemeralBytes)
+		if err != nil {
+			continue
+		}
+		wrapped, err := base64.StdEncoding.DecodeString(s.WrappedKey)
+		if err != nil {
+			continue
+		}
+
+		for _, identity := range identities {
+			recipient := identity.Recipient()
+			if recipient.KeyID() != s.KeyID {
+				continue
+			}
+
+			shared, err := identity.key.ECDH(ephemeral)
+			if err != nil {
+				continue
+			}
+			aead, err := chacha20poly1305.New(wrapKeyFor(shared, ephemeral, recipient.key))
+			if err != nil {
+				return nil, err
+			}
+			fileKey, err := aead.Open(nil, make([]byte, aead.NonceSize()), wrapped, nil)
+			if err == nil && len(fileKey) == fileKeySize {
+				return fileKey, nil
+			}
+		}
+	}
+
+	return nil, ErrNoIdentity
+}
+
+// wrapKeyFor выводит ключ обертки из общего секрета и открытых ключей сторон
+func wrapKeyFor(shared []byte, ephemeral, recipient *ecdh.PublicKey) []byte {
+	salt := append(append([]byte(nil), ephemeral.Bytes()...), recipient.Bytes()...)
+	return deriveKey(shared, salt, wrapInfo)
+}
+
+// deriveKey выводит 32-байтовый ключ через HKDF-SHA256
+func deriveKey(secret, salt []byte, info string) []byte {
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, []byte(info)), key); err != nil {
+		panic(fmt.Sprintf("hkdf: %v", err)) // HKDF не может исчерпаться на 32 байтах
+	}
+	return key
+}
+
+// headerMAC аутентифицирует заголовок ключом, выведенным из ключа файла
+func headerMAC(fileKey, salt, headerBytes []byte) []byte {
+	mac := hmac.New(sha256.New, deriveKey(fileKey, salt, headerInfo))
+	mac.Write(headerBytes)
+	return mac.Sum(nil)
+}
+
+// chunkNonce формирует nonce блока: 11 байт счетчика и флаг последнего блока
+func chunkNonce(counter uint64, last bool) []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	binary.BigEndian.PutUint64(nonce[3:11], counter)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// encodeHeader сериализует сигнатуру и заголовок с префиксом длины
+func encodeHeader(hdr *header) ([]byte, error) {
+	data, err := json.Marshal(hdr)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(Magic)+4+len(data))
+	out = append(out, Magic...)
+	out = binary.BigEndian.AppendUint32(out, uint32(len(data)))
+	return append(out, data...), nil
+}
+
+// readHeader читает сигнатуру и заголовок, возвращая их байты для проверки MAC
+func readHeader(reader io.Reader) (*header, []byte, error) {
+	prefix := make([]byte, len(Magic)+4)
+	if _, err := io.ReadFull(reader, prefix); err != nil {
+		return nil, nil, ErrNotEncrypted
+	}
+	if !IsEncrypted(prefix) {
+		return nil, nil, ErrNotEncrypted
+	}
+
+	length := binary.BigEndian.Uint32(prefix[len(Magic):])
+	if length == 0 || length > maxHeaderLength {
+		return nil, nil, fmt.Errorf("%w: invalid header length %d", ErrCorrupted, length)
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+
+	var hdr header
+	if err := json.Unmarshal(data, &hdr); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrCorrupted, err)
+	}
+
+	return &hdr, append(prefix, data...), nil
+}
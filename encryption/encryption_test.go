@@ -0,0 +1,232 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// sealedChunk размер зашифрованного полного блока
+const sealedChunk = chunkSize + chacha20poly1305.Overhead
+
+// newIdentity создает закрытый ключ для теста
+func newIdentity(t *testing.T) *Identity {
+	t.Helper()
+	identity, err := GenerateIdentity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return identity
+}
+
+// encrypt шифрует данные для получателей
+func encrypt(t *testing.T, plain []byte, recipients ...*Recipient) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	if err := Encrypt(&out, bytes.NewReader(plain), recipients); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+// payloadOffset возвращает смещение зашифрованных блоков после заголовка и MAC
+func payloadOffset(data []byte) int {
+	length := binary.BigEndian.Uint32(data[len(Magic):])
+	return len(Magic) + 4 + int(length) + sha256.Size
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	identity := newIdentity(t)
+
+	sizes := []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 2 * chunkSize, 3*chunkSize + 17}
+	for _, size := range sizes {
+		plain := make([]byte, size)
+		if _, err := rand.Read(plain); err != nil {
+			t.Fatal(err)
+		}
+
+		encrypted := encrypt(t, plain, identity.Recipient())
+		if !IsEncrypted(encrypted) {
+			t.Errorf("size %d: IsEncrypted() = false", size)
+		}
+
+		var out bytes.Buffer
+		if err := Decrypt(&out, bytes.NewReader(encrypted), []*Identity{identity}); err != nil {
+			t.Errorf("size %d: Decrypt() = %v", size, err)
+			continue
+		}
+		if !bytes.Equal(out.Bytes(), plain) {
+			t.Errorf("size %d: decrypted data differs", size)
+		}
+	}
+}
+
+func TestDecryptMultipleRecipients(t *testing.T) {
+	first, second, other := newIdentity(t), newIdentity(t), newIdentity(t)
+	encrypted := encrypt(t, []byte("secret"), first.Recipient(), second.Recipient())
+
+	for _, identity := range []*Identity{first, second} {
+		var out bytes.Buffer
+		if err := Decrypt(&out, bytes.NewReader(encrypted), []*Identity{other, identity}); err != nil {
+			t.Errorf("Decrypt() = %v", err)
+		} else if out.String() != "secret" {
+			t.Errorf("Decrypt() = %q, want %q", out.String(), "secret")
+		}
+	}
+
+	if err := Decrypt(&bytes.Buffer{}, bytes.NewReader(encrypted), []*Identity{other}); !errors.Is(err, ErrNoIdentity) {
+		t.Errorf("Decrypt() with other identity = %v, want %v", err, ErrNoIdentity)
+	}
+}
+
+func TestEncryptRequiresRecipients(t *testing.T) {
+	if err := Encrypt(&bytes.Buffer{}, bytes.NewReader(nil), nil); !errors.Is(err, ErrNoRecipients) {
+		t.Errorf("Encrypt() = %v, want %v", err, ErrNoRecipients)
+	}
+}
+
+func TestDecryptRejectsTampering(t *testing.T) {
+	identity := newIdentity(t)
+	plain := make([]byte, 2*chunkSize+100)
+	encrypted := encrypt(t, plain, identity.Recipient())
+	offset := payloadOffset(encrypted)
+
+	tests := []struct {
+		name   string
+		modify func([]byte) []byte
+	}{
+		{"header", func(data []byte) []byte {
+			// Изменение соли ломает MAC заголовка
+			i := bytes.Index(data, []byte(`"salt":"`)) + len(`"salt":"`)
+			data[i] ^= 'A' ^ 'B'
+			return data
+		}},
+		{"header mac", func(data []byte) []byte {
+			data[offset-1] ^= 1
+			return data
+		}},
+		{"payload", func(data []byte) []byte {
+			data[offset+10] ^= 1
+			return data
+		}},
+		{"truncated final chunk", func(data []byte) []byte {
+			return data[:offset+2*sealedChunk]
+		}},
+		{"truncated inside chunk", func(data []byte) []byte {
+			return data[:len(data)-1]
+		}},
+		{"reordered chunks", func(data []byte) []byte {
+			first := append([]byte(nil), data[offset:offset+sealedChunk]...)
+			copy(data[offset:], data[offset+sealedChunk:offset+2*sealedChunk])
+			copy(data[offset+sealedChunk:], first)
+			return data
+		}},
+		{"header length", func(data []byte) []byte {
+			binary.BigEndian.PutUint32(data[len(Magic):], maxHeaderLength+1)
+			return data
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := tt.modify(append([]byte(nil), encrypted...))
+			err := Decrypt(&bytes.Buffer{}, bytes.NewReader(data), []*Identity{identity})
+			if !errors.Is(err, ErrCorrupted) {
+				t.Errorf("Decrypt() = %v, want %v", err, ErrCorrupted)
+			}
+		})
+	}
+}
+
+func TestDecryptNotEncrypted(t *testing.T) {
+	err := Decrypt(&bytes.Buffer{}, bytes.NewReader([]byte("plain tar archive")), []*Identity{newIdentity(t)})
+	if !errors.Is(err, ErrNotEncrypted) {
+		t.Errorf("Decrypt() = %v, want %v", err, ErrNotEncrypted)
+	}
+}
+
+func TestKeyEncoding(t *testing.T) {
+	identity := newIdentity(t)
+
+	recipient, err := ParseRecipient(identity.Recipient().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recipient.KeyID() != identity.Recipient().KeyID() {
+		t.Errorf("recipient KeyID = %s, want %s", recipient.KeyID(), identity.Recipient().KeyID())
+	}
+
+	parsed, err := ParseIdentity(identity.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Recipient().String() != identity.Recipient().String() {
+		t.Error("parsed identity has another recipient")
+	}
+
+	if _, err := ParseRecipient(identity.String()); err == nil {
+		t.Error("ParseRecipient() accepted an identity")
+	}
+	if _, err := ParseIdentity("x25519-secret:AAAA"); err == nil {
+		t.Error("ParseIdentity() accepted a short key")
+	}
+}
+
+func TestSaveLoadIdentities(t *testing.T) {
+	identity := newIdentity(t)
+	path := filepath.Join(t.TempDir(), "identity.txt")
+	if err := SaveIdentity(path, identity); err != nil {
+		t.Fatal(err)
+	}
+
+	identities, err := LoadIdentities(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(identities) != 1 || identities[0].String() != identity.String() {
+		t.Errorf("LoadIdentities() = %v, want the saved identity", identities)
+	}
+}
+
+func TestEncryptFile(t *testing.T) {
+	identity := newIdentity(t)
+	dir := t.TempDir()
+	input := filepath.Join(dir, "package.tar.zst")
+	encrypted := filepath.Join(dir, "package.criage")
+	output := filepath.Join(dir, "package.out")
+
+	plain := []byte("archive contents")
+	if err := os.WriteFile(input, plain, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := EncryptFile(input, encrypted, []*Recipient{identity.Recipient()}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := IsEncryptedFile(encrypted); err != nil || !ok {
+		t.Errorf("IsEncryptedFile() = %v, %v, want true", ok, err)
+	}
+	if ok, err := IsEncryptedFile(input); err != nil || ok {
+		t.Errorf("IsEncryptedFile() of plain file = %v, %v, want false", ok, err)
+	}
+	if err := DecryptFile(encrypted, output, []*Identity{identity}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(output); !bytes.Equal(data, plain) {
+		t.Errorf("decrypted file = %q, want %q", data, plain)
+	}
+
+	// Частично расшифрованный файл удаляется
+	other := newIdentity(t)
+	if err := DecryptFile(encrypted, output, []*Identity{other}); !errors.Is(err, ErrNoIdentity) {
+		t.Errorf("DecryptFile() = %v, want %v", err, ErrNoIdentity)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Error("output kept after failed decryption")
+	}
+}
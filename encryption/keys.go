@@ -0,0 +1,152 @@
+package encryption
+
+import (
+	"bufio"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Префиксы текстового представления ключей
+const (
+	recipientPrefix = "x25519:"
+	identityPrefix  = "x25519-secret:"
+)
+
+// Recipient открытый ключ получателя зашифрованного пакета
+type Recipient struct {
+	key *ecdh.PublicKey
+}
+
+// Identity закрытый ключ для расшифровки пакетов
+type Identity struct {
+	key *ecdh.PrivateKey
+}
+
+// GenerateIdentity создает новый закрытый ключ X25519
+func GenerateIdentity() (*Identity, error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate identity: %w", err)
+	}
+	return &Identity{key: key}, nil
+}
+
+// Recipient возвращает открытый ключ, соответствующий закрытому
+func (i *Identity) Recipient() *Recipient {
+	return &Recipient{key: i.key.PublicKey()}
+}
+
+// String кодирует закрытый ключ в строку вида "x25519-secret:<base64>"
+func (i *Identity) String() string {
+	return identityPrefix + base64.StdEncoding.EncodeToString(i.key.Bytes())
+}
+
+// String кодирует открытый ключ в строку вида "x25519:<base64>"
+func (r *Recipient) String() string {
+	return recipientPrefix + base64.StdEncoding.EncodeToString(r.key.Bytes())
+}
+
+// KeyID возвращает короткий идентификатор получателя
+func (r *Recipient) KeyID() string {
+	sum := sha256.Sum256(r.key.Bytes())
+	return hex.EncodeToString(sum[:8])
+}
+
+// ParseRecipient разбирает открытый ключ в формате "x25519:<base64>"
+func ParseRecipient(s string) (*Recipient, error) {
+	data, err := decodeKey(s, recipientPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ecdh.X25519().NewPublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient: %w", err)
+	}
+	return &Recipient{key: key}, nil
+}
+
+// ParseRecipients разбирает список открытых ключей
+func ParseRecipients(values []string) ([]*Recipient, error) {
+	recipients := make([]*Recipient, 0, len(values))
+	for _, value := range values {
+		recipient, err := ParseRecipient(value)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients, nil
+}
+
+// ParseIdentity разбирает закрытый ключ в формате "x25519-secret:<base64>"
+func ParseIdentity(s string) (*Identity, error) {
+	data, err := decodeKey(s, identityPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ecdh.X25519().NewPrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid identity: %w", err)
+	}
+	return &Identity{key: key}, nil
+}
+
+// SaveIdentity сохраняет закрытый ключ в файл с правами 0600
+func SaveIdentity(path string, identity *Identity) error {
+	content := fmt.Sprintf("# recipient: %s\n%s\n", identity.Recipient(), identity)
+	return os.WriteFile(path, []byte(content), 0600)
+}
+
+// LoadIdentities загружает закрытые ключи из файла (по одному на строку, # - комментарий)
+func LoadIdentities(path string) ([]*Identity, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var identities []*Identity
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		identity, err := ParseIdentity(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		identities = append(identities, identity)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("no identities found in %s", path)
+	}
+	return identities, nil
+}
+
+// decodeKey декодирует base64 ключ с заданным префиксом
+func decodeKey(s, prefix string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, prefix) {
+		return nil, fmt.Errorf("unsupported key format, expected %q prefix", prefix)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(s, prefix))
+	if err != nil {
+		return nil, fmt.Errorf("invalid key encoding: %w", err)
+	}
+	return data, nil
+}
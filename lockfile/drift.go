@@ -0,0 +1,130 @@
+package lockfile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/criage-oss/criage-common/semver"
+	"github.com/criage-oss/criage-common/types"
+)
+
+// DependencyChange изменение зависимости корневого манифеста
+type DependencyChange struct {
+	Name string
+	// Locked ограничение на момент фиксации (пусто для добавленных)
+	Locked string
+	// Current текущее ограничение манифеста (пусто для удаленных)
+	Current string
+	// LockedVersion зафиксированная версия пакета, если есть
+	LockedVersion string
+	// Satisfied зафиксированная версия удовлетворяет текущему ограничению
+	Satisfied bool
+	Dev       bool
+}
+
+// String описывает изменение в одну строку
+func (c DependencyChange) String() string {
+	name := c.Name
+	if c.Dev {
+		name += " (dev)"
+	}
+	switch {
+	case c.Locked == "":
+		return fmt.Sprintf("added %s %s", name, c.Current)
+	case c.Current == "":
+		return fmt.Sprintf("removed %s %s", name, c.Locked)
+	case c.Satisfied:
+		return fmt.Sprintf("changed %s %s -> %s (locked %s still satisfies)", name, c.Locked, c.Current, c.LockedVersion)
+	default:
+		return fmt.Sprintf("changed %s %s -> %s (locked %s no longer satisfies)", name, c.Locked, c.Current, c.LockedVersion)
+	}
+}
+
+// DriftReport расхождения между файлом блокировки и манифестом
+type DriftReport struct {
+	Added   []DependencyChange
+	Removed []DependencyChange
+	Changed []DependencyChange
+}
+
+// HasDrift проверяет, есть ли расхождения
+func (r *DriftReport) HasDrift() bool {
+	return len(r.Added) > 0 || len(r.Removed) > 0 || len(r.Changed) > 0
+}
+
+// NeedsResolve проверяет, требуется ли повторное разрешение зависимостей:
+// изменения, которым зафиксированные версии по-прежнему удовлетворяют, его не требуют
+func (r *DriftReport) NeedsResolve() bool {
+	if len(r.Added) > 0 || len(r.Removed) > 0 {
+		return true
+	}
+	for _, change := range r.Changed {
+		if !change.Satisfied {
+			return true
+		}
+	}
+	return false
+}
+
+// String перечисляет расхождения построчно
+func (r *DriftReport) String() string {
+	var lines []string
+	for _, group := range [][]DependencyChange{r.Added, r.Removed, r.Changed} {
+		for _, change := range group {
+			lines = append(lines, change.String())
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Drift сравнивает зафиксированные ограничения с текущими зависимостями манифеста.
+// Зависимости разработки сравниваются, только если они были зафиксированы.
+func Drift(lock *types.Lockfile, manifest *types.PackageManifest) *DriftReport {
+	report := &DriftReport{}
+	locked := make(map[string]types.LockedPackage, len(lock.Packages))
+	for _, pkg := range lock.Packages {
+		locked[pkg.Name] = pkg
+	}
+
+	compareDependencies(report, lock, locked, lock.Dependencies, manifest.Dependencies, false)
+	if lock.IncludeDev {
+		compareDependencies(report, lock, locked, lock.DevDependencies, manifest.DevDeps, true)
+	}
+	return report
+}
+
+// compareDependencies добавляет в отчет расхождения одной группы зависимостей.
+// Версия ищется так же, как в Validate, с учетом виртуальных пакетов.
+func compareDependencies(report *DriftReport, lock *types.Lockfile, packages map[string]types.LockedPackage, locked, current map[string]string, dev bool) {
+	for _, name := range sortedKeys(current) {
+		change := DependencyChange{Name: name, Locked: locked[name], Current: current[name], Dev: dev}
+		if version, ok := lockedVersion(lock, packages, name); ok {
+			change.LockedVersion = version
+			change.Satisfied = satisfies(version, change.Current)
+		}
+
+		switch {
+		case change.Locked == "":
+			report.Added = append(report.Added, change)
+		case change.Locked != change.Current:
+			report.Changed = append(report.Changed, change)
+		}
+	}
+
+	for _, name := range sortedKeys(locked) {
+		if _, ok := current[name]; ok {
+			continue
+		}
+		change := DependencyChange{Name: name, Locked: locked[name], Dev: dev}
+		if version, ok := lockedVersion(lock, packages, name); ok {
+			change.LockedVersion = version
+		}
+		report.Removed = append(report.Removed, change)
+	}
+}
+
+// satisfies проверяет версию по ограничению, некорректное ограничение не выполняется
+func satisfies(version, constraint string) bool {
+	ok, err := semver.Satisfies(version, constraint)
+	return err == nil && ok
+}
@@ -0,0 +1,272 @@
+// Package lockfile читает и записывает файл блокировки criage.lock,
+// проверяет его согласованность с манифестом и сообщает о расхождениях
+// с текущими зависимостями.
+package lockfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/criage-oss/criage-common/resolver"
+	"github.com/criage-oss/criage-common/semver"
+	"github.com/criage-oss/criage-common/types"
+)
+
+// ErrUnsupportedVersion версия формата файла блокировки не поддерживается
+var ErrUnsupportedVersion = errors.New("unsupported lockfile version")
+
+// ValidationError файл блокировки не согласован с манифестом
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid lockfile:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// FromResolution создает файл блокировки из результата разрешения зависимостей
+func FromResolution(resolution *resolver.Resolution) *types.Lockfile {
+	lock := &types.Lockfile{LockfileVersion: types.LockfileVersion}
+
+	if root := resolution.Root; root != nil {
+		lock.Name = root.Name
+		lock.Version = root.Version
+		lock.Dependencies = copyMap(root.Dependencies)
+		if resolution.IncludeDev {
+			lock.IncludeDev = true
+			lock.DevDependencies = copyMap(root.DevDeps)
+		}
+	}
+
+	for _, pkg := range resolution.Packages {
+		locked := types.LockedPackage{
+			Name:       pkg.Name,
+			Version:    pkg.Version,
+			Repository: pkg.Repository,
+		}
+		if pkg.Entry != nil {
+			locked.Size = pkg.Entry.Size
+			locked.Checksum = pkg.Entry.Checksum
+			locked.Dependencies = copyMap(pkg.Entry.Dependencies)
+			locked.Provides = append([]string(nil), pkg.Entry.Provides...)
+		}
+		if pkg.File != nil {
+			locked.OS = pkg.File.OS
+			locked.Arch = pkg.File.Arch
+			locked.Format = pkg.File.Format
+			locked.Filename = pkg.File.Filename
+			locked.Size = pkg.File.Size
+			locked.Checksum = pkg.File.Checksum
+		}
+		lock.Packages = append(lock.Packages, locked)
+	}
+
+	sortPackages(lock)
+	return lock
+}
+
+// Marshal сериализует файл блокировки детерминированно:
+// пакеты по имени, ключи по алфавиту, перевод строки в конце
+func Marshal(lock *types.Lockfile) ([]byte, error) {
+	sorted := *lock
+	sorted.Packages = append([]types.LockedPackage(nil), lock.Packages...)
+	sortPackages(&sorted)
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(&sorted); err != nil {
+		return nil, fmt.Errorf("failed to encode lockfile: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Save записывает файл блокировки атомарно
+func Save(path string, lock *types.Lockfile) error {
+	data, err := Marshal(lock)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	temp, err := os.CreateTemp(dir, "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	if err := temp.Chmod(0644); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
+
+// Load читает файл блокировки
+func Load(path string) (*types.Lockfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var lock types.Lockfile
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse lockfile %s: %w", path, err)
+	}
+	if lock.LockfileVersion != types.LockfileVersion {
+		return nil, fmt.Errorf("%w: %d (expected %d)", ErrUnsupportedVersion, lock.LockfileVersion, types.LockfileVersion)
+	}
+	return &lock, nil
+}
+
+// LoadAndValidate читает файл блокировки и проверяет его по манифесту
+func LoadAndValidate(path string, manifest *types.PackageManifest) (*types.Lockfile, error) {
+	lock, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := Validate(lock, manifest); err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// Validate проверяет, что файл блокировки полон и удовлетворяет зависимостям манифеста.
+// Зависимости разработки проверяются, только если они были разрешены (IncludeDev).
+func Validate(lock *types.Lockfile, manifest *types.PackageManifest) error {
+	var problems []string
+
+	if lock.LockfileVersion != types.LockfileVersion {
+		problems = append(problems, fmt.Sprintf("unsupported lockfile version %d", lock.LockfileVersion))
+	}
+	if manifest != nil && lock.Name != "" && manifest.Name != "" && lock.Name != manifest.Name {
+		problems = append(problems, fmt.Sprintf("lockfile belongs to %s, manifest is %s", lock.Name, manifest.Name))
+	}
+
+	locked := make(map[string]types.LockedPackage, len(lock.Packages))
+	for _, pkg := range lock.Packages {
+		if _, ok := locked[pkg.Name]; ok {
+			problems = append(problems, fmt.Sprintf("package %s is locked more than once", pkg.Name))
+			continue
+		}
+		if _, err := semver.ParseTolerant(pkg.Version); err != nil {
+			problems = append(problems, fmt.Sprintf("package %s: %v", pkg.Name, err))
+		}
+		if pkg.Checksum == "" {
+			problems = append(problems, fmt.Sprintf("package %s@%s has no checksum", pkg.Name, pkg.Version))
+		}
+		locked[pkg.Name] = pkg
+	}
+
+	check := func(source, name, constraint string) {
+		version, ok := lockedVersion(lock, locked, name)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s requires %s %s, which is not locked", source, name, constraint))
+			return
+		}
+		c, err := semver.ParseConstraint(constraint)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: dependency %s: %v", source, name, err))
+			return
+		}
+		if !c.CheckString(version) {
+			problems = append(problems, fmt.Sprintf("%s requires %s %s, but %s is locked", source, name, constraint, version))
+		}
+	}
+
+	if manifest != nil {
+		for _, name := range sortedKeys(manifest.Dependencies) {
+			check("root", name, manifest.Dependencies[name])
+		}
+		if lock.IncludeDev {
+			for _, name := range sortedKeys(manifest.DevDeps) {
+				check("root", name, manifest.DevDeps[name])
+			}
+		}
+	}
+
+	for _, pkg := range lock.Packages {
+		for _, name := range sortedKeys(pkg.Dependencies) {
+			check(pkg.Name+"@"+pkg.Version, name, pkg.Dependencies[name])
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// lockedVersion возвращает зафиксированную версию пакета или виртуального пакета,
+// предоставляемого одним из зафиксированных пакетов
+func lockedVersion(lock *types.Lockfile, locked map[string]types.LockedPackage, name string) (string, bool) {
+	if pkg, ok := locked[name]; ok {
+		return pkg.Version, true
+	}
+	for _, pkg := range lock.Packages {
+		for _, value := range pkg.Provides {
+			provided, version, err := types.ParseProvides(value)
+			if err != nil || provided != name {
+				continue
+			}
+			if version == "" {
+				version = pkg.Version
+			}
+			return version, true
+		}
+	}
+	return "", false
+}
+
+// Find возвращает зафиксированный пакет по имени
+func Find(lock *types.Lockfile, name string) (*types.LockedPackage, bool) {
+	for i := range lock.Packages {
+		if lock.Packages[i].Name == name {
+			return &lock.Packages[i], true
+		}
+	}
+	return nil, false
+}
+
+// sortPackages упорядочивает пакеты по имени
+func sortPackages(lock *types.Lockfile) {
+	sort.SliceStable(lock.Packages, func(i, j int) bool {
+		return lock.Packages[i].Name < lock.Packages[j].Name
+	})
+}
+
+// copyMap копирует карту зависимостей (nil для пустой)
+func copyMap(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	c := make(map[string]string, len(m))
+	for key, value := range m {
+		c[key] = value
+	}
+	return c
+}
+
+// sortedKeys возвращает ключи карты по алфавиту
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,119 @@
+package lockfile
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/criage-oss/criage-common/resolver"
+	"github.com/criage-oss/criage-common/types"
+)
+
+// testSources индекс с пакетом lib и инструментом разработки tool
+func testSources() []resolver.Source {
+	index := &types.RepositoryIndex{Packages: map[string]*types.PackageEntry{
+		"lib": {Name: "lib", Versions: []types.VersionEntry{
+			{Version: "1.2.0", Checksum: "sha256:lib"},
+		}},
+		"tool": {Name: "tool", Versions: []types.VersionEntry{
+			{Version: "1.0.0", Checksum: "sha256:tool"},
+		}},
+	}}
+	return []resolver.Source{{
+		Repository: types.Repository{Name: "test", URL: "https://example.com", Enabled: true},
+		Index:      index,
+	}}
+}
+
+func testManifest() *types.PackageManifest {
+	return &types.PackageManifest{
+		Name:         "app",
+		Version:      "1.0.0",
+		Dependencies: map[string]string{"lib": "^1.0.0"},
+		DevDeps:      map[string]string{"tool": "^1"},
+	}
+}
+
+func resolve(t *testing.T, includeDev bool) *types.Lockfile {
+	t.Helper()
+	options := resolver.DefaultOptions()
+	options.IncludeDev = includeDev
+	resolution, err := resolver.NewResolver(testSources(), options).Resolve(testManifest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return FromResolution(resolution)
+}
+
+func TestFromResolutionWithoutDev(t *testing.T) {
+	lock := resolve(t, false)
+	if lock.IncludeDev || lock.DevDependencies != nil {
+		t.Errorf("dev dependencies locked without IncludeDev: %v", lock.DevDependencies)
+	}
+	if _, ok := Find(lock, "tool"); ok {
+		t.Error("dev dependency tool was resolved")
+	}
+	if err := Validate(lock, testManifest()); err != nil {
+		t.Errorf("lockfile built from resolution is invalid: %v", err)
+	}
+	if drift := Drift(lock, testManifest()); drift.HasDrift() {
+		t.Errorf("unexpected drift:\n%s", drift)
+	}
+}
+
+func TestFromResolutionWithDev(t *testing.T) {
+	lock := resolve(t, true)
+	if !lock.IncludeDev || lock.DevDependencies["tool"] != "^1" {
+		t.Errorf("includeDev=%v devDependencies=%v", lock.IncludeDev, lock.DevDependencies)
+	}
+	if err := Validate(lock, testManifest()); err != nil {
+		t.Errorf("lockfile built from resolution is invalid: %v", err)
+	}
+
+	// Зависимость разработки без зафиксированного пакета отклоняется
+	lock.Packages = lock.Packages[:1]
+	if err := Validate(lock, testManifest()); err == nil {
+		t.Error("expected an error for an unlocked dev dependency")
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	for _, includeDev := range []bool{false, true} {
+		path := filepath.Join(t.TempDir(), types.LockfileName)
+		if err := Save(path, resolve(t, includeDev)); err != nil {
+			t.Fatal(err)
+		}
+		lock, err := LoadAndValidate(path, testManifest())
+		if err != nil {
+			t.Fatalf("includeDev=%v: %v", includeDev, err)
+		}
+		if lock.IncludeDev != includeDev {
+			t.Errorf("includeDev = %v after reload, want %v", lock.IncludeDev, includeDev)
+		}
+	}
+}
+
+func TestDriftVirtualPackage(t *testing.T) {
+	lock := &types.Lockfile{
+		LockfileVersion: types.LockfileVersion,
+		Dependencies:    map[string]string{"logger": "^1.0.0"},
+		Packages: []types.LockedPackage{
+			{Name: "zlog", Version: "3.0.0", Checksum: "sha256:zlog", Provides: []string{"logger@1.4.0"}},
+		},
+	}
+	manifest := &types.PackageManifest{Name: "app", Version: "1.0.0", Dependencies: map[string]string{"logger": "^1.2.0"}}
+
+	if err := Validate(lock, manifest); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	drift := Drift(lock, manifest)
+	if len(drift.Changed) != 1 {
+		t.Fatalf("changed = %v, want one change", drift.Changed)
+	}
+	change := drift.Changed[0]
+	if change.LockedVersion != "1.4.0" || !change.Satisfied {
+		t.Errorf("change = %+v, want locked 1.4.0 satisfied", change)
+	}
+	if drift.NeedsResolve() {
+		t.Error("NeedsResolve() = true for a satisfied virtual package")
+	}
+}
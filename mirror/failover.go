@@ -0,0 +1,76 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+// ErrChecksumMismatch загруженные данные не совпали с ожидаемой контрольной суммой.
+// Функция запроса оборачивает эту ошибку, чтобы зеркало было отложено надолго.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// Attempt неудачная попытка запроса к зеркалу
+type Attempt struct {
+	Mirror types.Mirror
+	Err    error
+}
+
+// FailoverError запрос не удался ни на одном зеркале репозитория
+type FailoverError struct {
+	Repository string
+	Attempts   []Attempt
+}
+
+func (e *FailoverError) Error() string {
+	lines := make([]string, len(e.Attempts))
+	for i, attempt := range e.Attempts {
+		lines[i] = attempt.Mirror.URL + ": " + attempt.Err.Error()
+	}
+	return "all mirrors of repository " + e.Repository + " failed:\n  - " + strings.Join(lines, "\n  - ")
+}
+
+// Unwrap возвращает ошибки всех попыток для errors.Is и errors.As
+func (e *FailoverError) Unwrap() []error {
+	errs := make([]error, len(e.Attempts))
+	for i, attempt := range e.Attempts {
+		errs[i] = attempt.Err
+	}
+	return errs
+}
+
+// Do выполняет fetch на зеркалах в порядке Order, пока одно из них не ответит
+// без ошибки, и учитывает результат каждой попытки. Ошибка, оборачивающая
+// ErrChecksumMismatch, откладывает зеркало надолго. Отмена ctx прерывает
+// перебор и не ухудшает оценку зеркала.
+func (s *Selector) Do(ctx context.Context, fetch func(ctx context.Context, mirror types.Mirror) error) error {
+	mirrors := s.Order()
+	if len(mirrors) == 0 {
+		return ErrNoMirrors
+	}
+
+	failover := &FailoverError{Repository: s.repository}
+	for _, mirror := range mirrors {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		start := s.options.Now()
+		err := fetch(ctx, mirror)
+		switch {
+		case err == nil:
+			s.ReportSuccess(mirror.URL, s.options.Now().Sub(start))
+			return nil
+		case ctx.Err() != nil:
+			return err
+		case errors.Is(err, ErrChecksumMismatch):
+			s.ReportChecksumMismatch(mirror.URL, err)
+		default:
+			s.ReportFailure(mirror.URL, err)
+		}
+		failover.Attempts = append(failover.Attempts, Attempt{Mirror: mirror, Err: err})
+	}
+	return failover
+}
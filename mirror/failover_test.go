@@ -0,0 +1,144 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+// failoverRepo репозиторий с тремя зеркалами для тестов
+var failoverRepo = types.Repository{
+	Name:    "main",
+	URL:     "https://a",
+	Mirrors: []types.Mirror{{URL: "https://b"}, {URL: "https://c"}},
+}
+
+func TestDoFailover(t *testing.T) {
+	selector, c := newTestSelector(t, failoverRepo, Options{Cooldown: time.Minute})
+
+	var tried []string
+	err := selector.Do(context.Background(), func(ctx context.Context, mirror types.Mirror) error {
+		tried = append(tried, mirror.URL)
+		c.Advance(10 * time.Millisecond)
+		if mirror.URL == "https://c" {
+			return nil
+		}
+		return errFetch
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tried, []string{"https://a", "https://b", "https://c"}) {
+		t.Errorf("tried %v", tried)
+	}
+
+	health := selector.Health()
+	if health[0].Failures != 1 || health[1].Failures != 1 {
+		t.Errorf("failed mirrors not reported: %+v", health[:2])
+	}
+	if health[2].Latency != 10*time.Millisecond {
+		t.Errorf("latency = %v, want 10ms measured with Options.Now", health[2].Latency)
+	}
+
+	// Следующий запрос начинается с зеркала, ответившего успешно
+	if mirror, _ := selector.Select(); mirror.URL != "https://c" {
+		t.Errorf("Select() = %s, want https://c", mirror.URL)
+	}
+}
+
+func TestDoAllFailed(t *testing.T) {
+	selector, _ := newTestSelector(t, failoverRepo, Options{})
+
+	err := selector.Do(context.Background(), func(ctx context.Context, mirror types.Mirror) error {
+		if mirror.URL == "https://b" {
+			return fmt.Errorf("package.tar.zst: %w", ErrChecksumMismatch)
+		}
+		return errFetch
+	})
+
+	var failover *FailoverError
+	if !errors.As(err, &failover) {
+		t.Fatalf("error = %v, want *FailoverError", err)
+	}
+	if failover.Repository != "main" || len(failover.Attempts) != 3 {
+		t.Errorf("failover = %+v", failover)
+	}
+	if !errors.Is(err, ErrChecksumMismatch) || !errors.Is(err, errFetch) {
+		t.Errorf("errors.Is does not see attempt errors: %v", err)
+	}
+	for _, url := range []string{"https://a", "https://b", "https://c"} {
+		if !strings.Contains(err.Error(), url+": ") {
+			t.Errorf("error does not mention %s: %v", url, err)
+		}
+	}
+
+	// Несовпадение контрольной суммы учитывается отдельно
+	health := selector.Health()
+	if health[1].ChecksumMismatches != 1 || health[0].ChecksumMismatches != 0 {
+		t.Errorf("checksum mismatches = %d, %d", health[0].ChecksumMismatches, health[1].ChecksumMismatches)
+	}
+}
+
+func TestDoSkipsCoolingMirror(t *testing.T) {
+	selector, c := newTestSelector(t, failoverRepo, Options{Cooldown: time.Minute})
+	selector.ReportFailure("https://a", errFetch)
+
+	var first string
+	fetch := func(ctx context.Context, mirror types.Mirror) error {
+		if first == "" {
+			first = mirror.URL
+		}
+		return nil
+	}
+	if err := selector.Do(context.Background(), fetch); err != nil {
+		t.Fatal(err)
+	}
+	if first != "https://b" {
+		t.Errorf("first mirror = %s, want https://b while a is cooling", first)
+	}
+
+	// После паузы зеркало снова доступно, но из-за сниженной оценки идет последним
+	c.Advance(time.Minute)
+	if got := urls(selector.Order()); !reflect.DeepEqual(got, []string{"https://b", "https://c", "https://a"}) {
+		t.Errorf("Order() after cooldown = %v", got)
+	}
+}
+
+func TestDoCanceled(t *testing.T) {
+	selector, _ := newTestSelector(t, failoverRepo, Options{})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	calls := 0
+	err := selector.Do(ctx, func(ctx context.Context, mirror types.Mirror) error {
+		calls++
+		cancel()
+		return ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+	if calls != 1 {
+		t.Errorf("fetch called %d times after cancel", calls)
+	}
+	// Отмена не ухудшает оценку зеркала
+	if health := selector.Health()[0]; health.Failures != 0 || health.Score != 1 {
+		t.Errorf("canceled request reported: %+v", health)
+	}
+}
+
+func TestDoNoMirrors(t *testing.T) {
+	selector, _ := newTestSelector(t, types.Repository{Name: "empty"}, Options{})
+	err := selector.Do(context.Background(), func(ctx context.Context, mirror types.Mirror) error {
+		t.Error("fetch called without mirrors")
+		return nil
+	})
+	if !errors.Is(err, ErrNoMirrors) {
+		t.Errorf("error = %v, want ErrNoMirrors", err)
+	}
+}
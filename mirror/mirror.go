@@ -0,0 +1,297 @@
+// Package mirror выбирает зеркало репозитория с учетом явного приоритета,
+// региона и состояния зеркал и переключается на следующее зеркало при
+// ошибках загрузки или несовпадении контрольной суммы.
+package mirror
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+// Параметры оценки состояния по умолчанию
+const (
+	// DefaultCooldown пауза после первой ошибки, далее она удваивается
+	DefaultCooldown = 30 * time.Second
+	// DefaultMaxCooldown наибольшая пауза; после несовпадения контрольной суммы
+	// зеркало сразу откладывается на это время
+	DefaultMaxCooldown = 10 * time.Minute
+	// DefaultMinScore оценка, ниже которой зеркало уступает исправным
+	DefaultMinScore = 0.5
+	// DefaultRecovery время, за которое потеря оценки после ошибок уменьшается вдвое
+	DefaultRecovery = 10 * time.Minute
+)
+
+// scoreWeight вес последнего результата в скользящей оценке
+const scoreWeight = 0.3
+
+// mismatchPenalty множитель оценки при несовпадении контрольной суммы
+const mismatchPenalty = 0.25
+
+// ErrNoMirrors у репозитория нет ни адреса, ни зеркал
+var ErrNoMirrors = errors.New("repository has no URL or mirrors")
+
+// Options настройки выбора зеркал
+type Options struct {
+	// Region регион клиента: зеркала того же региона предпочтительнее.
+	// "eu" совпадает с "eu-west" и наоборот.
+	Region string
+	// Cooldown и MaxCooldown пауза после ошибок (DefaultCooldown, DefaultMaxCooldown)
+	Cooldown    time.Duration
+	MaxCooldown time.Duration
+	// MinScore оценка, ниже которой зеркало считается неисправным (DefaultMinScore)
+	MinScore float64
+	// Recovery период восстановления оценки без новых запросов (DefaultRecovery)
+	Recovery time.Duration
+	// Now источник времени (time.Now)
+	Now func() time.Time
+}
+
+// Health состояние зеркала
+type Health struct {
+	Mirror types.Mirror
+	// Score скользящая доля успешных запросов от 0 до 1, со временем
+	// восстанавливающаяся до 1
+	Score float64
+	// Latency скользящее среднее время успешного запроса, 0 - не измерялось
+	Latency time.Duration
+	// Failures число ошибок подряд
+	Failures int
+	// ChecksumMismatches число несовпадений контрольной суммы
+	ChecksumMismatches int
+	// RetryAt время, до которого зеркало отложено; нулевое - зеркало доступно
+	RetryAt   time.Time
+	LastError error
+}
+
+// Selector упорядочивает зеркала одного репозитория и учитывает результаты
+// запросов к ним. Безопасен для одновременного использования.
+type Selector struct {
+	repository string
+	options    Options
+
+	mu        sync.Mutex
+	endpoints []*endpoint
+}
+
+// endpoint зеркало и его состояние
+type endpoint struct {
+	Health
+	index int
+	// updated время последнего изменения оценки
+	updated time.Time
+}
+
+// NewSelector создает выбор зеркал для основного адреса и зеркал репозитория
+func NewSelector(repo types.Repository, options Options) *Selector {
+	if options.Cooldown <= 0 {
+		options.Cooldown = DefaultCooldown
+	}
+	if options.MaxCooldown < options.Cooldown {
+		options.MaxCooldown = max(DefaultMaxCooldown, options.Cooldown)
+	}
+	if options.MinScore <= 0 {
+		options.MinScore = DefaultMinScore
+	}
+	if options.Recovery <= 0 {
+		options.Recovery = DefaultRecovery
+	}
+	if options.Now == nil {
+		options.Now = time.Now
+	}
+
+	s := &Selector{repository: repo.Name, options: options}
+	for i, mirror := range repo.Endpoints() {
+		s.endpoints = append(s.endpoints, &endpoint{
+			Health: Health{Mirror: mirror, Score: 1},
+			index:  i,
+		})
+	}
+	return s
+}
+
+// Select возвращает лучшее зеркало. Если все зеркала отложены после ошибок,
+// возвращается то, пауза которого закончится раньше.
+func (s *Selector) Select() (types.Mirror, error) {
+	order := s.Order()
+	if len(order) == 0 {
+		return types.Mirror{}, ErrNoMirrors
+	}
+	return order[0], nil
+}
+
+// Order возвращает все зеркала от лучшего к худшему: сначала исправные,
+// затем с низкой оценкой, затем отложенные. Внутри группы зеркала упорядочены
+// по приоритету, совпадению региона, оценке и задержке; зеркала с неизмеренной
+// задержкой идут раньше измеренных, чтобы их задержка была оценена.
+func (s *Selector) Order() []types.Mirror {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.options.Now()
+	type ranked struct {
+		*endpoint
+		group int
+		score int
+	}
+	sorted := make([]ranked, len(s.endpoints))
+	for i, e := range s.endpoints {
+		score := s.score(e, now)
+		sorted[i] = ranked{endpoint: e, group: s.group(e, score, now), score: scoreBucket(score)}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.group != b.group {
+			return a.group < b.group
+		}
+		if a.group == groupCooling && !a.RetryAt.Equal(b.RetryAt) {
+			return a.RetryAt.Before(b.RetryAt)
+		}
+		if a.Mirror.Priority != b.Mirror.Priority {
+			return a.Mirror.Priority > b.Mirror.Priority
+		}
+		if ra, rb := regionMatch(s.options.Region, a.Mirror.Region), regionMatch(s.options.Region, b.Mirror.Region); ra != rb {
+			return ra > rb
+		}
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		if a.Latency != b.Latency {
+			return a.Latency < b.Latency
+		}
+		return a.index < b.index
+	})
+
+	mirrors := make([]types.Mirror, len(sorted))
+	for i, r := range sorted {
+		mirrors[i] = r.Mirror
+	}
+	return mirrors
+}
+
+// Health возвращает состояние зеркал в порядке их объявления
+func (s *Selector) Health() []Health {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.options.Now()
+	health := make([]Health, len(s.endpoints))
+	for i, e := range s.endpoints {
+		health[i] = e.Health
+		health[i].Score = s.score(e, now)
+	}
+	return health
+}
+
+// ReportSuccess учитывает успешный запрос к зеркалу url и его длительность
+func (s *Selector) ReportSuccess(url string, latency time.Duration) {
+	s.update(url, func(e *endpoint) {
+		e.setScore(s.score(e, s.options.Now())*(1-scoreWeight)+scoreWeight, s.options.Now())
+		if e.Latency == 0 {
+			e.Latency = latency
+		} else {
+			e.Latency = time.Duration(float64(e.Latency)*(1-scoreWeight) + float64(latency)*scoreWeight)
+		}
+		e.Failures = 0
+		e.RetryAt = time.Time{}
+		e.LastError = nil
+	})
+}
+
+// ReportFailure учитывает ошибку запроса: зеркало откладывается на паузу,
+// удваивающуюся с каждой ошибкой подряд
+func (s *Selector) ReportFailure(url string, err error) {
+	s.update(url, func(e *endpoint) {
+		e.setScore(s.score(e, s.options.Now())*(1-scoreWeight), s.options.Now())
+		e.Failures++
+		cooldown := min(s.options.Cooldown<<min(e.Failures-1, 16), s.options.MaxCooldown)
+		e.RetryAt = s.options.Now().Add(cooldown)
+		e.LastError = err
+	})
+}
+
+// ReportChecksumMismatch учитывает несовпадение контрольной суммы: содержимое
+// зеркала устарело или повреждено, поэтому оно откладывается на MaxCooldown
+func (s *Selector) ReportChecksumMismatch(url string, err error) {
+	s.update(url, func(e *endpoint) {
+		e.setScore(s.score(e, s.options.Now())*mismatchPenalty, s.options.Now())
+		e.Failures++
+		e.ChecksumMismatches++
+		e.RetryAt = s.options.Now().Add(s.options.MaxCooldown)
+		e.LastError = err
+	})
+}
+
+// update изменяет состояние зеркала с адресом url; неизвестные адреса игнорируются
+func (s *Selector) update(url string, fn func(*endpoint)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := types.NormalizeMirrorURL(url)
+	for _, e := range s.endpoints {
+		if types.NormalizeMirrorURL(e.Mirror.URL) == key {
+			fn(e)
+			return
+		}
+	}
+}
+
+// Группы зеркал в порядке предпочтения
+const (
+	groupHealthy = iota
+	groupDegraded
+	groupCooling
+)
+
+// setScore сохраняет оценку и время ее изменения
+func (e *endpoint) setScore(score float64, now time.Time) {
+	e.Score = score
+	e.updated = now
+}
+
+// score возвращает оценку зеркала в момент now: потеря оценки уменьшается
+// вдвое за каждый период Recovery без новых запросов
+func (s *Selector) score(e *endpoint, now time.Time) float64 {
+	elapsed := now.Sub(e.updated)
+	if e.updated.IsZero() || elapsed <= 0 {
+		return e.Score
+	}
+	return 1 - (1-e.Score)*math.Exp2(-float64(elapsed)/float64(s.options.Recovery))
+}
+
+// group возвращает группу зеркала с оценкой score в момент now
+func (s *Selector) group(e *endpoint, score float64, now time.Time) int {
+	switch {
+	case now.Before(e.RetryAt):
+		return groupCooling
+	case score < s.options.MinScore:
+		return groupDegraded
+	}
+	return groupHealthy
+}
+
+// regionMatch оценивает совпадение региона зеркала с регионом клиента:
+// 2 - полное, 1 - один регион вложен в другой ("eu" и "eu-west"), 0 - нет
+func regionMatch(preferred, region string) int {
+	preferred, region = strings.ToLower(preferred), strings.ToLower(region)
+	switch {
+	case preferred == "" || region == "":
+		return 0
+	case preferred == region:
+		return 2
+	case strings.HasPrefix(preferred, region+"-") || strings.HasPrefix(region, preferred+"-"):
+		return 1
+	}
+	return 0
+}
+
+// scoreBucket округляет оценку, чтобы близкие оценки не меняли порядок зеркал
+func scoreBucket(score float64) int {
+	return int(math.Round(score * 10))
+}
@@ -0,0 +1,245 @@
+package mirror
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+// clock управляемый источник времени для тестов
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time { return c.now }
+
+func (c *clock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// newTestSelector создает выбор зеркал с управляемым временем
+func newTestSelector(t *testing.T, repo types.Repository, options Options) (*Selector, *clock) {
+	t.Helper()
+	c := &clock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	options.Now = c.Now
+	return NewSelector(repo, options), c
+}
+
+// urls возвращает адреса зеркал по порядку
+func urls(mirrors []types.Mirror) []string {
+	result := make([]string, len(mirrors))
+	for i, mirror := range mirrors {
+		result[i] = mirror.URL
+	}
+	return result
+}
+
+var errFetch = errors.New("connection refused")
+
+func TestOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		region string
+		repo   types.Repository
+		want   []string
+	}{
+		{
+			name: "declaration order",
+			repo: types.Repository{URL: "https://a", Mirrors: []types.Mirror{{URL: "https://b"}, {URL: "https://c"}}},
+			want: []string{"https://a", "https://b", "https://c"},
+		},
+		{
+			name: "priority",
+			repo: types.Repository{URL: "https://a", Mirrors: []types.Mirror{{URL: "https://b", Priority: 1}, {URL: "https://c", Priority: 5}}},
+			want: []string{"https://c", "https://b", "https://a"},
+		},
+		{
+			name:   "region",
+			region: "eu",
+			repo: types.Repository{URL: "https://a", Mirrors: []types.Mirror{
+				{URL: "https://us", Region: "us"},
+				{URL: "https://eu-west", Region: "eu-west"},
+				{URL: "https://eu", Region: "EU"},
+			}},
+			want: []string{"https://eu", "https://eu-west", "https://a", "https://us"},
+		},
+		{
+			name:   "priority before region",
+			region: "eu",
+			repo: types.Repository{URL: "https://a", Mirrors: []types.Mirror{
+				{URL: "https://eu", Region: "eu"},
+				{URL: "https://us", Region: "us", Priority: 1},
+			}},
+			want: []string{"https://us", "https://eu", "https://a"},
+		},
+		{
+			name: "primary URL settings from mirror",
+			repo: types.Repository{URL: "https://a/", Mirrors: []types.Mirror{{URL: "https://b", Priority: 1}, {URL: "https://a", Priority: 2}}},
+			want: []string{"https://a/", "https://b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, _ := newTestSelector(t, tt.repo, Options{Region: tt.region})
+			if got := urls(selector.Order()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Order() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegionMatch(t *testing.T) {
+	tests := []struct {
+		preferred, region string
+		want              int
+	}{
+		{"", "eu", 0},
+		{"eu", "", 0},
+		{"eu", "eu", 2},
+		{"EU", "eu", 2},
+		{"eu", "eu-west", 1},
+		{"eu-west", "eu", 1},
+		{"eu", "europe", 0},
+		{"eu-west", "eu-east", 0},
+	}
+	for _, tt := range tests {
+		if got := regionMatch(tt.preferred, tt.region); got != tt.want {
+			t.Errorf("regionMatch(%q, %q) = %d, want %d", tt.preferred, tt.region, got, tt.want)
+		}
+	}
+}
+
+func TestOrderByHealth(t *testing.T) {
+	repo := types.Repository{URL: "https://a", Mirrors: []types.Mirror{{URL: "https://b"}, {URL: "https://c"}}}
+	selector, c := newTestSelector(t, repo, Options{Cooldown: time.Minute, Recovery: 24 * time.Hour})
+
+	// Отложенное зеркало уступает остальным
+	selector.ReportFailure("https://a/", errFetch)
+	if got := urls(selector.Order()); !reflect.DeepEqual(got, []string{"https://b", "https://c", "https://a"}) {
+		t.Errorf("Order() after failure = %v", got)
+	}
+
+	// Среди исправных зеркал быстрое выбирается первым
+	selector.ReportSuccess("https://b", 200*time.Millisecond)
+	selector.ReportSuccess("https://c", 50*time.Millisecond)
+	if got := urls(selector.Order()); !reflect.DeepEqual(got, []string{"https://c", "https://b", "https://a"}) {
+		t.Errorf("Order() by latency = %v", got)
+	}
+
+	// После паузы зеркало с низкой оценкой идет после исправных, а среди
+	// исправных оценка важнее задержки
+	selector.ReportFailure("https://a", errFetch)
+	selector.ReportFailure("https://a", errFetch)
+	selector.ReportFailure("https://c", errFetch)
+	c.Advance(5 * time.Minute)
+	health := selector.Health()
+	if health[0].Score >= DefaultMinScore {
+		t.Fatalf("score of a = %v, want below %v", health[0].Score, DefaultMinScore)
+	}
+	if got := urls(selector.Order()); !reflect.DeepEqual(got, []string{"https://b", "https://c", "https://a"}) {
+		t.Errorf("Order() with degraded mirror = %v", got)
+	}
+}
+
+func TestCooldown(t *testing.T) {
+	repo := types.Repository{URL: "https://a", Mirrors: []types.Mirror{{URL: "https://b"}}}
+	selector, c := newTestSelector(t, repo, Options{Cooldown: time.Minute, MaxCooldown: 3 * time.Minute})
+	start := c.Now()
+
+	// Пауза удваивается с каждой ошибкой подряд и ограничена MaxCooldown
+	for i, want := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute} {
+		selector.ReportFailure("https://a", errFetch)
+		health := selector.Health()[0]
+		if health.Failures != i+1 || !health.RetryAt.Equal(start.Add(want)) {
+			t.Errorf("failure %d: failures = %d, retryAt = %v, want %v", i+1, health.Failures, health.RetryAt.Sub(start), want)
+		}
+		if !errors.Is(health.LastError, errFetch) {
+			t.Errorf("LastError = %v", health.LastError)
+		}
+	}
+
+	// Пока пауза не закончилась, выбирается другое зеркало
+	if mirror, err := selector.Select(); err != nil || mirror.URL != "https://b" {
+		t.Errorf("Select() = %v, %v, want https://b", mirror.URL, err)
+	}
+
+	// Успех сбрасывает счетчик и паузу
+	c.Advance(3 * time.Minute)
+	selector.ReportSuccess("https://a", time.Millisecond)
+	health := selector.Health()[0]
+	if health.Failures != 0 || !health.RetryAt.IsZero() || health.LastError != nil {
+		t.Errorf("health after success = %+v", health)
+	}
+}
+
+func TestAllCooling(t *testing.T) {
+	repo := types.Repository{URL: "https://a", Mirrors: []types.Mirror{{URL: "https://b"}}}
+	selector, c := newTestSelector(t, repo, Options{Cooldown: time.Minute})
+
+	selector.ReportFailure("https://a", errFetch)
+	c.Advance(30 * time.Second)
+	selector.ReportFailure("https://b", errFetch)
+
+	// Все зеркала отложены: первым идет то, чья пауза закончится раньше
+	if got := urls(selector.Order()); !reflect.DeepEqual(got, []string{"https://a", "https://b"}) {
+		t.Errorf("Order() = %v", got)
+	}
+}
+
+func TestChecksumMismatchPenalty(t *testing.T) {
+	repo := types.Repository{URL: "https://a", Mirrors: []types.Mirror{{URL: "https://b"}}}
+	selector, c := newTestSelector(t, repo, Options{Cooldown: time.Minute, MaxCooldown: 20 * time.Minute})
+	start := c.Now()
+
+	selector.ReportChecksumMismatch("https://a", ErrChecksumMismatch)
+	health := selector.Health()[0]
+	if health.Score != mismatchPenalty {
+		t.Errorf("score = %v, want %v", health.Score, mismatchPenalty)
+	}
+	if health.ChecksumMismatches != 1 || health.Failures != 1 {
+		t.Errorf("checksumMismatches = %d, failures = %d, want 1, 1", health.ChecksumMismatches, health.Failures)
+	}
+	// Зеркало сразу откладывается на MaxCooldown, а не на Cooldown
+	if !health.RetryAt.Equal(start.Add(20 * time.Minute)) {
+		t.Errorf("retryAt = %v after start, want 20m", health.RetryAt.Sub(start))
+	}
+
+	// Несовпадение снижает оценку сильнее обычной ошибки
+	selector.ReportFailure("https://b", errFetch)
+	if mismatch, failure := selector.Health()[0].Score, selector.Health()[1].Score; mismatch >= failure {
+		t.Errorf("mismatch score %v not below failure score %v", mismatch, failure)
+	}
+}
+
+func TestScoreRecovery(t *testing.T) {
+	repo := types.Repository{URL: "https://a"}
+	selector, c := newTestSelector(t, repo, Options{Recovery: 10 * time.Minute})
+
+	selector.ReportChecksumMismatch("https://a", ErrChecksumMismatch)
+	// Потеря оценки уменьшается вдвое за каждый период Recovery
+	c.Advance(10 * time.Minute)
+	if got, want := selector.Health()[0].Score, 1-(1-mismatchPenalty)/2; got != want {
+		t.Errorf("score after one period = %v, want %v", got, want)
+	}
+	c.Advance(10 * time.Minute)
+	if got, want := selector.Health()[0].Score, 1-(1-mismatchPenalty)/4; got != want {
+		t.Errorf("score after two periods = %v, want %v", got, want)
+	}
+}
+
+func TestUnknownURLIgnored(t *testing.T) {
+	selector, _ := newTestSelector(t, types.Repository{URL: "https://a"}, Options{})
+	selector.ReportFailure("https://unknown", errFetch)
+	if health := selector.Health()[0]; health.Failures != 0 || health.Score != 1 {
+		t.Errorf("health changed by unknown URL: %+v", health)
+	}
+}
+
+func TestSelectNoMirrors(t *testing.T) {
+	selector, _ := newTestSelector(t, types.Repository{Name: "empty"}, Options{})
+	if _, err := selector.Select(); !errors.Is(err, ErrNoMirrors) {
+		t.Errorf("Select() error = %v, want ErrNoMirrors", err)
+	}
+}
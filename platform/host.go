@@ -0,0 +1,77 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"golang.org/x/sys/cpu"
+)
+
+var (
+	currentOnce sync.Once
+	current     Platform
+)
+
+// Current возвращает платформу текущего хоста с определением libc и уровня CPU
+func Current() Platform {
+	currentOnce.Do(func() {
+		current = Platform{
+			OS:      runtime.GOOS,
+			Arch:    runtime.GOARCH,
+			Libc:    detectLibc(),
+			Variant: detectVariant(),
+		}
+	})
+	return current
+}
+
+// detectLibc определяет вариант libc по динамическому загрузчику
+func detectLibc() string {
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+
+	if matches, _ := filepath.Glob("/lib/ld-musl-*.so.1"); len(matches) > 0 {
+		return LibcMusl
+	}
+	for _, pattern := range []string{"/lib*/ld-linux*.so.*", "/lib/*-linux-gnu*/ld-linux*.so.*", "/lib*/libc.so.6"} {
+		if matches, _ := filepath.Glob(pattern); len(matches) > 0 {
+			return LibcGlibc
+		}
+	}
+	if _, err := os.Stat("/etc/alpine-release"); err == nil {
+		return LibcMusl
+	}
+	return ""
+}
+
+// detectVariant определяет уровень набора инструкций процессора
+func detectVariant() string {
+	switch runtime.GOARCH {
+	case "amd64":
+		x := cpu.X86
+		switch {
+		case x.HasAVX512F && x.HasAVX512BW && x.HasAVX512CD && x.HasAVX512DQ && x.HasAVX512VL &&
+			x.HasAVX2 && x.HasBMI1 && x.HasBMI2 && x.HasFMA:
+			return "v4"
+		case x.HasAVX && x.HasAVX2 && x.HasBMI1 && x.HasBMI2 && x.HasFMA && x.HasOSXSAVE:
+			return "v3"
+		case x.HasCX16 && x.HasPOPCNT && x.HasSSE3 && x.HasSSSE3 && x.HasSSE41 && x.HasSSE42:
+			return "v2"
+		default:
+			return "v1"
+		}
+	case "arm":
+		switch {
+		case cpu.ARM.HasVFPv3:
+			return "v7"
+		case cpu.ARM.HasVFP:
+			return "v6"
+		default:
+			return "v5"
+		}
+	}
+	return ""
+}
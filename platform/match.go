@@ -0,0 +1,188 @@
+package platform
+
+import (
+	"path"
+	"strings"
+)
+
+// Веса при выборе наиболее подходящей сборки
+const (
+	scoreOS          = 1000
+	scoreArch        = 100
+	scoreLibcExact   = 20
+	scoreLibcGeneric = 10
+	scoreLibcUnknown = 5
+)
+
+// Базовые уровни CPU, предполагаемые при неизвестном уровне цели
+var baselineLevels = map[string]int{
+	"amd64": 1,
+	"arm":   5,
+	"arm64": 8,
+}
+
+// MatchOS проверяет операционную систему по списку шаблонов.
+// Пустой список подходит для любой системы.
+func MatchOS(patterns []string, os string) bool {
+	normalized := NormalizeOS(os)
+	return matchList(patterns, normalized, synonyms(os, normalized, osAliases), NormalizeOS)
+}
+
+// MatchArch проверяет архитектуру по списку шаблонов.
+// Пустой список подходит для любой архитектуры.
+func MatchArch(patterns []string, arch string) bool {
+	normalized, _ := NormalizeArch(arch)
+	return matchList(patterns, normalized, synonyms(arch, normalized, archAliases), func(value string) string {
+		normalized, _ := NormalizeArch(value)
+		return normalized
+	})
+}
+
+// Match проверяет, подходит ли платформа под списки шаблонов OS и архитектур
+func Match(osPatterns, archPatterns []string, target Platform) bool {
+	return MatchOS(osPatterns, target.OS) && MatchArch(archPatterns, target.Arch)
+}
+
+// matchList сопоставляет значение со списком шаблонов.
+// Шаблоны с "!" исключают значение; если есть хотя бы один включающий шаблон,
+// значение должно подойти под один из них. Поддерживаются "*" и glob-шаблоны;
+// glob-шаблон сопоставляется со всеми синонимами значения names.
+func matchList(patterns []string, target string, names []string, normalize func(string) string) bool {
+	included := false
+	hasIncludes := false
+
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+
+		exclude := strings.HasPrefix(pattern, "!")
+		if exclude {
+			pattern = strings.TrimSpace(pattern[1:])
+		}
+
+		matched := matchPattern(pattern, target, names, normalize)
+		if exclude {
+			if matched {
+				return false
+			}
+			continue
+		}
+
+		hasIncludes = true
+		if matched {
+			included = true
+		}
+	}
+
+	return included || !hasIncludes
+}
+
+// matchPattern сопоставляет значение с одним шаблоном
+func matchPattern(pattern, target string, names []string, normalize func(string) string) bool {
+	if strings.ContainsAny(pattern, "*?[") && pattern != "*" {
+		pattern = strings.ToLower(pattern)
+		for _, name := range names {
+			if matched, err := path.Match(pattern, name); err == nil && matched {
+				return true
+			}
+		}
+		return false
+	}
+
+	normalized := normalize(pattern)
+	return normalized == Any || target == Any || normalized == target
+}
+
+// synonyms возвращает нормализованное значение, исходное написание и все
+// синонимы из aliases, приводимые к тому же значению ("darwin" -> "macos", "osx", ...)
+func synonyms(value, normalized string, aliases map[string]string) []string {
+	names := []string{normalized}
+	if value = strings.ToLower(strings.TrimSpace(value)); value != normalized {
+		names = append(names, value)
+	}
+	for alias, canonical := range aliases {
+		if canonical == normalized {
+			names = append(names, alias)
+		}
+	}
+	return names
+}
+
+// Score оценивает, насколько сборка candidate подходит для цели target.
+// Возвращает false, если сборка не может выполняться на цели. Точное
+// совпадение OS и архитектуры ценится выше универсальной сборки, затем
+// учитываются libc и наибольший поддерживаемый уровень CPU.
+func Score(candidate, target Platform) (int, bool) {
+	c, t := candidate.Normalize(), target.Normalize()
+	score := 0
+
+	switch {
+	case c.OS == Any || t.OS == Any:
+	case c.OS == t.OS:
+		score += scoreOS
+	default:
+		return 0, false
+	}
+
+	switch {
+	case c.Arch == Any || t.Arch == Any:
+	case c.Arch == t.Arch:
+		score += scoreArch
+	default:
+		return 0, false
+	}
+
+	switch {
+	case c.Libc == "":
+		score += scoreLibcGeneric
+	case t.Libc == "":
+		score += scoreLibcUnknown
+	case c.Libc == t.Libc:
+		score += scoreLibcExact
+	default:
+		return 0, false
+	}
+
+	if c.Variant != "" && c.Arch != Any {
+		candidateLevel := level(c.Variant)
+		targetLevel := level(t.Variant)
+		if targetLevel == 0 {
+			targetLevel = baselineLevels[t.Arch]
+		}
+		if candidateLevel == 0 || candidateLevel > targetLevel {
+			return 0, false
+		}
+		score += candidateLevel
+	}
+
+	return score, true
+}
+
+// Compatible проверяет, может ли сборка candidate выполняться на цели target
+func Compatible(candidate, target Platform) bool {
+	_, ok := Score(candidate, target)
+	return ok
+}
+
+// Best возвращает индекс наиболее подходящей сборки для цели или -1.
+// При равной оценке выбирается первая.
+func Best(candidates []Platform, target Platform) int {
+	best, bestScore := -1, 0
+	for i, candidate := range candidates {
+		score, ok := Score(candidate, target)
+		if ok && (best < 0 || score > bestScore) {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
+
+// level возвращает номер уровня CPU ("v3" -> 3) или 0
+func level(variant string) int {
+	if len(variant) == 2 && variant[0] == 'v' && isLevel(variant[1:]) {
+		return int(variant[1] - '0')
+	}
+	return 0
+}
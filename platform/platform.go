@@ -0,0 +1,256 @@
+// Package platform нормализует идентификаторы платформ (OS, архитектура,
+// libc, уровень CPU), сопоставляет их с шаблонами вида "linux", "!windows", "*"
+// и выбирает наиболее подходящую сборку для хоста или заданной цели.
+package platform
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Варианты стандартной библиотеки C
+const (
+	LibcGlibc = "glibc"
+	LibcMusl  = "musl"
+)
+
+// Any идентификатор, подходящий для любой платформы
+const Any = "any"
+
+// Platform целевая платформа сборки
+type Platform struct {
+	OS   string
+	Arch string
+	// Libc вариант libc для Linux (glibc, musl), пусто - не важно или статическая сборка
+	Libc string
+	// Variant уровень набора инструкций: v1-v4 для amd64, v5-v7 для arm, v8-v9 для arm64
+	Variant string
+}
+
+// Синонимы операционных систем
+var osAliases = map[string]string{
+	"macos":  "darwin",
+	"mac":    "darwin",
+	"osx":    "darwin",
+	"macosx": "darwin",
+	"win":    "windows",
+	"win32":  "windows",
+	"win64":  "windows",
+	"mingw":  "windows",
+	"cygwin": "windows",
+	"sunos":  "solaris",
+}
+
+// Синонимы архитектур
+var archAliases = map[string]string{
+	"x86_64":      "amd64",
+	"x86-64":      "amd64",
+	"x64":         "amd64",
+	"em64t":       "amd64",
+	"aarch64":     "arm64",
+	"armv8":       "arm64",
+	"arm64e":      "arm64",
+	"i386":        "386",
+	"i486":        "386",
+	"i586":        "386",
+	"i686":        "386",
+	"x86":         "386",
+	"ppc64el":     "ppc64le",
+	"powerpc64le": "ppc64le",
+	"riscv":       "riscv64",
+}
+
+// Синонимы вариантов libc
+var libcAliases = map[string]string{
+	"gnu":   LibcGlibc,
+	"glibc": LibcGlibc,
+	"libc":  LibcGlibc,
+	"musl":  LibcMusl,
+}
+
+// Известные операционные системы и архитектуры (GOOS и GOARCH)
+var (
+	knownOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true,
+		"illumos": true, "ios": true, "js": true, "linux": true, "netbsd": true,
+		"openbsd": true, "plan9": true, "solaris": true, "wasip1": true, "windows": true,
+	}
+	knownArch = map[string]bool{
+		"386": true, "amd64": true, "arm": true, "arm64": true, "loong64": true,
+		"mips": true, "mipsle": true, "mips64": true, "mips64le": true, "ppc64": true,
+		"ppc64le": true, "riscv64": true, "s390x": true, "wasm": true,
+	}
+)
+
+// KnownOS проверяет, известна ли операционная система (с учетом синонимов)
+func KnownOS(value string) bool {
+	normalized := NormalizeOS(value)
+	return normalized == Any || knownOS[normalized]
+}
+
+// KnownArch проверяет, известна ли архитектура (с учетом синонимов)
+func KnownArch(value string) bool {
+	normalized, _ := NormalizeArch(value)
+	return normalized == Any || knownArch[normalized]
+}
+
+// NormalizeOS приводит имя операционной системы к виду GOOS
+func NormalizeOS(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if isAny(value) {
+		return Any
+	}
+	if alias, ok := osAliases[value]; ok {
+		return alias
+	}
+	return value
+}
+
+// NormalizeArch приводит архитектуру к виду GOARCH и выделяет уровень CPU
+// ("x86_64_v3" -> "amd64", "v3"; "arm64v8" -> "arm64", "v8"; "armv7l" -> "arm", "v7")
+func NormalizeArch(value string) (arch, variant string) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if isAny(value) {
+		return Any, ""
+	}
+
+	// amd64v3, x86_64_v3, x86-64-v3, arm64v8
+	for _, sep := range []string{"_v", "-v", "v"} {
+		if i := strings.LastIndex(value, sep); i > 0 {
+			level := value[i+len(sep):]
+			base := value[:i]
+			if isLevel(level) {
+				switch normalized := normalizeBaseArch(base); normalized {
+				case "amd64", "arm64":
+					return normalized, "v" + level
+				}
+			}
+		}
+	}
+
+	// armv5, armv6l, armv7hf, armhf
+	if strings.HasPrefix(value, "armv") && value != "armv8" {
+		level := strings.TrimRight(strings.TrimPrefix(value, "armv"), "lhf")
+		if isLevel(level) {
+			return "arm", "v" + level
+		}
+	}
+	switch value {
+	case "armhf", "armel":
+		return "arm", ""
+	}
+
+	return normalizeBaseArch(value), ""
+}
+
+// normalizeBaseArch приводит архитектуру без уровня к виду GOARCH
+func normalizeBaseArch(value string) string {
+	if alias, ok := archAliases[value]; ok {
+		return alias
+	}
+	return value
+}
+
+// NormalizeLibc приводит вариант libc к виду glibc или musl
+func NormalizeLibc(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if isAny(value) {
+		return ""
+	}
+	if alias, ok := libcAliases[value]; ok {
+		return alias
+	}
+	return value
+}
+
+// NormalizeVariant приводит уровень CPU к виду "v3"
+func NormalizeVariant(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if isAny(value) {
+		return ""
+	}
+	if !strings.HasPrefix(value, "v") && isLevel(value) {
+		return "v" + value
+	}
+	return value
+}
+
+// New создает нормализованную платформу
+func New(os, arch, libc, variant string) Platform {
+	normalizedArch, archVariant := NormalizeArch(arch)
+	if variant == "" {
+		variant = archVariant
+	}
+	return Platform{
+		OS:      NormalizeOS(os),
+		Arch:    normalizedArch,
+		Libc:    NormalizeLibc(libc),
+		Variant: NormalizeVariant(variant),
+	}
+}
+
+// Parse разбирает платформу вида "os[-libc]/arch[/variant]",
+// например "linux/amd64", "linux-musl/arm64", "linux/amd64/v3"
+func Parse(value string) (Platform, error) {
+	parts := strings.Split(strings.TrimSpace(value), "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return Platform{}, fmt.Errorf("invalid platform %q: expected os[-libc]/arch[/variant]", value)
+	}
+
+	os, libc, _ := strings.Cut(parts[0], "-")
+	variant := ""
+	if len(parts) == 3 {
+		variant = parts[2]
+	}
+
+	return New(os, parts[1], libc, variant), nil
+}
+
+// MustParse разбирает платформу и паникует при ошибке
+func MustParse(value string) Platform {
+	p, err := Parse(value)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String возвращает платформу в виде "os[-libc]/arch[/variant]"
+func (p Platform) String() string {
+	os := orAny(p.OS)
+	if p.Libc != "" {
+		os += "-" + p.Libc
+	}
+	s := os + "/" + orAny(p.Arch)
+	if p.Variant != "" {
+		s += "/" + p.Variant
+	}
+	return s
+}
+
+// Normalize возвращает платформу с нормализованными идентификаторами
+func (p Platform) Normalize() Platform {
+	return New(p.OS, p.Arch, p.Libc, p.Variant)
+}
+
+// isAny проверяет, обозначает ли идентификатор любую платформу
+func isAny(value string) bool {
+	switch value {
+	case "", "*", "any", "all", "noarch":
+		return true
+	}
+	return false
+}
+
+// isLevel проверяет, является ли строка номером уровня CPU
+func isLevel(value string) bool {
+	return len(value) == 1 && value[0] >= '1' && value[0] <= '9'
+}
+
+// orAny возвращает Any для пустого идентификатора
+func orAny(value string) string {
+	if value == "" {
+		return Any
+	}
+	return value
+}
@@ -0,0 +1,171 @@
+package platform
+
+import "testing"
+
+func TestNormalizeArch(t *testing.T) {
+	tests := []struct {
+		value   string
+		arch    string
+		variant string
+	}{
+		{"x86_64", "amd64", ""},
+		{"X86_64_V3", "amd64", "v3"},
+		{"x86-64-v2", "amd64", "v2"},
+		{"amd64v4", "amd64", "v4"},
+		{"aarch64", "arm64", ""},
+		{"armv8", "arm64", ""},
+		{"arm64v8", "arm64", "v8"},
+		{"armv7l", "arm", "v7"},
+		{"armv6hf", "arm", "v6"},
+		{"armhf", "arm", ""},
+		{"i686", "386", ""},
+		{"ppc64el", "ppc64le", ""},
+		{"noarch", Any, ""},
+		{"", Any, ""},
+		{"sparc", "sparc", ""},
+	}
+	for _, tt := range tests {
+		arch, variant := NormalizeArch(tt.value)
+		if arch != tt.arch || variant != tt.variant {
+			t.Errorf("NormalizeArch(%q) = %q, %q, want %q, %q", tt.value, arch, variant, tt.arch, tt.variant)
+		}
+	}
+}
+
+func TestNormalizeOS(t *testing.T) {
+	tests := map[string]string{
+		"macOS":   "darwin",
+		" osx ":   "darwin",
+		"win64":   "windows",
+		"Linux":   "linux",
+		"all":     Any,
+		"freebsd": "freebsd",
+	}
+	for value, want := range tests {
+		if got := NormalizeOS(value); got != want {
+			t.Errorf("NormalizeOS(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestMatchOS(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		os       string
+		want     bool
+	}{
+		{nil, "linux", true},
+		{[]string{"*"}, "windows", true},
+		{[]string{"linux", "darwin"}, "macos", true},
+		{[]string{"macos"}, "darwin", true},
+		{[]string{"linux"}, "windows", false},
+		{[]string{"!windows"}, "linux", true},
+		{[]string{"!win"}, "windows", false},
+		{[]string{"*", "!windows"}, "win32", false},
+		{[]string{"mac*"}, "darwin", true},
+		{[]string{"darw?n"}, "osx", true},
+		{[]string{"!mac*"}, "darwin", false},
+		{[]string{"*bsd"}, "freebsd", true},
+		{[]string{"*bsd"}, "linux", false},
+		{[]string{"linux"}, "any", true},
+	}
+	for _, tt := range tests {
+		if got := MatchOS(tt.patterns, tt.os); got != tt.want {
+			t.Errorf("MatchOS(%q, %q) = %v, want %v", tt.patterns, tt.os, got, tt.want)
+		}
+	}
+}
+
+func TestMatchArch(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		arch     string
+		want     bool
+	}{
+		{nil, "arm64", true},
+		{[]string{"amd64"}, "x86_64", true},
+		{[]string{"x86_64"}, "amd64", true},
+		{[]string{"arm64"}, "aarch64v8", true},
+		{[]string{"arm64"}, "arm64v8", true},
+		{[]string{"arm"}, "armv7l", true},
+		{[]string{"arm"}, "arm64", false},
+		{[]string{"!386"}, "i686", false},
+		{[]string{"x86*"}, "amd64", true},
+		{[]string{"aarch*"}, "arm64", true},
+		{[]string{"arm*"}, "aarch64", true},
+		{[]string{"ppc*"}, "amd64", false},
+		{[]string{"*", "!arm*"}, "armv6", false},
+	}
+	for _, tt := range tests {
+		if got := MatchArch(tt.patterns, tt.arch); got != tt.want {
+			t.Errorf("MatchArch(%q, %q) = %v, want %v", tt.patterns, tt.arch, got, tt.want)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		candidate string
+		target    string
+		ok        bool
+	}{
+		{"linux/amd64", "linux/amd64", true},
+		{"any/any", "windows/arm64", true},
+		{"linux/amd64", "darwin/amd64", false},
+		{"linux/arm64", "linux/amd64", false},
+		{"linux-musl/amd64", "linux-musl/amd64", true},
+		{"linux-musl/amd64", "linux-gnu/amd64", false},
+		{"linux-musl/amd64", "linux/amd64", true},
+		{"linux/amd64", "linux-musl/amd64", true},
+		{"linux/amd64/v3", "linux/amd64/v4", true},
+		{"linux/amd64/v3", "linux/amd64/v2", false},
+		{"linux/amd64/v2", "linux/amd64", false},
+		{"linux/amd64/v1", "linux/amd64", true},
+		{"linux/arm/v7", "linux/armv7l", true},
+		{"linux/arm/v7", "linux/armv6", false},
+		{"linux/arm64/v8", "linux/arm64", true},
+		{"linux/arm64/v9", "linux/arm64", false},
+	}
+	for _, tt := range tests {
+		_, ok := Score(MustParse(tt.candidate), MustParse(tt.target))
+		if ok != tt.ok {
+			t.Errorf("Score(%s, %s) ok = %v, want %v", tt.candidate, tt.target, ok, tt.ok)
+		}
+	}
+}
+
+func TestBest(t *testing.T) {
+	candidates := []Platform{
+		MustParse("any/any"),
+		MustParse("linux/amd64"),
+		MustParse("linux-musl/amd64"),
+		MustParse("linux-gnu/amd64"),
+		MustParse("linux-gnu/amd64/v3"),
+		MustParse("linux-gnu/amd64/v4"),
+		MustParse("darwin/arm64"),
+	}
+
+	tests := []struct {
+		target string
+		want   int
+	}{
+		// Точное совпадение libc и наибольший поддерживаемый уровень CPU
+		{"linux-gnu/amd64/v3", 4},
+		{"linux-gnu/amd64/v4", 5},
+		{"linux-gnu/amd64", 3},
+		{"linux-musl/amd64/v4", 2},
+		// Без известной libc универсальная сборка предпочтительнее сборки под конкретную libc
+		{"linux/amd64", 1},
+		{"darwin/arm64", 6},
+		{"windows/amd64", 0},
+	}
+	for _, tt := range tests {
+		if got := Best(candidates, MustParse(tt.target)); got != tt.want {
+			t.Errorf("Best(%s) = %d, want %d", tt.target, got, tt.want)
+		}
+	}
+
+	if got := Best([]Platform{MustParse("darwin/arm64")}, MustParse("linux/amd64")); got != -1 {
+		t.Errorf("Best() without compatible builds = %d, want -1", got)
+	}
+}
@@ -0,0 +1,128 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrTooComplex перебор превысил допустимое число шагов
+var ErrTooComplex = errors.New("dependency resolution exceeded step limit")
+
+// RequirementKind вид требования
+type RequirementKind int
+
+const (
+	// KindRequired обязательная зависимость
+	KindRequired RequirementKind = iota
+	// KindOptional необязательная зависимость, устанавливается при возможности
+	KindOptional
+	// KindPeer одноранговая зависимость: ограничение на пакет, установленный другими
+	KindPeer
+	// KindConflicts запрет совместной установки
+	KindConflicts
+	// KindReplaces замещение пакета
+	KindReplaces
+)
+
+var kindVerbs = map[RequirementKind]string{
+	KindRequired:  "requires",
+	KindOptional:  "optionally requires",
+	KindPeer:      "expects peer",
+	KindConflicts: "conflicts with",
+	KindReplaces:  "replaces",
+}
+
+// Requirement требование одного пакета к версии другого
+type Requirement struct {
+	// Package и Version пакета, предъявляющего требование (пустой Package - корневой манифест)
+	Package string
+	Version string
+
+	Kind       RequirementKind
+	Dependency string
+	Constraint string
+}
+
+// String описывает требование в виде "a@1.2.0 requires b ^1.0.0"
+func (r Requirement) String() string {
+	return fmt.Sprintf("%s %s %s %s", r.Source(), kindVerbs[r.Kind], r.Dependency, r.Constraint)
+}
+
+// Source возвращает описание пакета, предъявившего требование
+func (r Requirement) Source() string {
+	if r.Package == "" {
+		return "root"
+	}
+	if r.Version == "" {
+		return r.Package
+	}
+	return r.Package + "@" + r.Version
+}
+
+// ConflictError ни одна доступная версия пакета не удовлетворяет всем требованиям
+type ConflictError struct {
+	Package      string
+	Requirements []Requirement
+	// Selected уже выбранная версия, отвергнутая новым требованием
+	Selected string
+	// Available версии пакета, доступные для целевой платформы
+	Available []string
+}
+
+func (e *ConflictError) Error() string {
+	var b strings.Builder
+	if e.Selected != "" {
+		fmt.Fprintf(&b, "conflicting requirements for %s (selected %s):", e.Package, e.Selected)
+	} else {
+		fmt.Fprintf(&b, "no version of %s satisfies all requirements:", e.Package)
+	}
+	for _, requirement := range e.Requirements {
+		b.WriteString("\n  - ")
+		b.WriteString(requirement.String())
+	}
+	if len(e.Available) > 0 {
+		fmt.Fprintf(&b, "\n  available versions: %s", strings.Join(e.Available, ", "))
+	}
+	return b.String()
+}
+
+// PackageConflictError выбранный пакет запрещен отношением conflicts или replaces другого пакета
+type PackageConflictError struct {
+	// Declared запрет, который нарушен
+	Declared Requirement
+	Package  string
+	Version  string
+}
+
+func (e *PackageConflictError) Error() string {
+	return fmt.Sprintf("%s@%s cannot be installed: %s", e.Package, e.Version, e.Declared)
+}
+
+// NotFoundError пакет отсутствует в репозиториях или не собран для целевой платформы
+type NotFoundError struct {
+	Package      string
+	Requirements []Requirement
+	// Platform целевая платформа вида "linux/amd64"
+	Platform string
+	// OtherPlatforms платформы, для которых пакет доступен
+	OtherPlatforms []string
+}
+
+func (e *NotFoundError) Error() string {
+	var b strings.Builder
+	if len(e.OtherPlatforms) > 0 {
+		fmt.Fprintf(&b, "package %s has no files for %s (available for: %s)", e.Package, e.Platform, strings.Join(e.OtherPlatforms, ", "))
+	} else {
+		fmt.Fprintf(&b, "package %s not found in any repository", e.Package)
+	}
+
+	sources := make([]string, 0, len(e.Requirements))
+	for _, requirement := range e.Requirements {
+		sources = append(sources, requirement.Source())
+	}
+	if len(sources) > 0 {
+		fmt.Fprintf(&b, ", required by %s", strings.Join(sources, ", "))
+	}
+	return b.String()
+}
@@ -0,0 +1,375 @@
+// Package resolver подбирает согласованный набор версий пакетов по корневому
+// манифесту и индексам репозиториев: перебор с возвратом, учет приоритета
+// репозиториев, фильтрация по платформе и понятные объяснения конфликтов.
+//
+// Поддерживаются обязательные, необязательные (optionalDependencies) и
+// одноранговые (peerDependencies) зависимости, а также отношения conflicts,
+// provides (виртуальные пакеты) и replaces.
+package resolver
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/criage-oss/criage-common/platform"
+	"github.com/criage-oss/criage-common/semver"
+	"github.com/criage-oss/criage-common/types"
+)
+
+// DefaultMaxSteps ограничение числа шагов перебора по умолчанию
+const DefaultMaxSteps = 100000
+
+// Source снимок индекса репозитория
+type Source struct {
+	Repository types.Repository
+	Index      *types.RepositoryIndex
+}
+
+// Options параметры разрешения зависимостей
+type Options struct {
+	// Platform целевая платформа (по умолчанию - текущий хост)
+	Platform platform.Platform
+
+	// IncludeDev разрешать также DevDeps корневого манифеста
+	IncludeDev bool
+	// IncludePrerelease допускать pre-release версии без явного указания
+	IncludePrerelease bool
+	// MaxSteps ограничение числа шагов перебора
+	MaxSteps int
+}
+
+// DefaultOptions возвращает параметры для текущей платформы
+func DefaultOptions() Options {
+	return Options{
+		Platform: platform.Current(),
+		MaxSteps: DefaultMaxSteps,
+	}
+}
+
+// ResolvedPackage выбранная версия пакета
+type ResolvedPackage struct {
+	Name       string
+	Version    string
+	Repository string
+
+	Entry *types.VersionEntry
+	// File файл для целевой платформы (nil, если у версии нет файлов)
+	File *types.FileEntry
+
+	RequiredBy []Requirement
+}
+
+// SkippedDependency необязательная зависимость, которую не удалось установить
+type SkippedDependency struct {
+	Requirement Requirement
+	Reason      string
+}
+
+// Resolution результат разрешения зависимостей
+type Resolution struct {
+	Root     *types.PackageManifest
+	Packages []*ResolvedPackage
+	// IncludeDev зависимости разработки корневого манифеста были разрешены
+	IncludeDev bool
+
+	// Virtual виртуальные пакеты и пакеты, которые их предоставляют
+	Virtual map[string]string
+	// Skipped пропущенные необязательные зависимости
+	Skipped []SkippedDependency
+	// MissingPeers одноранговые зависимости, не установленные другими пакетами
+	MissingPeers []Requirement
+}
+
+// Get возвращает выбранный пакет по имени, в том числе виртуальный
+func (r *Resolution) Get(name string) *ResolvedPackage {
+	if provider, ok := r.Virtual[name]; ok {
+		name = provider
+	}
+	for _, pkg := range r.Packages {
+		if pkg.Name == name {
+			return pkg
+		}
+	}
+	return nil
+}
+
+// Resolver разрешает зависимости по индексам репозиториев
+type Resolver struct {
+	sources []Source
+	options Options
+}
+
+// NewResolver создает резолвер; источники отключенных репозиториев пропускаются,
+// остальные упорядочиваются по убыванию приоритета
+func NewResolver(sources []Source, options Options) *Resolver {
+	sorted := make([]Source, 0, len(sources))
+	for _, source := range sources {
+		if source.Repository.Enabled {
+			sorted = append(sorted, source)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Repository.Priority > sorted[j].Repository.Priority
+	})
+
+	if options.MaxSteps <= 0 {
+		options.MaxSteps = DefaultMaxSteps
+	}
+	if options.Platform == (platform.Platform{}) {
+		options.Platform = platform.Current()
+	}
+
+	return &Resolver{sources: sorted, options: options}
+}
+
+// Resolve подбирает версии для всех зависимостей манифеста
+func (r *Resolver) Resolve(manifest *types.PackageManifest) (*Resolution, error) {
+	if err := manifest.ValidateRelations(); err != nil {
+		return nil, err
+	}
+
+	initial := newState()
+
+	deps := manifest.Dependencies
+	if r.options.IncludeDev && len(manifest.DevDeps) > 0 {
+		deps = mergeDependencies(manifest.Dependencies, manifest.DevDeps)
+	}
+
+	root := types.Relations{
+		Dependencies: deps,
+		OptionalDeps: manifest.OptionalDeps,
+		Conflicts:    manifest.Conflicts,
+		Replaces:     manifest.Replaces,
+	}
+	if err := r.addRelations(initial, "", "", root); err != nil {
+		return nil, err
+	}
+
+	s := &search{resolver: r, candidates: make(map[string]*candidateList)}
+	final, err := s.solve(initial)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve dependencies: %w", err)
+	}
+
+	final, err = s.addOptional(final)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve dependencies: %w", err)
+	}
+
+	resolution := final.resolution(manifest)
+	resolution.IncludeDev = r.options.IncludeDev
+	return resolution, nil
+}
+
+// addRelations добавляет в состояние требования и запреты пакета name@version
+func (r *Resolver) addRelations(st *state, name, version string, relations types.Relations) error {
+	source := Requirement{Package: name, Version: version}
+
+	kinds := []struct {
+		kind RequirementKind
+		deps map[string]string
+	}{
+		{KindRequired, relations.Dependencies},
+		{KindOptional, relations.OptionalDeps},
+		{KindPeer, relations.PeerDeps},
+	}
+	for _, group := range kinds {
+		for _, dep := range sortedKeys(group.deps) {
+			req := source
+			req.Kind = group.kind
+			req.Dependency = dep
+			req.Constraint = group.deps[dep]
+
+			parsed, err := r.newRequirement(req)
+			if err != nil {
+				return fmt.Errorf("%s has invalid dependency %s: %w", source.Source(), dep, err)
+			}
+			st.require(parsed)
+		}
+	}
+
+	rules := []struct {
+		replaces bool
+		deps     map[string]string
+	}{
+		{false, relations.Conflicts},
+		{true, relations.Replaces},
+	}
+	for _, group := range rules {
+		for _, dep := range sortedKeys(group.deps) {
+			constraint, err := semver.ParseConstraint(group.deps[dep])
+			if err != nil {
+				return fmt.Errorf("%s has invalid conflict %s: %w", source.Source(), dep, err)
+			}
+			constraint.IncludePrerelease = true
+			st.conflicts = append(st.conflicts, conflictRule{
+				source:     source,
+				name:       dep,
+				raw:        group.deps[dep],
+				constraint: constraint,
+				replaces:   group.replaces,
+			})
+		}
+	}
+
+	return nil
+}
+
+// requirement требование с разобранным ограничением
+type requirement struct {
+	Requirement
+	constraint *semver.Constraint
+}
+
+// newRequirement разбирает ограничение требования
+func (r *Resolver) newRequirement(req Requirement) (requirement, error) {
+	constraint, err := semver.ParseConstraint(req.Constraint)
+	if err != nil {
+		return requirement{}, err
+	}
+	constraint.IncludePrerelease = r.options.IncludePrerelease
+	return requirement{Requirement: req, constraint: constraint}, nil
+}
+
+// conflictRule запрет совместной установки с версиями пакета.
+// Замещение (replaces) также запрещает совместную установку с замещаемым пакетом.
+type conflictRule struct {
+	source     Requirement
+	name       string
+	raw        string
+	constraint *semver.Constraint
+	replaces   bool
+}
+
+// state частичное решение
+type state struct {
+	selected     map[string]*candidate
+	requirements map[string][]requirement
+	conflicts    []conflictRule
+	// wanted необязательные зависимости, которые решено установить
+	wanted  map[string]bool
+	skipped []SkippedDependency
+}
+
+func newState() *state {
+	return &state{
+		selected:     make(map[string]*candidate),
+		requirements: make(map[string][]requirement),
+		wanted:       make(map[string]bool),
+	}
+}
+
+// clone копирует состояние для очередной ветви перебора
+func (s *state) clone() *state {
+	c := newState()
+	for name, selected := range s.selected {
+		c.selected[name] = selected
+	}
+	for name, reqs := range s.requirements {
+		c.requirements[name] = append([]requirement(nil), reqs...)
+	}
+	for name := range s.wanted {
+		c.wanted[name] = true
+	}
+	c.conflicts = append([]conflictRule(nil), s.conflicts...)
+	c.skipped = append([]SkippedDependency(nil), s.skipped...)
+	return c
+}
+
+// require добавляет требование к пакету
+func (s *state) require(req requirement) {
+	s.requirements[req.Dependency] = append(s.requirements[req.Dependency], req)
+}
+
+// pending проверяет, нужно ли выбрать версию пакета
+func (s *state) pending(name string) bool {
+	if _, ok := s.selected[name]; ok {
+		return false
+	}
+	if s.wanted[name] {
+		return true
+	}
+	for _, req := range s.requirements[name] {
+		if req.Kind == KindRequired {
+			return true
+		}
+	}
+	return false
+}
+
+// publicRequirements возвращает требования к пакету без разобранных ограничений
+func (s *state) publicRequirements(name string) []Requirement {
+	reqs := make([]Requirement, 0, len(s.requirements[name]))
+	for _, req := range s.requirements[name] {
+		reqs = append(reqs, req.Requirement)
+	}
+	return reqs
+}
+
+// resolution формирует результат из полного решения
+func (s *state) resolution(manifest *types.PackageManifest) *Resolution {
+	result := &Resolution{Root: manifest, Skipped: s.skipped}
+
+	for _, name := range sortedKeys(s.selected) {
+		c := s.selected[name]
+		if c.provider != "" {
+			if result.Virtual == nil {
+				result.Virtual = make(map[string]string)
+			}
+			result.Virtual[name] = c.provider
+			continue
+		}
+
+		// Требования к виртуальным пакетам относятся к их поставщику
+		requiredBy := s.publicRequirements(name)
+		for _, virtual := range sortedKeys(s.selected) {
+			if s.selected[virtual].provider == name {
+				requiredBy = append(requiredBy, s.publicRequirements(virtual)...)
+			}
+		}
+
+		result.Packages = append(result.Packages, &ResolvedPackage{
+			Name:       c.name,
+			Version:    c.entry.Version,
+			Repository: c.repository,
+			Entry:      c.entry,
+			File:       c.file,
+			RequiredBy: requiredBy,
+		})
+	}
+
+	for _, name := range sortedKeys(s.requirements) {
+		if _, ok := s.selected[name]; ok {
+			continue
+		}
+		for _, req := range s.requirements[name] {
+			if req.Kind == KindPeer {
+				result.MissingPeers = append(result.MissingPeers, req.Requirement)
+			}
+		}
+	}
+
+	return result
+}
+
+// mergeDependencies объединяет зависимости, при совпадении имен побеждает первая карта
+func mergeDependencies(primary, secondary map[string]string) map[string]string {
+	merged := make(map[string]string, len(primary)+len(secondary))
+	for name, constraint := range secondary {
+		merged[name] = constraint
+	}
+	for name, constraint := range primary {
+		merged[name] = constraint
+	}
+	return merged
+}
+
+// sortedKeys возвращает ключи карты в порядке возрастания
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
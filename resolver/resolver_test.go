@@ -0,0 +1,228 @@
+package resolver
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/criage-oss/criage-common/platform"
+	"github.com/criage-oss/criage-common/types"
+)
+
+// versions строит запись индекса с версиями пакета без файлов
+func versions(name string, list ...string) *types.PackageEntry {
+	entry := &types.PackageEntry{Name: name}
+	for _, version := range list {
+		entry.Versions = append(entry.Versions, types.VersionEntry{Version: version, Checksum: "sha256:" + name + version})
+	}
+	return entry
+}
+
+func source(name string, priority int, enabled bool, packages ...*types.PackageEntry) Source {
+	index := &types.RepositoryIndex{Packages: make(map[string]*types.PackageEntry)}
+	for _, pkg := range packages {
+		index.Packages[pkg.Name] = pkg
+	}
+	return Source{
+		Repository: types.Repository{Name: name, URL: "https://" + name + ".example.com", Priority: priority, Enabled: enabled},
+		Index:      index,
+	}
+}
+
+func manifest(deps map[string]string) *types.PackageManifest {
+	return &types.PackageManifest{Name: "app", Version: "1.0.0", Dependencies: deps}
+}
+
+func TestResolveSkipsDisabledRepositories(t *testing.T) {
+	r := NewResolver([]Source{
+		source("disabled", 200, false, versions("lib", "1.9.0"), versions("extra", "1.0.0")),
+		source("official", 100, true, versions("lib", "1.2.0")),
+	}, DefaultOptions())
+
+	resolution, err := r.Resolve(manifest(map[string]string{"lib": "^1.0.0"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg := resolution.Get("lib"); pkg.Version != "1.2.0" || pkg.Repository != "official" {
+		t.Errorf("lib resolved to %s from %s, want 1.2.0 from official", pkg.Version, pkg.Repository)
+	}
+
+	_, err = r.Resolve(manifest(map[string]string{"extra": "*"}))
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("package of a disabled repository resolved: %v", err)
+	}
+}
+
+func TestResolvePrefersRepositoryPriority(t *testing.T) {
+	r := NewResolver([]Source{
+		source("official", 100, true, versions("lib", "1.5.0")),
+		source("internal", 200, true, versions("lib", "1.2.0")),
+	}, DefaultOptions())
+
+	resolution, err := r.Resolve(manifest(map[string]string{"lib": "^1.0.0"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg := resolution.Get("lib"); pkg.Repository != "internal" || pkg.Version != "1.2.0" {
+		t.Errorf("lib resolved to %s from %s, want 1.2.0 from internal", pkg.Version, pkg.Repository)
+	}
+}
+
+func TestResolveBacktracks(t *testing.T) {
+	a := versions("a", "1.0.0", "1.1.0")
+	a.Versions[1].Dependencies = map[string]string{"c": "^2.0.0"}
+	a.Versions[0].Dependencies = map[string]string{"c": "^1.0.0"}
+	b := versions("b", "1.0.0")
+	b.Versions[0].Dependencies = map[string]string{"c": "~1.2"}
+
+	r := NewResolver([]Source{source("official", 100, true, a, b, versions("c", "1.2.5", "2.0.0"))}, DefaultOptions())
+	resolution, err := r.Resolve(manifest(map[string]string{"a": "^1.0.0", "b": "^1.0.0"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resolution.Get("a").Version; got != "1.0.0" {
+		t.Errorf("a = %s, want 1.0.0", got)
+	}
+	if got := resolution.Get("c").Version; got != "1.2.5" {
+		t.Errorf("c = %s, want 1.2.5", got)
+	}
+}
+
+func TestResolveConflict(t *testing.T) {
+	a := versions("a", "1.0.0")
+	a.Versions[0].Dependencies = map[string]string{"c": "^2.0.0"}
+	b := versions("b", "1.0.0")
+	b.Versions[0].Dependencies = map[string]string{"c": "~1.2"}
+
+	r := NewResolver([]Source{source("official", 100, true, a, b, versions("c", "1.2.5", "2.0.0"))}, DefaultOptions())
+	_, err := r.Resolve(manifest(map[string]string{"a": "*", "b": "*"}))
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || conflict.Package != "c" {
+		t.Fatalf("expected a conflict on c, got %v", err)
+	}
+}
+
+func TestResolveFiltersPlatform(t *testing.T) {
+	lib := versions("lib", "1.0.0", "1.1.0")
+	lib.Versions[0].Files = []types.FileEntry{{OS: "linux", Arch: "amd64", Format: "tar.zst", Filename: "lib-1.0.0.tar.zst", Checksum: "sha256:a"}}
+	lib.Versions[1].Files = []types.FileEntry{{OS: "darwin", Arch: "arm64", Format: "tar.zst", Filename: "lib-1.1.0.tar.zst", Checksum: "sha256:b"}}
+
+	options := DefaultOptions()
+	options.Platform = platform.Platform{OS: "linux", Arch: "amd64"}
+	resolution, err := NewResolver([]Source{source("official", 100, true, lib)}, options).Resolve(manifest(map[string]string{"lib": "*"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := resolution.Get("lib")
+	if pkg.Version != "1.0.0" || pkg.File == nil || pkg.File.Filename != "lib-1.0.0.tar.zst" {
+		t.Errorf("lib resolved to %s with file %v", pkg.Version, pkg.File)
+	}
+}
+
+func TestResolveSkipsUnavailableOptional(t *testing.T) {
+	lib := versions("lib", "1.0.0")
+	lib.Versions[0].OptionalDeps = map[string]string{"fast": "^2.0.0", "color": "^1.0.0"}
+
+	r := NewResolver([]Source{source("official", 100, true, lib, versions("fast", "1.0.0"), versions("color", "1.1.0"))}, DefaultOptions())
+	resolution, err := r.Resolve(manifest(map[string]string{"lib": "*"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg := resolution.Get("color"); pkg == nil || pkg.Version != "1.1.0" {
+		t.Errorf("optional color = %v, want 1.1.0", pkg)
+	}
+	if pkg := resolution.Get("fast"); pkg != nil {
+		t.Errorf("optional fast resolved to %s", pkg.Version)
+	}
+	if len(resolution.Skipped) != 1 || resolution.Skipped[0].Requirement.Dependency != "fast" || resolution.Skipped[0].Reason == "" {
+		t.Errorf("skipped = %+v, want fast with a reason", resolution.Skipped)
+	}
+}
+
+func TestResolveReportsMissingPeers(t *testing.T) {
+	plugin := versions("plugin", "1.0.0")
+	plugin.Versions[0].PeerDeps = map[string]string{"host": "^3.0.0", "runtime": "^1.0.0"}
+
+	r := NewResolver([]Source{source("official", 100, true, plugin, versions("host", "3.1.0"), versions("runtime", "1.0.0"))}, DefaultOptions())
+	resolution, err := r.Resolve(manifest(map[string]string{"plugin": "*", "host": "^3.0.0"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolution.Get("runtime") != nil {
+		t.Error("peer dependency runtime installed without being required")
+	}
+	if len(resolution.MissingPeers) != 1 || resolution.MissingPeers[0].Dependency != "runtime" {
+		t.Errorf("missing peers = %v, want runtime", resolution.MissingPeers)
+	}
+
+	// Установленный пакет должен удовлетворять одноранговой зависимости
+	_, err = r.Resolve(manifest(map[string]string{"plugin": "*", "host": "^2.0.0"}))
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || conflict.Package != "host" {
+		t.Errorf("expected a conflict on host, got %v", err)
+	}
+}
+
+func TestResolveRejectsConflictsAndReplaces(t *testing.T) {
+	tests := []struct {
+		name     string
+		relation func(entry *types.VersionEntry)
+	}{
+		{"conflicts", func(entry *types.VersionEntry) { entry.Conflicts = map[string]string{"old": "<2.0.0"} }},
+		{"replaces", func(entry *types.VersionEntry) { entry.Replaces = map[string]string{"old": "*"} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lib := versions("lib", "1.0.0")
+			tt.relation(&lib.Versions[0])
+
+			r := NewResolver([]Source{source("official", 100, true, lib, versions("old", "1.5.0"))}, DefaultOptions())
+			_, err := r.Resolve(manifest(map[string]string{"lib": "*", "old": "*"}))
+			var conflict *PackageConflictError
+			if !errors.As(err, &conflict) || conflict.Package != "old" || conflict.Declared.Package != "lib" {
+				t.Fatalf("expected old to be rejected by lib, got %v", err)
+			}
+		})
+	}
+
+	// Запрет не действует на версии вне диапазона
+	lib := versions("lib", "1.0.0")
+	lib.Versions[0].Conflicts = map[string]string{"old": "<2.0.0"}
+	r := NewResolver([]Source{source("official", 100, true, lib, versions("old", "1.5.0", "2.1.0"))}, DefaultOptions())
+	resolution, err := r.Resolve(manifest(map[string]string{"lib": "*", "old": "*"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resolution.Get("old").Version; got != "2.1.0" {
+		t.Errorf("old = %s, want 2.1.0", got)
+	}
+}
+
+func TestResolveVirtualPackage(t *testing.T) {
+	zlog := versions("zlog", "3.0.0")
+	zlog.Versions[0].Provides = []string{"logger@1.4.0"}
+	app := versions("service", "1.0.0")
+	app.Versions[0].Dependencies = map[string]string{"logger": "^1.2.0"}
+
+	r := NewResolver([]Source{source("official", 100, true, app, zlog)}, DefaultOptions())
+	resolution, err := r.Resolve(manifest(map[string]string{"service": "*"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolution.Virtual["logger"] != "zlog" {
+		t.Errorf("virtual = %v, want logger provided by zlog", resolution.Virtual)
+	}
+	pkg := resolution.Get("logger")
+	if pkg == nil || pkg.Name != "zlog" || pkg.Version != "3.0.0" {
+		t.Fatalf("logger resolved to %v, want zlog 3.0.0", pkg)
+	}
+	if len(pkg.RequiredBy) != 1 || pkg.RequiredBy[0].Package != "service" {
+		t.Errorf("zlog required by %v, want service", pkg.RequiredBy)
+	}
+
+	// Версия виртуального пакета проверяется по ограничению
+	_, err = r.Resolve(manifest(map[string]string{"logger": "^2.0.0"}))
+	if err == nil {
+		t.Error("virtual package with a wrong version accepted")
+	}
+}
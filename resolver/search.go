@@ -0,0 +1,459 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/criage-oss/criage-common/semver"
+	"github.com/criage-oss/criage-common/types"
+)
+
+// candidate версия пакета из конкретного репозитория
+type candidate struct {
+	name       string
+	version    *semver.Version
+	entry      *types.VersionEntry
+	file       *types.FileEntry
+	repository string
+
+	// provider имя реального пакета, если кандидат предоставляет виртуальный пакет name
+	provider string
+	real     *candidate
+}
+
+// candidateList все версии пакета, подходящие для целевой платформы
+type candidateList struct {
+	versions []*candidate
+	// otherPlatforms платформы, для которых есть файлы, если версий для целевой нет
+	otherPlatforms []string
+}
+
+// search выполняет перебор с возвратом
+type search struct {
+	resolver   *Resolver
+	candidates map[string]*candidateList
+	steps      int
+}
+
+// solve выбирает версию для очередного пакета и рекурсивно продолжает перебор.
+// Возвращается первая встреченная ошибка: она соответствует ветви с наиболее
+// новыми версиями и обычно лучше всего объясняет конфликт.
+func (s *search) solve(current *state) (*state, error) {
+	s.steps++
+	if s.steps > s.resolver.options.MaxSteps {
+		return nil, fmt.Errorf("%w (%d)", ErrTooComplex, s.resolver.options.MaxSteps)
+	}
+
+	// Выбираем пакет с наименьшим числом подходящих версий
+	var next string
+	var nextViable []*candidate
+	for _, name := range sortedKeys(current.requirements) {
+		if !current.pending(name) {
+			continue
+		}
+
+		viable, err := s.viable(name, current)
+		if err != nil {
+			return nil, err
+		}
+		if next == "" || len(viable) < len(nextViable) {
+			next, nextViable = name, viable
+		}
+	}
+
+	if next == "" {
+		return current, nil
+	}
+
+	var firstErr error
+	for _, c := range nextViable {
+		branch, err := s.choose(current, c)
+		if err == nil {
+			var solved *state
+			solved, err = s.solve(branch)
+			if err == nil {
+				return solved, nil
+			}
+		}
+		if errors.Is(err, ErrTooComplex) {
+			return nil, err
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+// addOptional пытается добавить к решению необязательные зависимости.
+// Зависимость, которую нельзя установить без конфликта, пропускается.
+func (s *search) addOptional(final *state) (*state, error) {
+	attempted := make(map[string]bool)
+
+	for {
+		progressed := false
+		for _, name := range sortedKeys(final.requirements) {
+			if attempted[name] || !hasKind(final.requirements[name], KindOptional) {
+				continue
+			}
+			if _, ok := final.selected[name]; ok {
+				continue
+			}
+			attempted[name] = true
+
+			trial := final.clone()
+			trial.wanted[name] = true
+			solved, err := s.solve(trial)
+			if errors.Is(err, ErrTooComplex) {
+				return nil, err
+			}
+			if err != nil {
+				for _, req := range final.requirements[name] {
+					if req.Kind == KindOptional {
+						final.skipped = append(final.skipped, SkippedDependency{Requirement: req.Requirement, Reason: err.Error()})
+					}
+				}
+				continue
+			}
+
+			final = solved
+			progressed = true
+		}
+
+		if !progressed {
+			break
+		}
+	}
+
+	// Пропущенная зависимость могла быть установлена позже как обязательная
+	skipped := final.skipped[:0]
+	for _, skip := range final.skipped {
+		if _, ok := final.selected[skip.Requirement.Dependency]; !ok {
+			skipped = append(skipped, skip)
+		}
+	}
+	final.skipped = skipped
+
+	return final, nil
+}
+
+// viable возвращает версии пакета, удовлетворяющие всем текущим требованиям
+func (s *search) viable(name string, current *state) ([]*candidate, error) {
+	list := s.candidatesFor(name)
+	if len(list.versions) == 0 {
+		return nil, &NotFoundError{
+			Package:        name,
+			Requirements:   current.publicRequirements(name),
+			Platform:       s.resolver.options.Platform.String(),
+			OtherPlatforms: list.otherPlatforms,
+		}
+	}
+
+	var viable []*candidate
+	for _, c := range list.versions {
+		if !satisfiesAll(c.version, current.requirements[name]) {
+			continue
+		}
+		// Поставщик виртуального пакета уже выбран в другой версии
+		if c.provider != "" {
+			if selected, ok := current.selected[c.provider]; ok && selected.entry != c.entry {
+				continue
+			}
+		}
+		viable = append(viable, c)
+	}
+
+	if len(viable) == 0 {
+		return nil, &ConflictError{
+			Package:      name,
+			Requirements: current.publicRequirements(name),
+			Available:    list.versionStrings(),
+		}
+	}
+	return viable, nil
+}
+
+// choose выбирает версию пакета и добавляет требования ее зависимостей
+func (s *search) choose(current *state, c *candidate) (*state, error) {
+	next := current.clone()
+
+	if c.provider == "" {
+		return next, s.selectPackage(next, c)
+	}
+
+	// Виртуальный пакет: выбираем и его поставщика
+	next.selected[c.name] = c
+	if _, ok := next.selected[c.provider]; ok {
+		return next, nil
+	}
+	if !satisfiesAll(c.real.version, next.requirements[c.provider]) {
+		return nil, &ConflictError{
+			Package:      c.provider,
+			Requirements: next.publicRequirements(c.provider),
+			Selected:     c.real.entry.Version,
+		}
+	}
+	return next, s.selectPackage(next, c.real)
+}
+
+// selectPackage добавляет реальный пакет в решение и проверяет конфликты
+func (s *search) selectPackage(next *state, c *candidate) error {
+	next.selected[c.name] = c
+
+	// Запреты уже выбранных пакетов
+	for _, rule := range next.conflicts {
+		if version, ok := violates(rule, c); ok {
+			return &PackageConflictError{Declared: rule.declared(), Package: c.name, Version: version}
+		}
+	}
+
+	// Запреты и зависимости нового пакета
+	before := len(next.conflicts)
+	relations := c.entry.Relations()
+	relations.DevDeps = nil
+	if err := s.resolver.addRelations(next, c.name, c.entry.Version, relations); err != nil {
+		return err
+	}
+
+	for _, rule := range next.conflicts[before:] {
+		for _, name := range sortedKeys(next.selected) {
+			if version, ok := violates(rule, next.selected[name]); ok {
+				return &PackageConflictError{Declared: rule.declared(), Package: name, Version: version}
+			}
+		}
+	}
+
+	// Уже выбранные версии должны удовлетворять новым требованиям
+	for _, group := range []map[string]string{relations.Dependencies, relations.OptionalDeps, relations.PeerDeps} {
+		for _, dep := range sortedKeys(group) {
+			if selected, ok := next.selected[dep]; ok && !satisfiesAll(selected.version, next.requirements[dep]) {
+				return &ConflictError{
+					Package:      dep,
+					Requirements: next.publicRequirements(dep),
+					Selected:     selected.version.Original(),
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// violates проверяет, нарушает ли кандидат запрет; возвращает конфликтующую версию.
+// Запрет на виртуальный пакет распространяется на всех его поставщиков,
+// кроме объявившего запрет пакета.
+func violates(rule conflictRule, c *candidate) (string, bool) {
+	owner := c.name
+	if c.provider != "" {
+		owner = c.provider
+	}
+	if rule.source.Package != "" && rule.source.Package == owner {
+		return "", false
+	}
+
+	if c.name == rule.name && rule.constraint.Check(c.version) {
+		return c.version.Original(), true
+	}
+	if c.provider != "" {
+		return "", false
+	}
+
+	for _, value := range c.entry.Provides {
+		name, version, err := types.ParseProvides(value)
+		if err != nil || name != rule.name {
+			continue
+		}
+		provided := c.version
+		if version != "" {
+			if v, err := semver.ParseTolerant(version); err == nil {
+				provided = v
+			}
+		}
+		if rule.constraint.Check(provided) {
+			return provided.Original(), true
+		}
+	}
+	return "", false
+}
+
+// declared возвращает требование, описывающее запрет
+func (r conflictRule) declared() Requirement {
+	req := r.source
+	req.Kind = KindConflicts
+	if r.replaces {
+		req.Kind = KindReplaces
+	}
+	req.Dependency = r.name
+	req.Constraint = r.raw
+	return req
+}
+
+// candidatesFor собирает версии пакета из всех источников с кешированием.
+// Порядок: реальные версии, затем поставщики виртуального пакета; внутри группы
+// по убыванию приоритета репозитория, затем по убыванию версии.
+func (s *search) candidatesFor(name string) *candidateList {
+	if list, ok := s.candidates[name]; ok {
+		return list
+	}
+
+	list := &candidateList{}
+	seen := make(map[string]bool)
+	otherPlatforms := make(map[string]bool)
+
+	for _, source := range s.resolver.sources {
+		if source.Index == nil {
+			continue
+		}
+		entry, ok := source.Index.Packages[name]
+		if !ok || entry == nil {
+			continue
+		}
+
+		var fromSource []*candidate
+		for i := range entry.Versions {
+			c, ok := s.newCandidate(name, source, &entry.Versions[i], otherPlatforms)
+			if !ok || seen[c.version.String()] {
+				continue
+			}
+			seen[c.version.String()] = true
+			fromSource = append(fromSource, c)
+		}
+		list.versions = append(list.versions, sortCandidates(fromSource)...)
+	}
+
+	for _, source := range s.resolver.sources {
+		if source.Index == nil {
+			continue
+		}
+
+		var fromSource []*candidate
+		for _, providerName := range sortedKeys(source.Index.Packages) {
+			entry := source.Index.Packages[providerName]
+			if providerName == name || entry == nil {
+				continue
+			}
+			for i := range entry.Versions {
+				versionEntry := &entry.Versions[i]
+				provided, ok := providedVersion(versionEntry, name)
+				if !ok {
+					continue
+				}
+				real, ok := s.newCandidate(providerName, source, versionEntry, otherPlatforms)
+				if !ok || seen[providerName+"@"+real.version.String()] {
+					continue
+				}
+				seen[providerName+"@"+real.version.String()] = true
+
+				virtual := *real
+				virtual.name = name
+				virtual.provider = providerName
+				virtual.real = real
+				if provided != nil {
+					virtual.version = provided
+				}
+				fromSource = append(fromSource, &virtual)
+			}
+		}
+		list.versions = append(list.versions, sortCandidates(fromSource)...)
+	}
+
+	if len(list.versions) == 0 {
+		list.otherPlatforms = sortedKeys(otherPlatforms)
+	}
+
+	s.candidates[name] = list
+	return list
+}
+
+// newCandidate создает кандидата, если у версии есть файл для целевой платформы
+func (s *search) newCandidate(name string, source Source, entry *types.VersionEntry, otherPlatforms map[string]bool) (*candidate, bool) {
+	version, err := semver.ParseTolerant(entry.Version)
+	if err != nil {
+		return nil, false
+	}
+
+	// Версия без файлов считается платформенно-независимой
+	var file *types.FileEntry
+	if len(entry.Files) > 0 {
+		selected, ok := entry.FileFor(s.resolver.options.Platform)
+		if !ok {
+			for i := range entry.Files {
+				otherPlatforms[entry.Files[i].Platform().String()] = true
+			}
+			return nil, false
+		}
+		file = selected
+	}
+
+	return &candidate{
+		name:       name,
+		version:    version,
+		entry:      entry,
+		file:       file,
+		repository: source.Repository.Name,
+	}, true
+}
+
+// providedVersion проверяет, предоставляет ли версия виртуальный пакет name.
+// Возвращает явно указанную версию виртуального пакета или nil.
+func providedVersion(entry *types.VersionEntry, name string) (*semver.Version, bool) {
+	for _, value := range entry.Provides {
+		providedName, version, err := types.ParseProvides(value)
+		if err != nil || providedName != name {
+			continue
+		}
+		if version == "" {
+			return nil, true
+		}
+		v, err := semver.ParseTolerant(version)
+		if err != nil {
+			return nil, false
+		}
+		return v, true
+	}
+	return nil, false
+}
+
+// sortCandidates упорядочивает кандидатов по убыванию версии
+func sortCandidates(candidates []*candidate) []*candidate {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].version.GreaterThan(candidates[j].version)
+	})
+	return candidates
+}
+
+// versionStrings возвращает доступные версии по возрастанию
+func (l *candidateList) versionStrings() []string {
+	versions := make([]*semver.Version, 0, len(l.versions))
+	for _, c := range l.versions {
+		versions = append(versions, c.version)
+	}
+	semver.Sort(versions)
+
+	result := make([]string, 0, len(versions))
+	for _, v := range versions {
+		result = append(result, v.Original())
+	}
+	return result
+}
+
+// satisfiesAll проверяет версию по всем требованиям
+func satisfiesAll(version *semver.Version, reqs []requirement) bool {
+	for _, req := range reqs {
+		if !req.constraint.Check(version) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasKind проверяет наличие требования заданного вида
+func hasKind(reqs []requirement, kind RequirementKind) bool {
+	for _, req := range reqs {
+		if req.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package schema
+
+// typeDescriptions описания объектов по имени типа
+var typeDescriptions = map[string]string{
+	"PackageManifest":   "Package manifest (criage.yaml)",
+	"PackageHooks":      "Commands executed at package lifecycle stages",
+	"BuildManifest":     "Package build manifest",
+	"CompressionConfig": "Archive compression settings",
+	"BuildTarget":       "Target platform of a build",
+	"Config":            "Criage client configuration",
+	"Repository":        "Package repository",
+	"Mirror":            "Repository mirror serving the same content as the repository URL",
+	"NetworkConfig":     "Proxy, TLS certificate and retry settings",
+	"Profile":           "Named configuration overrides; empty fields keep the base value",
+	"ProfileNetwork":    "Network settings of a profile; fields that are present, even zero or empty, replace the base values",
+	"ServerConfig":      "Repository server configuration",
+	"MCPConfig":         "MCP server configuration",
+	"RepositoryIndex":   "Repository index",
+	"PackageEntry":      "Package published in the repository",
+	"VersionEntry":      "Published package version",
+	"FileEntry":         "Package archive for a platform",
+	"DeltaEntry":        "Binary delta from a previous version",
+	"Statistics":        "Repository statistics",
+	"Lockfile":          "Locked dependency resolution (criage.lock)",
+	"LockedPackage":     "Locked package version",
+}
+
+// fieldDescriptions описания полей по ключу "Тип.поле"
+var fieldDescriptions = map[string]string{
+	"PackageManifest.name":                 "Package name: lowercase letters, digits, '.', '_' and '-', optionally scoped as @scope/name",
+	"PackageManifest.version":              "Package version (semver 2.0.0)",
+	"PackageManifest.description":          "Short description of the package",
+	"PackageManifest.author":               "Package author",
+	"PackageManifest.license":              "License, preferably an SPDX identifier",
+	"PackageManifest.homepage":             "Project home page",
+	"PackageManifest.repository":           "Source code repository",
+	"PackageManifest.keywords":             "Keywords used by search",
+	"PackageManifest.dependencies":         "Required dependencies: package name to version constraint",
+	"PackageManifest.devDependencies":      "Dependencies needed only for development",
+	"PackageManifest.optionalDependencies": "Dependencies installed when available; failures are skipped",
+	"PackageManifest.peerDependencies":     "Dependencies that must be installed by another package",
+	"PackageManifest.conflicts":            "Packages that cannot be installed together with this one",
+	"PackageManifest.provides":             "Virtual packages provided by this package, as name or name@version",
+	"PackageManifest.replaces":             "Packages superseded by this package",
+	"PackageManifest.scripts":              "Named scripts",
+	"PackageManifest.files":                "File patterns included into the package",
+	"PackageManifest.exclude":              "File patterns excluded from the package",
+	"PackageManifest.arch":                 "Supported architectures; '!' excludes, '*' matches any",
+	"PackageManifest.os":                   "Supported operating systems; '!' excludes, '*' matches any",
+	"PackageManifest.minVersion":           "Minimum criage version required to install the package",
+	"PackageManifest.hooks":                "Lifecycle hooks",
+	"PackageManifest.metadata":             "Arbitrary additional metadata",
+
+	"PackageHooks.preInstall":  "Commands run before installation",
+	"PackageHooks.postInstall": "Commands run after installation",
+	"PackageHooks.preRemove":   "Commands run before removal",
+	"PackageHooks.postRemove":  "Commands run after removal",
+	"PackageHooks.preUpdate":   "Commands run before update",
+	"PackageHooks.postUpdate":  "Commands run after update",
+
+	"BuildManifest.name":         "Package name",
+	"BuildManifest.version":      "Package version (semver 2.0.0)",
+	"BuildManifest.buildScript":  "Command that builds the package",
+	"BuildManifest.outputDir":    "Directory with build results",
+	"BuildManifest.includeFiles": "File patterns included into the archive",
+	"BuildManifest.excludeFiles": "File patterns excluded from the archive",
+	"BuildManifest.compression":  "Archive compression settings",
+	"BuildManifest.targets":      "Platforms to build for",
+	"BuildManifest.environment":  "Environment variables for the build script",
+
+	"CompressionConfig.format": "Archive format",
+	"CompressionConfig.level":  "Compression level, 0 for the format default",
+
+	"BuildTarget.os":      "Operating system (GOOS or an alias such as macos)",
+	"BuildTarget.arch":    "Architecture (GOARCH or an alias such as x86_64)",
+	"BuildTarget.libc":    "C library for linux targets",
+	"BuildTarget.variant": "CPU level: v1-v4 for amd64, v5-v7 for arm, v8-v9 for arm64",
+
+	"Config.configVersion":        "Version of the configuration file format",
+	"Config.installPath":          "Directory for installed packages",
+	"Config.cachePath":            "Download cache directory",
+	"Config.tempPath":             "Directory for temporary files",
+	"Config.configPath":           "Path of the configuration file",
+	"Config.keyringPath":          "Path of the trusted keys keyring",
+	"Config.timeout":              "Network timeout in seconds",
+	"Config.maxConnections":       "Maximum number of simultaneous connections",
+	"Config.userAgent":            "User-Agent header of HTTP requests",
+	"Config.network":              "Proxy, TLS certificate and retry settings",
+	"Config.repositories":         "Package repositories",
+	"Config.region":               "Client region; mirrors in the same region are preferred",
+	"Config.encryptionRecipients": "Public keys private packages are encrypted for",
+	"Config.identityFiles":        "Secret key files used to decrypt private packages",
+	"Config.compressionLevel":     "Default compression level",
+	"Config.preferredFormat":      "Preferred archive format",
+	"Config.profile":              "Active profile, overridden by CRIAGE_PROFILE",
+	"Config.profiles":             "Named profiles such as internal-mirror, public or offline",
+	"Config.parallel":             "Download and install packages in parallel",
+	"Config.maxParallel":          "Maximum number of parallel operations",
+	"Config.language":             "Interface language",
+	"Config.debug":                "Enable debug output",
+
+	"Profile.description":    "Description of the profile",
+	"Profile.installPath":    "Directory for installed packages",
+	"Profile.cachePath":      "Download cache directory",
+	"Profile.tempPath":       "Directory for temporary files",
+	"Profile.keyringPath":    "Path of the trusted keys keyring",
+	"Profile.timeout":        "Network timeout in seconds",
+	"Profile.maxConnections": "Maximum number of simultaneous connections",
+	"Profile.userAgent":      "User-Agent header of HTTP requests",
+	"Profile.network":        "Network settings; fields that are set replace the base values",
+	"Profile.repositories":   "Repositories replacing the base list",
+	"Profile.region":         "Client region used to prefer mirrors",
+	"Profile.offline":        "Disable all repositories",
+
+	"NetworkConfig.proxy":      "Proxy for all requests (http, https, socks5 or socks5h URL); empty uses HTTP_PROXY/HTTPS_PROXY, \"direct\" disables proxies",
+	"NetworkConfig.httpsProxy": "Proxy for HTTPS requests if it differs from proxy",
+	"NetworkConfig.noProxy":    "Hosts reached without a proxy: *, example.com, .example.com, IP addresses or CIDR subnets, optionally with :port",
+	"NetworkConfig.caFiles":    "PEM files with CA certificates trusted in addition to the system ones",
+	"NetworkConfig.certFile":   "Client certificate for mutual TLS (PEM)",
+	"NetworkConfig.keyFile":    "Private key of the client certificate (PEM)",
+	"NetworkConfig.retries":    "Number of retries after network errors and 429, 502, 503 or 504 responses",
+
+	"ProfileNetwork.proxy":      "Proxy for all requests; an empty string falls back to HTTP_PROXY/HTTPS_PROXY",
+	"ProfileNetwork.httpsProxy": "Proxy for HTTPS requests if it differs from proxy",
+	"ProfileNetwork.noProxy":    "Hosts reached without a proxy; an empty list clears the base list",
+	"ProfileNetwork.caFiles":    "PEM files with CA certificates trusted in addition to the system ones",
+	"ProfileNetwork.certFile":   "Client certificate for mutual TLS (PEM)",
+	"ProfileNetwork.keyFile":    "Private key of the client certificate (PEM)",
+	"ProfileNetwork.retries":    "Number of retries; 0 disables retries",
+
+	"Repository.name":             "Repository name",
+	"Repository.url":              "Repository base URL",
+	"Repository.priority":         "Priority; repositories with higher values are preferred",
+	"Repository.enabled":          "Whether the repository is used",
+	"Repository.mirrors":          "Mirrors used for failover; they share the repository credentials",
+	"Repository.authToken":        "Access token",
+	"Repository.username":         "User name for basic authentication",
+	"Repository.password":         "Password for basic authentication",
+	"Repository.signingKeys":      "Trusted package signing keys",
+	"Repository.requireSignature": "Reject unsigned packages",
+	"Repository.trustMode":        "Signing key trust mode",
+	"Repository.revocationUrl":    "URL of the key revocation list",
+	"Repository.timeout":          "Network timeout in seconds overriding the global one",
+	"Repository.retries":          "Number of retries overriding network.retries",
+	"Repository.caFile":           "PEM file with CA certificates trusted for this repository",
+	"Repository.certFile":         "Client certificate for mutual TLS with this repository (PEM)",
+	"Repository.keyFile":          "Private key of the repository client certificate (PEM)",
+
+	"Mirror.url":      "Mirror base URL",
+	"Mirror.priority": "Explicit preference; mirrors with higher values are tried first",
+	"Mirror.region":   "Region the mirror is hosted in, such as eu or ru-msk",
+
+	"ServerConfig.configVersion":  "Version of the configuration file format",
+	"ServerConfig.host":           "Address to listen on",
+	"ServerConfig.port":           "Port to listen on",
+	"ServerConfig.storagePath":    "Directory with package archives",
+	"ServerConfig.indexPath":      "Path of the repository index",
+	"ServerConfig.authEnabled":    "Require authentication for uploads",
+	"ServerConfig.authToken":      "Upload access token",
+	"ServerConfig.maxFileSize":    "Maximum upload size in bytes",
+	"ServerConfig.allowedFormats": "Archive formats accepted for upload",
+	"ServerConfig.rateLimit":      "Requests per minute per client",
+	"ServerConfig.logLevel":       "Log level",
+	"ServerConfig.logFile":        "Log file, standard output if empty",
+	"ServerConfig.corsEnabled":    "Enable CORS headers",
+	"ServerConfig.corsOrigins":    "Allowed CORS origins",
+
+	"MCPConfig.configVersion":     "Version of the configuration file format",
+	"MCPConfig.criageClientPath":  "Path of the criage executable",
+	"MCPConfig.configPath":        "Client configuration file",
+	"MCPConfig.maxConcurrency":    "Maximum number of concurrent operations",
+	"MCPConfig.timeout":           "Operation timeout in seconds",
+	"MCPConfig.logLevel":          "Log level",
+	"MCPConfig.logFile":           "Log file, standard error if empty",
+	"MCPConfig.allowedOperations": "Operations available to MCP clients",
+	"MCPConfig.restrictedPaths":   "Paths MCP operations may not touch",
+
+	"RepositoryIndex.lastUpdated":   "Time of the last index update",
+	"RepositoryIndex.totalPackages": "Number of packages",
+	"RepositoryIndex.packages":      "Packages by name",
+	"RepositoryIndex.statistics":    "Repository statistics",
+
+	"PackageEntry.latestVersion": "Latest published version",
+	"PackageEntry.versions":      "Published versions",
+	"PackageEntry.downloads":     "Total number of downloads",
+	"PackageEntry.updated":       "Time of the last publication",
+
+	"VersionEntry.version":      "Version (semver 2.0.0)",
+	"VersionEntry.dependencies": "Required dependencies: package name to version constraint",
+	"VersionEntry.files":        "Archives for supported platforms",
+	"VersionEntry.checksum":     "SHA-256 checksum of the archive",
+	"VersionEntry.uploaded":     "Time of publication",
+
+	"FileEntry.os":       "Operating system, 'any' for platform independent archives",
+	"FileEntry.arch":     "Architecture, 'any' for platform independent archives",
+	"FileEntry.libc":     "C library for linux archives",
+	"FileEntry.variant":  "Minimum CPU level",
+	"FileEntry.format":   "Archive format",
+	"FileEntry.filename": "Archive file name",
+	"FileEntry.size":     "Archive size in bytes",
+	"FileEntry.checksum": "SHA-256 checksum of the archive",
+	"FileEntry.deltas":   "Binary deltas from previous versions",
+
+	"Lockfile.lockfileVersion": "Lockfile format version",
+	"Lockfile.dependencies":    "Root dependencies at the time of locking",
+	"Lockfile.devDependencies": "Root development dependencies at the time of locking",
+	"Lockfile.includeDev":      "Whether development dependencies were resolved and locked",
+	"Lockfile.packages":        "Locked packages ordered by name",
+}
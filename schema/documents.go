@@ -0,0 +1,157 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/criage-oss/criage-common/config"
+	"github.com/criage-oss/criage-common/types"
+)
+
+// BaseURL адрес, по которому публикуются схемы
+const BaseURL = "https://packages.criage.ru/schemas/"
+
+// Имена публикуемых схем
+const (
+	NamePackageManifest = "criage"
+	NameBuildManifest   = "build"
+	NameConfig          = "config"
+	NameServerConfig    = "server-config"
+	NameMCPConfig       = "mcp-config"
+	NameRepositoryIndex = "index"
+	NameLockfile        = "lockfile"
+)
+
+// ErrUnknownSchema схема с таким именем не существует
+var ErrUnknownSchema = errors.New("unknown schema")
+
+// document публикуемая схема
+type document struct {
+	name  string
+	title string
+	value any
+}
+
+// documents публикуемые схемы в порядке перечисления
+var documents = []document{
+	{NamePackageManifest, "criage.yaml", types.PackageManifest{}},
+	{NameBuildManifest, "Criage build manifest", types.BuildManifest{}},
+	{NameConfig, "Criage configuration", config.Config{}},
+	{NameServerConfig, "Criage repository server configuration", config.ServerConfig{}},
+	{NameMCPConfig, "Criage MCP server configuration", config.MCPConfig{}},
+	{NameRepositoryIndex, "Criage repository index", types.RepositoryIndex{}},
+	{NameLockfile, "criage.lock", types.Lockfile{}},
+}
+
+// Names возвращает имена всех публикуемых схем
+func Names() []string {
+	names := make([]string, len(documents))
+	for i, doc := range documents {
+		names[i] = doc.name
+	}
+	return names
+}
+
+// Filename возвращает имя файла схемы, например "criage.schema.json"
+func Filename(name string) string {
+	return name + ".schema.json"
+}
+
+// Get возвращает схему по имени
+func Get(name string) (*Schema, error) {
+	for _, doc := range documents {
+		if doc.name == name {
+			s := Generate(doc.value)
+			s.ID = BaseURL + Filename(doc.name)
+			s.Title = doc.title
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownSchema, name)
+}
+
+// PackageManifest возвращает схему criage.yaml
+func PackageManifest() *Schema {
+	return mustGet(NamePackageManifest)
+}
+
+// BuildManifest возвращает схему манифеста сборки
+func BuildManifest() *Schema {
+	return mustGet(NameBuildManifest)
+}
+
+// Config возвращает схему конфигурации клиента
+func Config() *Schema {
+	return mustGet(NameConfig)
+}
+
+// RepositoryIndex возвращает схему индекса репозитория
+func RepositoryIndex() *Schema {
+	return mustGet(NameRepositoryIndex)
+}
+
+// All возвращает все публикуемые схемы по имени
+func All() map[string]*Schema {
+	all := make(map[string]*Schema, len(documents))
+	for _, doc := range documents {
+		all[doc.name] = mustGet(doc.name)
+	}
+	return all
+}
+
+// WriteAll записывает все схемы в директорию dir в виде <name>.schema.json
+func WriteAll(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create schema directory: %w", err)
+	}
+
+	for _, name := range Names() {
+		data, err := mustGet(name).JSON()
+		if err != nil {
+			return fmt.Errorf("failed to encode schema %s: %w", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, Filename(name)), data, 0644); err != nil {
+			return fmt.Errorf("failed to write schema %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Handler отдает схемы по путям вида /<name>.schema.json
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := strings.CutSuffix(path.Base(r.URL.Path), ".schema.json")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		s, err := Get(name)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		data, err := s.JSON()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/schema+json")
+		w.Write(data)
+	})
+}
+
+// mustGet возвращает схему из списка documents
+func mustGet(name string) *Schema {
+	s, err := Get(name)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
@@ -0,0 +1,148 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// generator строит схему по типу, складывая именованные структуры в $defs
+type generator struct {
+	defs map[string]*Schema
+}
+
+// Generate строит JSON Schema для значения v (структуры или указателя на нее)
+func Generate(v any) *Schema {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	g := &generator{defs: make(map[string]*Schema)}
+	var root *Schema
+	if t.Kind() == reflect.Struct {
+		root = g.structSchema(t)
+	} else {
+		root = g.typeSchema(t)
+	}
+
+	root.Schema = Draft
+	if len(g.defs) > 0 {
+		root.Defs = g.defs
+	}
+	return root
+}
+
+// typeSchema возвращает схему для типа поля
+func (g *generator) typeSchema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if override, ok := typeRules[t]; ok {
+		return override()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: floatPtr(0)}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.typeSchema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.typeSchema(t.Elem())}
+	case reflect.Struct:
+		return g.refSchema(t)
+	default:
+		// interface{} и прочие типы допускают любое значение
+		return &Schema{}
+	}
+}
+
+// refSchema выносит именованную структуру в $defs и возвращает ссылку на нее
+func (g *generator) refSchema(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		return g.structSchema(t)
+	}
+
+	name := t.Name()
+	if _, ok := g.defs[name]; !ok {
+		// Заглушка защищает от бесконечной рекурсии на циклических типах
+		g.defs[name] = &Schema{}
+		*g.defs[name] = *g.structSchema(t)
+	}
+	return &Schema{Ref: "#/$defs/" + name}
+}
+
+// structSchema строит схему объекта по экспортируемым полям структуры
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:        "object",
+		Description: typeDescriptions[t.Name()],
+		Properties:  make(map[string]*Schema),
+	}
+	g.addFields(s, t)
+	s.Required = requiredFields[t.Name()]
+	return s
+}
+
+// addFields добавляет поля структуры, включая поля встроенных структур
+func (g *generator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, ok := jsonName(field)
+		if !ok {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addFields(s, embedded)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := g.typeSchema(field.Type)
+		key := t.Name() + "." + name
+		if description, ok := fieldDescriptions[key]; ok {
+			property.Description = description
+		}
+		if rule, ok := fieldRules[key]; ok {
+			rule(property)
+		}
+		s.Properties[name] = property
+	}
+}
+
+// jsonName возвращает имя поля из тега json; false - поле не сериализуется
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	return name, true
+}
+
+// timeType тип времени, сериализуемый как строка RFC 3339
+var timeType = reflect.TypeOf(time.Time{})
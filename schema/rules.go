@@ -0,0 +1,208 @@
+package schema
+
+import (
+	"reflect"
+
+	"github.com/criage-oss/criage-common/config"
+	"github.com/criage-oss/criage-common/platform"
+	"github.com/criage-oss/criage-common/types"
+)
+
+// Шаблоны строковых значений
+const (
+	// versionPattern строгая версия semver 2.0.0
+	versionPattern = `^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(-[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?(\+[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?$`
+	// looseVersionPattern версия с необязательными префиксом "v" и компонентами
+	looseVersionPattern = `^v?\d+(\.\d+){0,2}(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`
+	// providesPattern виртуальный пакет "name" или "name@1.2.3"
+	providesPattern = `^[^@\s][^@\s]*(@v?\d+(\.\d+){0,2}(-[0-9A-Za-z.-]+)?)?$`
+)
+
+// typeRules схемы для типов со специальным представлением
+var typeRules = map[reflect.Type]func() *Schema{
+	timeType: func() *Schema {
+		return &Schema{Type: "string", Format: "date-time"}
+	},
+	reflect.TypeOf(types.ArchiveFormat("")): func() *Schema {
+		return &Schema{Type: "string", Enum: archiveFormats()}
+	},
+	reflect.TypeOf(types.TrustMode("")): func() *Schema {
+		return &Schema{Type: "string", Enum: []any{
+			string(types.TrustModeStrict), string(types.TrustModeTOFU), string(types.TrustModeOff),
+		}}
+	},
+}
+
+// archiveFormats перечисление форматов архивов
+func archiveFormats() []any {
+	formats := types.ArchiveFormats()
+	values := make([]any, len(formats))
+	for i, format := range formats {
+		values[i] = string(format)
+	}
+	return values
+}
+
+// Правила для отдельных полей
+func packageName(s *Schema) {
+	s.Pattern = types.PackageNamePattern
+	s.MinLength = intPtr(1)
+	s.MaxLength = intPtr(types.MaxPackageNameLength)
+}
+
+func semverVersion(s *Schema) {
+	s.Pattern = versionPattern
+	s.Examples = []any{"1.0.0", "2.1.0-beta.1"}
+}
+
+func looseVersion(s *Schema) {
+	s.Pattern = looseVersionPattern
+}
+
+func uri(s *Schema) {
+	s.Format = "uri"
+}
+
+func constraints(s *Schema) {
+	s.AdditionalProperties.Examples = []any{"^1.2.0", "~1.4", ">=2.0.0 <3.0.0", "*"}
+}
+
+func formatEnum(s *Schema) {
+	s.Enum = archiveFormats()
+}
+
+func compressionLevel(s *Schema) {
+	s.Minimum = floatPtr(0)
+	s.Maximum = floatPtr(22)
+}
+
+func configVersion(s *Schema) {
+	s.Minimum = floatPtr(config.LegacyVersion)
+	s.Maximum = floatPtr(config.CurrentVersion)
+}
+
+func logLevel(s *Schema) {
+	for _, level := range config.LogLevels {
+		s.Enum = append(s.Enum, level)
+	}
+}
+
+func positive(s *Schema) {
+	s.Minimum = floatPtr(1)
+}
+
+func nonNegative(s *Schema) {
+	s.Minimum = floatPtr(0)
+}
+
+func osList(s *Schema) {
+	s.Items.Examples = []any{"linux", "darwin", "windows", "!windows", "*"}
+}
+
+func archList(s *Schema) {
+	s.Items.Examples = []any{"amd64", "arm64", "x86_64_v3", "!386"}
+}
+
+// fieldRules ограничения полей по ключу "Тип.поле"
+var fieldRules = map[string]func(*Schema){
+	"PackageManifest.name":                 packageName,
+	"PackageManifest.version":              semverVersion,
+	"PackageManifest.homepage":             uri,
+	"PackageManifest.repository":           uri,
+	"PackageManifest.dependencies":         constraints,
+	"PackageManifest.devDependencies":      constraints,
+	"PackageManifest.optionalDependencies": constraints,
+	"PackageManifest.peerDependencies":     constraints,
+	"PackageManifest.conflicts":            constraints,
+	"PackageManifest.replaces":             constraints,
+	"PackageManifest.provides": func(s *Schema) {
+		s.Items.Pattern = providesPattern
+		s.UniqueItems = true
+	},
+	"PackageManifest.keywords":   func(s *Schema) { s.UniqueItems = true },
+	"PackageManifest.os":         osList,
+	"PackageManifest.arch":       archList,
+	"PackageManifest.minVersion": looseVersion,
+
+	"BuildManifest.name":    packageName,
+	"BuildManifest.version": semverVersion,
+	"BuildManifest.environment": func(s *Schema) {
+		s.AdditionalProperties.Description = "Value of the environment variable"
+	},
+	"CompressionConfig.format": formatEnum,
+	"CompressionConfig.level":  compressionLevel,
+	"BuildTarget.os":           func(s *Schema) { s.Examples = []any{"linux", "darwin", "windows"} },
+	"BuildTarget.arch":         func(s *Schema) { s.Examples = []any{"amd64", "arm64", "arm"} },
+	"BuildTarget.libc":         func(s *Schema) { s.Enum = []any{platform.LibcGlibc, platform.LibcMusl} },
+	"BuildTarget.variant":      func(s *Schema) { s.Enum = []any{"v1", "v2", "v3", "v4", "v5", "v6", "v7", "v8", "v9"} },
+
+	"Config.configVersion":    configVersion,
+	"Config.timeout":          nonNegative,
+	"Config.maxConnections":   positive,
+	"Config.compressionLevel": compressionLevel,
+	"Config.preferredFormat":  formatEnum,
+	"Config.maxParallel":      positive,
+
+	"NetworkConfig.retries": nonNegative,
+	"NetworkConfig.noProxy": func(s *Schema) {
+		s.Items.Examples = []any{"localhost", ".corp.example.com", "10.0.0.0/8", "registry.local:8443"}
+	},
+
+	"Profile.timeout":        nonNegative,
+	"Profile.maxConnections": positive,
+
+	"ProfileNetwork.retries": nonNegative,
+
+	"Repository.name":          func(s *Schema) { s.MinLength = intPtr(1) },
+	"Repository.url":           uri,
+	"Repository.revocationUrl": uri,
+	"Repository.timeout":       nonNegative,
+	"Repository.mirrors":       func(s *Schema) { s.UniqueItems = true },
+	"Mirror.url":               uri,
+	"Repository.retries":       nonNegative,
+
+	"ServerConfig.configVersion": configVersion,
+	"ServerConfig.port": func(s *Schema) {
+		s.Minimum = floatPtr(1)
+		s.Maximum = floatPtr(65535)
+	},
+	"ServerConfig.maxFileSize": positive,
+	"ServerConfig.rateLimit":   nonNegative,
+	"ServerConfig.logLevel":    logLevel,
+	"ServerConfig.allowedFormats": func(s *Schema) {
+		s.Items.Enum = append(archiveFormats(), "criage")
+	},
+
+	"MCPConfig.configVersion":  configVersion,
+	"MCPConfig.maxConcurrency": positive,
+	"MCPConfig.timeout":        nonNegative,
+	"MCPConfig.logLevel":       logLevel,
+
+	"PackageEntry.name":          packageName,
+	"PackageEntry.latestVersion": semverVersion,
+	"VersionEntry.version":       semverVersion,
+	"VersionEntry.dependencies":  constraints,
+	"FileEntry.size":             nonNegative,
+	"FileEntry.libc":             func(s *Schema) { s.Enum = []any{platform.LibcGlibc, platform.LibcMusl} },
+
+	"Lockfile.lockfileVersion": func(s *Schema) { s.Enum = []any{types.LockfileVersion} },
+	"LockedPackage.name":       packageName,
+	"LockedPackage.version":    semverVersion,
+}
+
+// requiredFields обязательные поля объектов
+var requiredFields = map[string][]string{
+	"PackageManifest":   {"name", "version"},
+	"BuildManifest":     {"name", "version", "compression"},
+	"CompressionConfig": {"format"},
+	"BuildTarget":       {"os", "arch"},
+	"Repository":        {"name", "url"},
+	"Mirror":            {"url"},
+	"PackageEntry":      {"name", "versions"},
+	"VersionEntry":      {"version"},
+	"FileEntry":         {"os", "arch", "format", "filename", "checksum"},
+	"DeltaEntry":        {"fromVersion", "filename", "checksum"},
+	"RepositoryIndex":   {"packages"},
+	"Lockfile":          {"lockfileVersion", "packages"},
+	"LockedPackage":     {"name", "version"},
+}
@@ -0,0 +1,64 @@
+// Package schema генерирует JSON Schema для форматов criage (criage.yaml,
+// манифест сборки, конфигурации, индекс репозитория, criage.lock) из Go-типов,
+// чтобы автодополнение в редакторах и внешние валидаторы не расходились с кодом.
+package schema
+
+import (
+	"encoding/json"
+)
+
+// Draft версия спецификации JSON Schema
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema документ или узел JSON Schema
+type Schema struct {
+	Schema      string `json:"$schema,omitempty"`
+	ID          string `json:"$id,omitempty"`
+	Ref         string `json:"$ref,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	Type   string `json:"type,omitempty"`
+	Format string `json:"format,omitempty"`
+	Enum   []any  `json:"enum,omitempty"`
+
+	// Строки
+	Pattern   string `json:"pattern,omitempty"`
+	MinLength *int   `json:"minLength,omitempty"`
+	MaxLength *int   `json:"maxLength,omitempty"`
+
+	// Числа
+	Minimum *float64 `json:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty"`
+
+	// Массивы
+	Items       *Schema `json:"items,omitempty"`
+	UniqueItems bool    `json:"uniqueItems,omitempty"`
+
+	// Объекты
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+
+	Examples []any              `json:"examples,omitempty"`
+	Defs     map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// JSON возвращает документ в виде JSON с отступами
+func (s *Schema) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// intPtr возвращает указатель на значение для ограничений длины
+func intPtr(v int) *int {
+	return &v
+}
+
+// floatPtr возвращает указатель на значение для числовых ограничений
+func floatPtr(v float64) *float64 {
+	return &v
+}
@@ -0,0 +1,187 @@
+package schema
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// documentFields собирает имена полей всех структур, достижимых из публикуемых схем,
+// в виде ключей "Тип.поле"
+func documentFields() (types map[string]bool, fields map[string]bool) {
+	types = make(map[string]bool)
+	fields = make(map[string]bool)
+
+	var walk func(t reflect.Type, owner string)
+	walk = func(t reflect.Type, owner string) {
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || t == timeType {
+			return
+		}
+		if owner == "" {
+			owner = t.Name()
+			if types[owner] {
+				return
+			}
+			types[owner] = true
+		}
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, ok := jsonName(field)
+			if !field.IsExported() || !ok {
+				continue
+			}
+			if field.Anonymous && name == "" {
+				// Поля встроенной структуры принадлежат внешнему типу
+				walk(field.Type, owner)
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			fields[owner+"."+name] = true
+			walk(field.Type, "")
+		}
+	}
+
+	for _, doc := range documents {
+		walk(reflect.TypeOf(doc.value), "")
+	}
+	return types, fields
+}
+
+func TestRulesMatchFields(t *testing.T) {
+	types, fields := documentFields()
+
+	for key := range fieldRules {
+		if !fields[key] {
+			t.Errorf("fieldRules: %s is not a field of any published schema", key)
+		}
+	}
+	for key := range fieldDescriptions {
+		if !fields[key] {
+			t.Errorf("fieldDescriptions: %s is not a field of any published schema", key)
+		}
+	}
+	for name := range typeDescriptions {
+		if !types[name] {
+			t.Errorf("typeDescriptions: %s is not used by any published schema", name)
+		}
+	}
+	for name, required := range requiredFields {
+		if !types[name] {
+			t.Errorf("requiredFields: %s is not used by any published schema", name)
+		}
+		for _, field := range required {
+			if !fields[name+"."+field] {
+				t.Errorf("requiredFields: %s.%s is not a field", name, field)
+			}
+		}
+	}
+}
+
+func TestGenerateAllDocuments(t *testing.T) {
+	all := All()
+	if len(all) != len(Names()) {
+		t.Fatalf("All() returned %d schemas, want %d", len(all), len(Names()))
+	}
+
+	for _, name := range Names() {
+		s := all[name]
+		if s.ID != BaseURL+Filename(name) || s.Title == "" || s.Schema != Draft {
+			t.Errorf("%s: id=%q title=%q $schema=%q", name, s.ID, s.Title, s.Schema)
+		}
+		if s.Type != "object" || len(s.Properties) == 0 {
+			t.Errorf("%s: schema has no properties", name)
+		}
+
+		data, err := s.JSON()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var decoded map[string]any
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("%s: invalid JSON: %v", name, err)
+		}
+
+		// Все ссылки ведут на определения из $defs
+		for _, ref := range refs(decoded) {
+			def, ok := strings.CutPrefix(ref, "#/$defs/")
+			if !ok || s.Defs[def] == nil {
+				t.Errorf("%s: dangling reference %s", name, ref)
+			}
+		}
+	}
+
+	if _, err := Get("unknown"); err == nil {
+		t.Error("Get() of an unknown schema succeeded")
+	}
+}
+
+// refs возвращает все значения $ref в декодированной схеме
+func refs(value any) []string {
+	var result []string
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if ref, ok := item.(string); ok && key == "$ref" {
+				result = append(result, ref)
+				continue
+			}
+			result = append(result, refs(item)...)
+		}
+	case []any:
+		for _, item := range v {
+			result = append(result, refs(item)...)
+		}
+	}
+	return result
+}
+
+func TestWriteAll(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "schemas")
+	if err := WriteAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range Names() {
+		if _, err := os.Stat(filepath.Join(dir, Filename(name))); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	server := httptest.NewServer(Handler())
+	defer server.Close()
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/schemas/criage.schema.json", http.StatusOK},
+		{"/config.schema.json", http.StatusOK},
+		{"/unknown.schema.json", http.StatusNotFound},
+		{"/criage.json", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(server.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("GET %s = %d, want %d", tt.path, resp.StatusCode, tt.status)
+		}
+		if tt.status == http.StatusOK && resp.Header.Get("Content-Type") != "application/schema+json" {
+			t.Errorf("GET %s Content-Type = %q", tt.path, resp.Header.Get("Content-Type"))
+		}
+	}
+}
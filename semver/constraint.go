@@ -0,0 +1,516 @@
+package semver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidConstraint строка не является корректным ограничением версии
+var ErrInvalidConstraint = errors.New("invalid constraint")
+
+// operator оператор сравнения версий
+type operator int
+
+const (
+	opEQ operator = iota
+	opNEQ
+	opGT
+	opGTE
+	opLT
+	opLTE
+)
+
+var operatorSymbols = map[operator]string{
+	opEQ:  "=",
+	opNEQ: "!=",
+	opGT:  ">",
+	opGTE: ">=",
+	opLT:  "<",
+	opLTE: "<=",
+}
+
+// comparator элементарное сравнение с версией
+type comparator struct {
+	op      operator
+	version *Version
+	// synthetic граница "-0", построенная при разборе неполной версии или
+	// диапазона; она не считается явным упоминанием pre-release версии
+	synthetic bool
+}
+
+// bound возвращает сгенерированную границу диапазона
+func bound(op operator, version *Version) comparator {
+	return comparator{op: op, version: version, synthetic: true}
+}
+
+// matches проверяет версию по элементарному сравнению
+func (c comparator) matches(v *Version) bool {
+	cmp := v.Compare(c.version)
+	switch c.op {
+	case opEQ:
+		return cmp == 0
+	case opNEQ:
+		return cmp != 0
+	case opGT:
+		return cmp > 0
+	case opGTE:
+		return cmp >= 0
+	case opLT:
+		return cmp < 0
+	case opLTE:
+		return cmp <= 0
+	}
+	return false
+}
+
+func (c comparator) String() string {
+	return operatorSymbols[c.op] + c.version.String()
+}
+
+// comparatorSet набор сравнений, которые должны выполняться одновременно
+type comparatorSet []comparator
+
+// Constraint ограничение версии, например "^1.2.0", ">=1.0 <2.0 || 3.x".
+//
+// Поддерживаются операторы =, !=, >, >=, <, <=, ^ (совместимые версии),
+// ~ и ~> (патч-обновления), диапазоны "1.2 - 2.0", подстановки "1.x", "1.2.*", "*"
+// и объединения через "||". Pre-release версии подходят только если в том же
+// наборе сравнений явно указана pre-release версия с тем же MAJOR.MINOR.PATCH,
+// либо если включен IncludePrerelease.
+type Constraint struct {
+	// IncludePrerelease разрешает любые pre-release версии, попадающие в диапазон
+	IncludePrerelease bool
+
+	sets     []comparatorSet
+	original string
+}
+
+// ParseConstraint разбирает ограничение версии
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{original: s}
+
+	for _, part := range strings.Split(s, "||") {
+		set, err := parseComparatorSet(part)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrInvalidConstraint, s, err)
+		}
+		c.sets = append(c.sets, set)
+	}
+
+	return c, nil
+}
+
+// MustParseConstraint разбирает ограничение и паникует при ошибке
+func MustParseConstraint(s string) *Constraint {
+	c, err := ParseConstraint(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Satisfies мягко разбирает версию и проверяет ее по ограничению
+func Satisfies(version, constraint string) (bool, error) {
+	v, err := ParseTolerant(version)
+	if err != nil {
+		return false, err
+	}
+	c, err := ParseConstraint(constraint)
+	if err != nil {
+		return false, err
+	}
+	return c.Check(v), nil
+}
+
+// String возвращает исходную строку ограничения
+func (c *Constraint) String() string {
+	return c.original
+}
+
+// Normalized возвращает ограничение, развернутое в элементарные сравнения
+func (c *Constraint) Normalized() string {
+	sets := make([]string, 0, len(c.sets))
+	for _, set := range c.sets {
+		if len(set) == 0 {
+			sets = append(sets, "*")
+			continue
+		}
+		parts := make([]string, 0, len(set))
+		for _, cmp := range set {
+			parts = append(parts, cmp.String())
+		}
+		sets = append(sets, strings.Join(parts, " "))
+	}
+	return strings.Join(sets, " || ")
+}
+
+// Check проверяет, удовлетворяет ли версия ограничению
+func (c *Constraint) Check(v *Version) bool {
+	for _, set := range c.sets {
+		if c.checkSet(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckString мягко разбирает версию и проверяет ее по ограничению.
+// Некорректная версия ограничению не удовлетворяет.
+func (c *Constraint) CheckString(version string) bool {
+	v, err := ParseTolerant(version)
+	if err != nil {
+		return false
+	}
+	return c.Check(v)
+}
+
+// Filter возвращает версии, удовлетворяющие ограничению, в исходном порядке
+func (c *Constraint) Filter(versions []*Version) []*Version {
+	var matched []*Version
+	for _, v := range versions {
+		if c.Check(v) {
+			matched = append(matched, v)
+		}
+	}
+	return matched
+}
+
+// Max возвращает наибольшую из версий, удовлетворяющих ограничению
+func (c *Constraint) Max(versions []*Version) (*Version, error) {
+	var best *Version
+	for _, v := range c.Filter(versions) {
+		if best == nil || less(best, v) {
+			best = v
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("%w for constraint %q", ErrNoVersions, c.original)
+	}
+	return best, nil
+}
+
+// checkSet проверяет версию по набору сравнений с учетом правила pre-release
+func (c *Constraint) checkSet(set comparatorSet, v *Version) bool {
+	for _, cmp := range set {
+		if !cmp.matches(v) {
+			return false
+		}
+	}
+
+	if !v.IsPrerelease() || c.IncludePrerelease {
+		return true
+	}
+
+	// Pre-release допускается только при явном упоминании того же выпуска
+	for _, cmp := range set {
+		if !cmp.synthetic && cmp.version.IsPrerelease() && cmp.version.Core().Equal(v.Core()) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseComparatorSet разбирает набор сравнений, разделенных пробелами или запятыми
+func parseComparatorSet(s string) (comparatorSet, error) {
+	tokens := tokenize(s)
+	if len(tokens) == 0 {
+		return nil, errors.New("empty range, use \"*\" to match any version")
+	}
+
+	// Диапазон через дефис: "1.2.3 - 2.3.4"
+	if len(tokens) == 3 && tokens[1] == "-" {
+		return parseHyphenRange(tokens[0], tokens[2])
+	}
+
+	var set comparatorSet
+	for _, token := range tokens {
+		if token == "-" {
+			return nil, errors.New("hyphen range must have the form \"A - B\"")
+		}
+		comparators, err := parseTerm(token)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, comparators...)
+	}
+	return set, nil
+}
+
+// tokenize разбивает набор сравнений на термы, присоединяя оператор к версии (">= 1.2")
+func tokenize(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == ','
+	})
+
+	var tokens []string
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		if isOperator(field) && i+1 < len(fields) {
+			field += fields[i+1]
+			i++
+		}
+		tokens = append(tokens, field)
+	}
+	return tokens
+}
+
+// isOperator проверяет, состоит ли строка только из оператора
+func isOperator(s string) bool {
+	switch s {
+	case "=", "!=", ">", ">=", "<", "<=", "^", "~", "~>":
+		return true
+	}
+	return false
+}
+
+// parseTerm разбирает один терм в элементарные сравнения
+func parseTerm(term string) ([]comparator, error) {
+	prefix, rest := splitOperator(term)
+	if rest == "" {
+		return nil, fmt.Errorf("term %q: missing version", term)
+	}
+
+	p, err := parsePartial(rest)
+	if err != nil {
+		return nil, fmt.Errorf("term %q: %v", term, err)
+	}
+
+	switch prefix {
+	case "^":
+		return caretRange(p), nil
+	case "~", "~>":
+		return tildeRange(p), nil
+	case "", "=":
+		return exactRange(p), nil
+	case "!=":
+		if p.wildcard() {
+			return nil, fmt.Errorf("term %q: wildcards are not supported with !=", term)
+		}
+		return []comparator{{op: opNEQ, version: p.version()}}, nil
+	case ">":
+		if p.any() {
+			// ">*" не может выполниться ни для одной версии
+			return []comparator{bound(opLT, New(0, 0, 0).withPrerelease("0"))}, nil
+		}
+		if p.wildcard() {
+			return []comparator{bound(opGTE, p.upper())}, nil
+		}
+		return []comparator{{op: opGT, version: p.version()}}, nil
+	case ">=":
+		return []comparator{{op: opGTE, version: p.version()}}, nil
+	case "<":
+		if p.wildcard() {
+			return []comparator{bound(opLT, p.version().withPrerelease("0"))}, nil
+		}
+		return []comparator{{op: opLT, version: p.version()}}, nil
+	case "<=":
+		if p.any() {
+			return nil, nil
+		}
+		if p.wildcard() {
+			return []comparator{bound(opLT, p.upper())}, nil
+		}
+		return []comparator{{op: opLTE, version: p.version()}}, nil
+	}
+
+	return nil, fmt.Errorf("term %q: unknown operator %q", term, prefix)
+}
+
+// splitOperator отделяет оператор от версии
+func splitOperator(term string) (string, string) {
+	for _, op := range []string{"~>", ">=", "<=", "!=", "^", "~", ">", "<", "="} {
+		if strings.HasPrefix(term, op) {
+			return op, strings.TrimSpace(term[len(op):])
+		}
+	}
+	return "", term
+}
+
+// parseHyphenRange разбирает диапазон "A - B" с включенными границами
+func parseHyphenRange(from, to string) (comparatorSet, error) {
+	lower, err := parsePartial(from)
+	if err != nil {
+		return nil, fmt.Errorf("range start %q: %v", from, err)
+	}
+	upper, err := parsePartial(to)
+	if err != nil {
+		return nil, fmt.Errorf("range end %q: %v", to, err)
+	}
+
+	var set comparatorSet
+	if !lower.any() {
+		set = append(set, comparator{op: opGTE, version: lower.version()})
+	}
+	switch {
+	case upper.any():
+	case upper.wildcard():
+		set = append(set, bound(opLT, upper.upper()))
+	default:
+		set = append(set, comparator{op: opLTE, version: upper.version()})
+	}
+
+	if len(set) == 2 && set[0].version.GreaterThan(set[1].version) {
+		return nil, fmt.Errorf("range %s - %s is empty: start is greater than end", from, to)
+	}
+	return set, nil
+}
+
+// partial версия с возможно опущенными или подстановочными компонентами
+type partial struct {
+	major, minor, patch *uint64
+	prerelease          []string
+	build               []string
+}
+
+// parsePartial разбирает версию вида "1", "1.2", "1.x", "1.2.*", "1.2.3-rc.1"
+func parsePartial(s string) (*partial, error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "v"), "V")
+	p := &partial{}
+
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		build, err := parseIdentifiers(s[i+1:], false)
+		if err != nil {
+			return nil, fmt.Errorf("build metadata: %v", err)
+		}
+		p.build = build
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		prerelease, err := parseIdentifiers(s[i+1:], true)
+		if err != nil {
+			return nil, fmt.Errorf("pre-release: %v", err)
+		}
+		p.prerelease = prerelease
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return nil, errors.New("expected at most MAJOR.MINOR.PATCH")
+	}
+
+	components := []**uint64{&p.major, &p.minor, &p.patch}
+	seenWildcard := false
+	for i, part := range parts {
+		if part == "x" || part == "X" || part == "*" {
+			seenWildcard = true
+			continue
+		}
+		if seenWildcard {
+			return nil, fmt.Errorf("%s component %q follows a wildcard", componentNames[i], part)
+		}
+		n, err := parseNumber(part, false)
+		if err != nil {
+			return nil, fmt.Errorf("%s component: %v", componentNames[i], err)
+		}
+		*components[i] = &n
+	}
+
+	if len(p.prerelease) > 0 && p.wildcard() {
+		return nil, errors.New("pre-release requires a full MAJOR.MINOR.PATCH version")
+	}
+	return p, nil
+}
+
+// any проверяет, что версия не ограничена ("*")
+func (p *partial) any() bool {
+	return p.major == nil
+}
+
+// wildcard проверяет, что хотя бы один компонент опущен
+func (p *partial) wildcard() bool {
+	return p.patch == nil
+}
+
+// version возвращает нижнюю границу, заменяя опущенные компоненты нулями
+func (p *partial) version() *Version {
+	v := New(value(p.major), value(p.minor), value(p.patch))
+	v.Prerelease = p.prerelease
+	v.Build = p.build
+	return v
+}
+
+// upper возвращает исключающую верхнюю границу неполной версии ("1.2" -> "1.3.0-0")
+func (p *partial) upper() *Version {
+	switch {
+	case p.minor == nil:
+		return New(value(p.major)+1, 0, 0).withPrerelease("0")
+	default:
+		return New(value(p.major), value(p.minor)+1, 0).withPrerelease("0")
+	}
+}
+
+// value возвращает значение компонента или 0
+func value(n *uint64) uint64 {
+	if n == nil {
+		return 0
+	}
+	return *n
+}
+
+// withPrerelease возвращает копию версии с pre-release идентификаторами
+func (v *Version) withPrerelease(identifiers ...string) *Version {
+	c := *v
+	c.Prerelease = identifiers
+	return &c
+}
+
+// exactRange разворачивает "1.2.3", "=1.2" или "1.x"
+func exactRange(p *partial) []comparator {
+	switch {
+	case p.any():
+		return nil
+	case p.wildcard():
+		return []comparator{
+			{op: opGTE, version: p.version()},
+			bound(opLT, p.upper()),
+		}
+	default:
+		return []comparator{{op: opEQ, version: p.version()}}
+	}
+}
+
+// caretRange разворачивает "^": изменения, не затрагивающие первый ненулевой компонент
+func caretRange(p *partial) []comparator {
+	if p.any() {
+		return nil
+	}
+
+	lower := p.version()
+	major, minor, patch := value(p.major), value(p.minor), value(p.patch)
+
+	var upper *Version
+	switch {
+	case major > 0 || p.minor == nil:
+		upper = New(major+1, 0, 0)
+	case minor > 0 || p.patch == nil:
+		upper = New(0, minor+1, 0)
+	default:
+		upper = New(0, 0, patch+1)
+	}
+
+	return []comparator{
+		{op: opGTE, version: lower},
+		bound(opLT, upper.withPrerelease("0")),
+	}
+}
+
+// tildeRange разворачивает "~": патч-обновления, либо минорные при указании только MAJOR
+func tildeRange(p *partial) []comparator {
+	if p.any() {
+		return nil
+	}
+
+	var upper *Version
+	if p.minor == nil {
+		upper = New(value(p.major)+1, 0, 0)
+	} else {
+		upper = New(value(p.major), value(p.minor)+1, 0)
+	}
+
+	return []comparator{
+		{op: opGTE, version: p.version()},
+		bound(opLT, upper.withPrerelease("0")),
+	}
+}
@@ -0,0 +1,92 @@
+package semver
+
+import "testing"
+
+func TestConstraintPrerelease(t *testing.T) {
+	tests := []struct {
+		version    string
+		constraint string
+		want       bool
+	}{
+		// Синтетические границы "-0" не разрешают pre-release версии
+		{"2.0.0-rc.1", ">1.x", false},
+		{"1.3.0-beta", ">1.2", false},
+		{"1.3.0", ">1.2", true},
+		{"1.2.5", ">1.2", false},
+		{"1.5.0-alpha", "1.x", false},
+		{"1.5.0", "1.x", true},
+		{"1.2.4-beta", "~1.2.3", false},
+		{"1.3.0-0", "~1.2", false},
+		{"1.9.0-rc.1", "^1.2.0", false},
+		{"2.0.0-0", "^1.2.0", false},
+		{"1.9.0", "^1.2.0", true},
+		{"1.1.0-beta", "<1.2", false},
+		{"1.1.0-beta", "<=1.x", false},
+		{"1.5.0-beta", "1.0 - 1.x", false},
+		{"0.0.0-0", ">*", false},
+
+		// Явно указанная pre-release версия разрешает тот же выпуск
+		{"1.2.3-beta.2", "^1.2.3-beta.1", true},
+		{"1.2.4-beta.2", "^1.2.3-beta.1", false},
+		{"1.2.3-rc.1", "~1.2.3-beta", true},
+		{"1.2.3-rc.1", ">1.2.3-beta", true},
+		{"1.2.3-rc.1", ">=1.2.3-beta <1.3", true},
+		{"1.2.3-rc.1", "<1.2.3-rc.2", true},
+		{"1.2.3-rc.1", ">=1.2.3-rc.0 <1.2.3-rc.2", true},
+		{"2.0.0-rc.1", "<2.0.0-rc.2", true},
+		{"2.0.0-rc.1", "<2.0.0", false},
+	}
+
+	for _, tt := range tests {
+		got, err := Satisfies(tt.version, tt.constraint)
+		if err != nil {
+			t.Errorf("%s %s: %v", tt.version, tt.constraint, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Satisfies(%q, %q) = %v, want %v", tt.version, tt.constraint, got, tt.want)
+		}
+	}
+}
+
+func TestConstraintIncludePrerelease(t *testing.T) {
+	tests := []struct {
+		version    string
+		constraint string
+		want       bool
+	}{
+		{"2.0.0-rc.1", ">1.x", true},
+		{"1.3.0-beta", ">1.2", true},
+		{"1.9.0-rc.1", "^1.2.0", true},
+		{"2.0.0-0", "^1.2.0", false},
+		{"1.3.0-0", "~1.2", false},
+		{"1.2.0-beta", "1.2.x", false},
+	}
+
+	for _, tt := range tests {
+		c := MustParseConstraint(tt.constraint)
+		c.IncludePrerelease = true
+		if got := c.CheckString(tt.version); got != tt.want {
+			t.Errorf("%q with IncludePrerelease: Check(%q) = %v, want %v", tt.constraint, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestConstraintNormalized(t *testing.T) {
+	tests := []struct {
+		constraint string
+		want       string
+	}{
+		{"^1.2.3", ">=1.2.3 <2.0.0-0"},
+		{"~1.2", ">=1.2.0 <1.3.0-0"},
+		{">1.x", ">=2.0.0-0"},
+		{"1.x || 3.0.0", ">=1.0.0 <2.0.0-0 || =3.0.0"},
+		{"*", "*"},
+	}
+
+	for _, tt := range tests {
+		if got := MustParseConstraint(tt.constraint).Normalized(); got != tt.want {
+			t.Errorf("Normalized(%q) = %q, want %q", tt.constraint, got, tt.want)
+		}
+	}
+}
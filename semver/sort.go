@@ -0,0 +1,111 @@
+package semver
+
+import (
+	"sort"
+	"strings"
+)
+
+// Collection список версий, упорядочиваемый по возрастанию
+type Collection []*Version
+
+func (c Collection) Len() int      { return len(c) }
+func (c Collection) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+
+// Less задает полный порядок: приоритет SemVer, затем метаданные сборки,
+// чтобы версии, различающиеся только сборкой, сортировались детерминированно
+func (c Collection) Less(i, j int) bool {
+	return less(c[i], c[j])
+}
+
+// less сравнивает версии с учетом метаданных сборки
+func less(a, b *Version) bool {
+	if cmp := a.Compare(b); cmp != 0 {
+		return cmp < 0
+	}
+	return strings.Join(a.Build, ".") < strings.Join(b.Build, ".")
+}
+
+// Sort сортирует версии по возрастанию
+func Sort(versions []*Version) {
+	sort.Sort(Collection(versions))
+}
+
+// Compare мягко разбирает и сравнивает две версии: -1, 0 или 1
+func Compare(a, b string) (int, error) {
+	va, err := ParseTolerant(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := ParseTolerant(b)
+	if err != nil {
+		return 0, err
+	}
+	return va.Compare(vb), nil
+}
+
+// ParseAll мягко разбирает список версий, пропуская некорректные строки.
+// Некорректные строки возвращаются вторым значением.
+func ParseAll(values []string) ([]*Version, []string) {
+	versions := make([]*Version, 0, len(values))
+	var invalid []string
+	for _, value := range values {
+		v, err := ParseTolerant(value)
+		if err != nil {
+			invalid = append(invalid, value)
+			continue
+		}
+		versions = append(versions, v)
+	}
+	return versions, invalid
+}
+
+// SortStrings сортирует строки версий по возрастанию.
+// Некорректные версии помещаются в начало в лексикографическом порядке.
+func SortStrings(values []string) {
+	sort.SliceStable(values, func(i, j int) bool {
+		vi, errI := ParseTolerant(values[i])
+		vj, errJ := ParseTolerant(values[j])
+		switch {
+		case errI != nil && errJ != nil:
+			return values[i] < values[j]
+		case errI != nil:
+			return true
+		case errJ != nil:
+			return false
+		default:
+			return less(vi, vj)
+		}
+	})
+}
+
+// Latest возвращает наибольшую версию из списка, включая pre-release
+func Latest(values []string) (string, error) {
+	return latest(values, true)
+}
+
+// LatestStable возвращает наибольшую стабильную версию из списка
+func LatestStable(values []string) (string, error) {
+	return latest(values, false)
+}
+
+// latest выбирает наибольшую версию, при необходимости пропуская pre-release
+func latest(values []string, includePrerelease bool) (string, error) {
+	var best *Version
+	for _, value := range values {
+		v, err := ParseTolerant(value)
+		if err != nil {
+			continue
+		}
+		if !includePrerelease && v.IsPrerelease() {
+			continue
+		}
+		if best == nil || less(best, v) {
+			best = v
+		}
+	}
+
+	if best == nil {
+		return "", ErrNoVersions
+	}
+	return best.Original(), nil
+}
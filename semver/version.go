@@ -0,0 +1,318 @@
+// Package semver реализует семантическое версионирование (SemVer 2.0.0):
+// строгий и мягкий разбор версий, сравнение с учетом pre-release,
+// сортировку и выбор последней стабильной версии.
+package semver
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidVersion строка не является корректной версией
+	ErrInvalidVersion = errors.New("invalid version")
+	// ErrNoVersions в списке нет ни одной подходящей версии
+	ErrNoVersions = errors.New("no matching versions")
+)
+
+// Version семантическая версия MAJOR.MINOR.PATCH[-PRERELEASE][+BUILD]
+type Version struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease []string
+	Build      []string
+
+	original string
+}
+
+// Parse строго разбирает версию по спецификации SemVer 2.0.0
+func Parse(s string) (*Version, error) {
+	return parse(s, false)
+}
+
+// ParseTolerant разбирает версию мягко: допускает пробелы, префикс "v" или "=",
+// отсутствующие MINOR и PATCH ("1", "1.2") и ведущие нули в числах
+func ParseTolerant(s string) (*Version, error) {
+	return parse(s, true)
+}
+
+// MustParse разбирает версию строго и паникует при ошибке
+func MustParse(s string) *Version {
+	v, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// New создает стабильную версию из числовых компонентов
+func New(major, minor, patch uint64) *Version {
+	return &Version{Major: major, Minor: minor, Patch: patch}
+}
+
+// parse разбирает версию в строгом или мягком режиме
+func parse(s string, tolerant bool) (*Version, error) {
+	original := s
+	if tolerant {
+		s = strings.TrimSpace(s)
+		s = strings.TrimPrefix(s, "=")
+		s = strings.TrimPrefix(strings.TrimPrefix(s, "v"), "V")
+		s = strings.TrimSpace(s)
+	}
+	if s == "" {
+		return nil, fmt.Errorf("%w %q: empty string", ErrInvalidVersion, original)
+	}
+
+	v := &Version{original: original}
+
+	// Метаданные сборки
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		build, err := parseIdentifiers(s[i+1:], false)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: build metadata: %v", ErrInvalidVersion, original, err)
+		}
+		v.Build = build
+		s = s[:i]
+	}
+
+	// Pre-release
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		prerelease, err := parseIdentifiers(s[i+1:], !tolerant)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: pre-release: %v", ErrInvalidVersion, original, err)
+		}
+		v.Prerelease = prerelease
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 || (!tolerant && len(parts) != 3) {
+		return nil, fmt.Errorf("%w %q: expected MAJOR.MINOR.PATCH", ErrInvalidVersion, original)
+	}
+
+	numbers := [3]uint64{}
+	for i, part := range parts {
+		n, err := parseNumber(part, !tolerant)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %s component: %v", ErrInvalidVersion, original, componentNames[i], err)
+		}
+		numbers[i] = n
+	}
+	v.Major, v.Minor, v.Patch = numbers[0], numbers[1], numbers[2]
+
+	return v, nil
+}
+
+var componentNames = [3]string{"major", "minor", "patch"}
+
+// parseNumber разбирает числовой компонент версии
+func parseNumber(s string, strict bool) (uint64, error) {
+	if s == "" {
+		return 0, errors.New("empty number")
+	}
+	if !isNumeric(s) {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	if strict && len(s) > 1 && s[0] == '0' {
+		return 0, fmt.Errorf("%q has a leading zero", s)
+	}
+
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is out of range", s)
+	}
+	return n, nil
+}
+
+// parseIdentifiers разбирает идентификаторы pre-release или сборки, разделенные точками
+func parseIdentifiers(s string, noLeadingZeros bool) ([]string, error) {
+	if s == "" {
+		return nil, errors.New("empty identifier list")
+	}
+
+	identifiers := strings.Split(s, ".")
+	for _, id := range identifiers {
+		if id == "" {
+			return nil, errors.New("empty identifier")
+		}
+		for _, r := range id {
+			if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '-') {
+				return nil, fmt.Errorf("invalid character %q in %q", r, id)
+			}
+		}
+		if noLeadingZeros && len(id) > 1 && id[0] == '0' && isNumeric(id) {
+			return nil, fmt.Errorf("numeric identifier %q has a leading zero", id)
+		}
+	}
+	return identifiers, nil
+}
+
+// isNumeric проверяет, состоит ли строка только из цифр
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String возвращает каноническое представление версии
+func (v *Version) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		b.WriteByte('-')
+		b.WriteString(strings.Join(v.Prerelease, "."))
+	}
+	if len(v.Build) > 0 {
+		b.WriteByte('+')
+		b.WriteString(strings.Join(v.Build, "."))
+	}
+	return b.String()
+}
+
+// Original возвращает исходную строку, из которой была разобрана версия
+func (v *Version) Original() string {
+	if v.original == "" {
+		return v.String()
+	}
+	return v.original
+}
+
+// IsPrerelease проверяет, является ли версия предварительной
+func (v *Version) IsPrerelease() bool {
+	return len(v.Prerelease) > 0
+}
+
+// IsStable проверяет, является ли версия стабильной (без pre-release)
+func (v *Version) IsStable() bool {
+	return !v.IsPrerelease()
+}
+
+// Core возвращает версию без pre-release и метаданных сборки
+func (v *Version) Core() *Version {
+	return New(v.Major, v.Minor, v.Patch)
+}
+
+// IncMajor возвращает следующую мажорную версию
+func (v *Version) IncMajor() *Version {
+	return New(v.Major+1, 0, 0)
+}
+
+// IncMinor возвращает следующую минорную версию
+func (v *Version) IncMinor() *Version {
+	return New(v.Major, v.Minor+1, 0)
+}
+
+// IncPatch возвращает следующую патч-версию.
+// Для pre-release версии следующей считается ее стабильная версия.
+func (v *Version) IncPatch() *Version {
+	if v.IsPrerelease() {
+		return v.Core()
+	}
+	return New(v.Major, v.Minor, v.Patch+1)
+}
+
+// Compare сравнивает версии по приоритету SemVer: -1, 0 или 1.
+// Метаданные сборки в сравнении не участвуют.
+func (v *Version) Compare(o *Version) int {
+	if c := compareUint(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+// LessThan проверяет, что v меньше o
+func (v *Version) LessThan(o *Version) bool {
+	return v.Compare(o) < 0
+}
+
+// GreaterThan проверяет, что v больше o
+func (v *Version) GreaterThan(o *Version) bool {
+	return v.Compare(o) > 0
+}
+
+// Equal проверяет равенство приоритетов версий
+func (v *Version) Equal(o *Version) bool {
+	return v.Compare(o) == 0
+}
+
+// MarshalText кодирует версию в каноническую строку
+func (v Version) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText строго разбирает версию из строки
+func (v *Version) UnmarshalText(data []byte) error {
+	parsed, err := Parse(string(data))
+	if err != nil {
+		return err
+	}
+	*v = *parsed
+	return nil
+}
+
+// compareUint сравнивает два числа
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// comparePrerelease сравнивает pre-release идентификаторы по правилам SemVer
+func comparePrerelease(a, b []string) int {
+	// Версия без pre-release старше версии с pre-release
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return 1
+	case len(b) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := compareIdentifier(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return compareUint(uint64(len(a)), uint64(len(b)))
+}
+
+// compareIdentifier сравнивает идентификаторы: числовые численно и младше буквенных
+func compareIdentifier(a, b string) int {
+	aNumeric, bNumeric := isNumeric(a), isNumeric(b)
+	switch {
+	case aNumeric && bNumeric:
+		// Сравнение без переполнения: сначала по длине без ведущих нулей
+		a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+		if c := compareUint(uint64(len(a)), uint64(len(b))); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	case aNumeric:
+		return -1
+	case bNumeric:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
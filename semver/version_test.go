@@ -0,0 +1,249 @@
+package semver
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input      string
+		want       string
+		prerelease []string
+		build      []string
+	}{
+		{"1.2.3", "1.2.3", nil, nil},
+		{"0.0.0", "0.0.0", nil, nil},
+		{"1.2.3-alpha.1", "1.2.3-alpha.1", []string{"alpha", "1"}, nil},
+		{"1.2.3-0.3.7", "1.2.3-0.3.7", []string{"0", "3", "7"}, nil},
+		{"1.2.3-x-y.z", "1.2.3-x-y.z", []string{"x-y", "z"}, nil},
+		{"1.2.3+build.001", "1.2.3+build.001", nil, []string{"build", "001"}},
+		{"1.2.3-rc.1+sha.5114f85", "1.2.3-rc.1+sha.5114f85", []string{"rc", "1"}, []string{"sha", "5114f85"}},
+		{"18446744073709551615.0.0", "18446744073709551615.0.0", nil, nil},
+	}
+
+	for _, tt := range tests {
+		v, err := Parse(tt.input)
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", tt.input, err)
+			continue
+		}
+		if v.String() != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.input, v, tt.want)
+		}
+		if !reflect.DeepEqual(v.Prerelease, tt.prerelease) || !reflect.DeepEqual(v.Build, tt.build) {
+			t.Errorf("Parse(%q) prerelease = %v, build = %v, want %v, %v", tt.input, v.Prerelease, v.Build, tt.prerelease, tt.build)
+		}
+		if v.Original() != tt.input {
+			t.Errorf("Parse(%q).Original() = %q", tt.input, v.Original())
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"1",
+		"1.2",
+		"1.2.3.4",
+		"v1.2.3",
+		"01.2.3",
+		"1.02.3",
+		"1.2.3-01",
+		"1.2.3-",
+		"1.2.3-alpha..1",
+		"1.2.3+",
+		"1.2.3-alpha_1",
+		"1.2.-3",
+		"a.b.c",
+		"18446744073709551616.0.0",
+	}
+
+	for _, input := range tests {
+		if v, err := Parse(input); !errors.Is(err, ErrInvalidVersion) {
+			t.Errorf("Parse(%q) = %v, %v, want %v", input, v, err, ErrInvalidVersion)
+		}
+	}
+}
+
+func TestParseTolerant(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"v1.2.3", "1.2.3"},
+		{"V1.2.3", "1.2.3"},
+		{"=1.2.3", "1.2.3"},
+		{" 1.2.3 ", "1.2.3"},
+		{"1", "1.0.0"},
+		{"1.2", "1.2.0"},
+		{"01.02.03", "1.2.3"},
+		{"1.2-beta.01", "1.2.0-beta.01"},
+	}
+
+	for _, tt := range tests {
+		v, err := ParseTolerant(tt.input)
+		if err != nil {
+			t.Errorf("ParseTolerant(%q) error: %v", tt.input, err)
+			continue
+		}
+		if v.String() != tt.want {
+			t.Errorf("ParseTolerant(%q) = %s, want %s", tt.input, v, tt.want)
+		}
+	}
+
+	for _, input := range []string{"", "v", "1.2.3.4", "1.x"} {
+		if _, err := ParseTolerant(input); !errors.Is(err, ErrInvalidVersion) {
+			t.Errorf("ParseTolerant(%q) error = %v, want %v", input, err, ErrInvalidVersion)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// Порядок из спецификации SemVer 2.0.0
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"2.0.0",
+		"10.0.0",
+	}
+
+	for i := range ordered {
+		for j := range ordered {
+			a, b := MustParse(ordered[i]), MustParse(ordered[j])
+			want := compareUint(uint64(i), uint64(j))
+			if got := a.Compare(b); got != want {
+				t.Errorf("Compare(%s, %s) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+}
+
+func TestCompareIgnoresBuild(t *testing.T) {
+	a, b := MustParse("1.2.3+build.1"), MustParse("1.2.3+build.2")
+	if !a.Equal(b) {
+		t.Errorf("%s and %s are not equal", a, b)
+	}
+
+	// Числовые идентификаторы сравниваются без переполнения
+	big, small := MustParse("1.0.0-99999999999999999999999"), MustParse("1.0.0-9")
+	if !big.GreaterThan(small) {
+		t.Errorf("%s is not greater than %s", big, small)
+	}
+}
+
+func TestIncrement(t *testing.T) {
+	v := MustParse("1.2.3-rc.1+build")
+	tests := []struct {
+		name string
+		got  *Version
+		want string
+	}{
+		{"Core", v.Core(), "1.2.3"},
+		{"IncMajor", v.IncMajor(), "2.0.0"},
+		{"IncMinor", v.IncMinor(), "1.3.0"},
+		{"IncPatch prerelease", v.IncPatch(), "1.2.3"},
+		{"IncPatch stable", MustParse("1.2.3").IncPatch(), "1.2.4"},
+	}
+	for _, tt := range tests {
+		if tt.got.String() != tt.want {
+			t.Errorf("%s() = %s, want %s", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestVersionJSON(t *testing.T) {
+	var out struct {
+		Version Version `json:"version"`
+	}
+	if err := json.Unmarshal([]byte(`{"version":"1.2.3-beta+exp"}`), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Version.String() != "1.2.3-beta+exp" {
+		t.Errorf("version = %s", out.Version.String())
+	}
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"version":"1.2.3-beta+exp"}` {
+		t.Errorf("Marshal() = %s", data)
+	}
+
+	if err := json.Unmarshal([]byte(`{"version":"v1.2"}`), &out); !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("Unmarshal() of tolerant version = %v, want %v", err, ErrInvalidVersion)
+	}
+}
+
+func TestSortStrings(t *testing.T) {
+	values := []string{"1.10.0", "v1.2.0", "bad", "1.2.0-rc.1", "1.2.0+b", "1.9", "0.1.0", "also-bad"}
+	SortStrings(values)
+
+	want := []string{"also-bad", "bad", "0.1.0", "1.2.0-rc.1", "v1.2.0", "1.2.0+b", "1.9", "1.10.0"}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("SortStrings() = %v, want %v", values, want)
+	}
+}
+
+func TestSort(t *testing.T) {
+	versions, invalid := ParseAll([]string{"2.0.0", "1.0.0+b", "1.0.0+a", "1.0.0-rc.1", "not-a-version"})
+	if !reflect.DeepEqual(invalid, []string{"not-a-version"}) {
+		t.Errorf("ParseAll() invalid = %v", invalid)
+	}
+
+	Sort(versions)
+	var got []string
+	for _, v := range versions {
+		got = append(got, v.String())
+	}
+	want := []string{"1.0.0-rc.1", "1.0.0+a", "1.0.0+b", "2.0.0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Sort() = %v, want %v", got, want)
+	}
+}
+
+func TestLatest(t *testing.T) {
+	values := []string{"1.0.0", "v1.4.0", "2.0.0-beta.1", "1.10.0-rc.1", "garbage"}
+
+	tests := []struct {
+		name string
+		fn   func([]string) (string, error)
+		want string
+	}{
+		{"Latest", Latest, "2.0.0-beta.1"},
+		{"LatestStable", LatestStable, "v1.4.0"},
+	}
+	for _, tt := range tests {
+		got, err := tt.fn(values)
+		if err != nil || got != tt.want {
+			t.Errorf("%s() = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+
+	if _, err := LatestStable([]string{"1.0.0-rc.1", "bad"}); !errors.Is(err, ErrNoVersions) {
+		t.Errorf("LatestStable() error = %v, want %v", err, ErrNoVersions)
+	}
+}
+
+func TestCompareStrings(t *testing.T) {
+	if c, err := Compare("v1.2", "1.2.0"); err != nil || c != 0 {
+		t.Errorf("Compare(v1.2, 1.2.0) = %d, %v, want 0", c, err)
+	}
+	if c, err := Compare("1.2.0-rc.1", "1.2.0"); err != nil || c != -1 {
+		t.Errorf("Compare(1.2.0-rc.1, 1.2.0) = %d, %v, want -1", c, err)
+	}
+	if _, err := Compare("1.2.0", "x"); !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("Compare(1.2.0, x) error = %v, want %v", err, ErrInvalidVersion)
+	}
+}
@@ -0,0 +1,349 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+// KeyringVersion текущая версия формата файла ключей
+const KeyringVersion = 1
+
+// Префиксы подписываемых данных для служебных документов
+const (
+	rotationPrefix   = "criage-key-rotation-v1\n"
+	revocationPrefix = "criage-revocation-list-v1\n"
+)
+
+// Keyring локальное хранилище доверенных ключей и отзывов
+type Keyring struct {
+	path string
+	mu   sync.RWMutex
+	data types.Keyring
+}
+
+// NewKeyring создает пустое хранилище ключей, сохраняемое по пути path
+func NewKeyring(path string) *Keyring {
+	return &Keyring{
+		path: path,
+		data: types.Keyring{
+			Version:      KeyringVersion,
+			Repositories: make(map[string]*types.RepositoryKeys),
+		},
+	}
+}
+
+// LoadKeyring загружает хранилище ключей; отсутствующий файл дает пустое хранилище
+func LoadKeyring(path string) (*Keyring, error) {
+	keyring := NewKeyring(path)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return keyring, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyring: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &keyring.data); err != nil {
+		return nil, fmt.Errorf("failed to parse keyring: %w", err)
+	}
+	if keyring.data.Version > KeyringVersion {
+		return nil, fmt.Errorf("unsupported keyring version: %d", keyring.data.Version)
+	}
+	if keyring.data.Repositories == nil {
+		keyring.data.Repositories = make(map[string]*types.RepositoryKeys)
+	}
+
+	return keyring, nil
+}
+
+// Path возвращает путь к файлу хранилища
+func (k *Keyring) Path() string {
+	return k.path
+}
+
+// Save атомарно сохраняет хранилище ключей с правами 0600
+func (k *Keyring) Save() error {
+	k.mu.RLock()
+	data, err := json.MarshalIndent(&k.data, "", "  ")
+	k.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(k.path), 0700); err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(k.path), ".keyring-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Chmod(0600); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), k.path)
+}
+
+// Keys возвращает ключи репозитория
+func (k *Keyring) Keys(repo string) []types.TrustedKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	entry := k.data.Repositories[repo]
+	if entry == nil {
+		return nil
+	}
+	return append([]types.TrustedKey(nil), entry.Keys...)
+}
+
+// AddKey добавляет ключ репозитория. Уже сохраненный ключ не изменяется,
+// в том числе его срок действия; для него возвращается существующая запись.
+func (k *Keyring) AddKey(repo string, publicKey ed25519.PublicKey, source types.KeySource, expiresAt *time.Time) types.TrustedKey {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	key, _ := k.addKey(k.repository(repo), publicKey, source, expiresAt)
+	return *key
+}
+
+// SetExpiry задает срок действия ключа репозитория
+func (k *Keyring) SetExpiry(repo string, publicKey ed25519.PublicKey, expiresAt time.Time) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.setExpiry(k.repository(repo), publicKey, expiresAt, false)
+}
+
+// addKey добавляет ключ, если его еще нет; existed сообщает, что ключ уже был сохранен
+func (k *Keyring) addKey(entry *types.RepositoryKeys, publicKey ed25519.PublicKey, source types.KeySource, expiresAt *time.Time) (key *types.TrustedKey, existed bool) {
+	keyID := KeyID(publicKey)
+	for i := range entry.Keys {
+		if entry.Keys[i].KeyID == keyID {
+			return &entry.Keys[i], true
+		}
+	}
+
+	entry.Keys = append(entry.Keys, types.TrustedKey{
+		KeyID:     keyID,
+		PublicKey: EncodePublicKey(publicKey),
+		Source:    source,
+		AddedAt:   time.Now().UTC(),
+		ExpiresAt: expiresAt,
+	})
+	return &entry.Keys[len(entry.Keys)-1], false
+}
+
+// setExpiry задает срок действия ключа; shortenOnly не позволяет продлить
+// или снять уже заданный срок
+func (k *Keyring) setExpiry(entry *types.RepositoryKeys, publicKey ed25519.PublicKey, expiresAt time.Time, shortenOnly bool) {
+	// Закрепленный в конфигурации ключ получает запись только со сроком действия
+	key, existed := k.addKey(entry, publicKey, types.KeySourceConfig, &expiresAt)
+	if !existed {
+		return
+	}
+	if shortenOnly && key.ExpiresAt != nil && !expiresAt.Before(*key.ExpiresAt) {
+		return
+	}
+	key.ExpiresAt = &expiresAt
+}
+
+// applyRotation принимает смену ключа из заявления. Заявление с версией не выше
+// уже принятой отклоняется (защита от повторного применения), а сроки действия
+// уже сохраненных ключей могут только сокращаться.
+func (k *Keyring) applyRotation(rotation *types.KeyRotation, newKey, oldKey ed25519.PublicKey) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	entry := k.repository(rotation.Repository)
+	if rotation.Version <= entry.RotationVersion {
+		return fmt.Errorf("key rotation version %d is not newer than %d", rotation.Version, entry.RotationVersion)
+	}
+
+	if _, existed := k.addKey(entry, newKey, types.KeySourceRotation, rotation.NewKeyExpiresAt); existed && rotation.NewKeyExpiresAt != nil {
+		k.setExpiry(entry, newKey, *rotation.NewKeyExpiresAt, true)
+	}
+	if rotation.OldKeyExpiresAt != nil {
+		k.setExpiry(entry, oldKey, *rotation.OldKeyExpiresAt, true)
+	}
+	entry.RotationVersion = rotation.Version
+	return nil
+}
+
+// RemoveKey удаляет ключ репозитория
+func (k *Keyring) RemoveKey(repo, keyID string) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	entry := k.data.Repositories[repo]
+	if entry == nil {
+		return false
+	}
+
+	for i := range entry.Keys {
+		if entry.Keys[i].KeyID == keyID {
+			entry.Keys = append(entry.Keys[:i], entry.Keys[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Revoke отзывает ключ репозитория
+func (k *Keyring) Revoke(repo, keyID, reason string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.revoke(k.repository(repo), types.KeyRevocation{
+		KeyID:     keyID,
+		RevokedAt: time.Now().UTC(),
+		Reason:    reason,
+	})
+}
+
+// IsRevoked проверяет, отозван ли ключ репозитория
+func (k *Keyring) IsRevoked(repo, keyID string) bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	entry := k.data.Repositories[repo]
+	if entry == nil {
+		return false
+	}
+	for _, revocation := range entry.Revocations {
+		if revocation.KeyID == keyID {
+			return true
+		}
+	}
+	return false
+}
+
+// revoke добавляет запись об отзыве, если ключ еще не отозван
+func (k *Keyring) revoke(entry *types.RepositoryKeys, revocation types.KeyRevocation) {
+	for _, existing := range entry.Revocations {
+		if existing.KeyID == revocation.KeyID {
+			return
+		}
+	}
+	entry.Revocations = append(entry.Revocations, revocation)
+}
+
+// repository возвращает (создавая при необходимости) записи репозитория
+func (k *Keyring) repository(repo string) *types.RepositoryKeys {
+	entry := k.data.Repositories[repo]
+	if entry == nil {
+		entry = &types.RepositoryKeys{}
+		k.data.Repositories[repo] = entry
+	}
+	return entry
+}
+
+// SignRotation подписывает заявление о смене ключа текущим ключом репозитория
+func SignRotation(rotation *types.KeyRotation, oldKey ed25519.PrivateKey) error {
+	publicKey, ok := oldKey.Public().(ed25519.PublicKey)
+	if !ok {
+		return fmt.Errorf("invalid private key")
+	}
+	rotation.OldKeyID = KeyID(publicKey)
+
+	payload, err := rotationPayload(rotation)
+	if err != nil {
+		return err
+	}
+	rotation.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(oldKey, payload))
+	return nil
+}
+
+// SignRevocationList подписывает список отзывов ключом репозитория
+func SignRevocationList(list *types.RevocationList, key ed25519.PrivateKey) error {
+	publicKey, ok := key.Public().(ed25519.PublicKey)
+	if !ok {
+		return fmt.Errorf("invalid private key")
+	}
+	list.KeyID = KeyID(publicKey)
+
+	payload, err := revocationPayload(list)
+	if err != nil {
+		return err
+	}
+	list.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload))
+	return nil
+}
+
+// rotationPayload возвращает подписываемое представление заявления о смене ключа
+func rotationPayload(rotation *types.KeyRotation) ([]byte, error) {
+	unsigned := *rotation
+	unsigned.Signature = ""
+	data, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(rotationPrefix), data...), nil
+}
+
+// revocationPayload возвращает подписываемое представление списка отзывов
+func revocationPayload(list *types.RevocationList) ([]byte, error) {
+	unsigned := *list
+	unsigned.Signature = ""
+	data, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(revocationPrefix), data...), nil
+}
+
+// verifyRaw проверяет base64 подпись ed25519 над payload
+func verifyRaw(publicKey ed25519.PublicKey, payload []byte, signature string) error {
+	raw, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	if !ed25519.Verify(publicKey, payload, raw) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// revokeKey отзывает один ключ, не меняя версию списка отзывов
+func (k *Keyring) revokeKey(repo string, revocation types.KeyRevocation) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.revoke(k.repository(repo), revocation)
+}
+
+// applyRevocations принимает отзывы из списка версии version
+func (k *Keyring) applyRevocations(repo string, version int, revocations []types.KeyRevocation) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	entry := k.repository(repo)
+	if version <= entry.RevocationVersion {
+		return fmt.Errorf("revocation list version %d is not newer than %d", version, entry.RevocationVersion)
+	}
+
+	for _, revocation := range revocations {
+		k.revoke(entry, revocation)
+	}
+	entry.RevocationVersion = version
+	return nil
+}
@@ -0,0 +1,136 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+// publicKeyPrefix префикс текстового представления открытого ключа
+const publicKeyPrefix = types.SignatureAlgorithmEd25519 + ":"
+
+// GenerateKey создает новую пару ключей ed25519
+func GenerateKey() (ed25519.PublicKey, ed25519.PrivateKey, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return publicKey, privateKey, nil
+}
+
+// KeyID возвращает идентификатор ключа (первые 16 байт SHA-256 открытого ключа)
+func KeyID(publicKey ed25519.PublicKey) string {
+	sum := sha256.Sum256(publicKey)
+	return hex.EncodeToString(sum[:16])
+}
+
+// EncodePublicKey кодирует открытый ключ в строку вида "ed25519:<base64>"
+func EncodePublicKey(publicKey ed25519.PublicKey) string {
+	return publicKeyPrefix + base64.StdEncoding.EncodeToString(publicKey)
+}
+
+// ParsePublicKey разбирает открытый ключ в формате "ed25519:<base64>"
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, publicKeyPrefix) {
+		return nil, fmt.Errorf("unsupported public key format: %q", s)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(s, publicKeyPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key encoding: %w", err)
+	}
+	if len(data) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key size: %d", len(data))
+	}
+
+	return ed25519.PublicKey(data), nil
+}
+
+// SavePrivateKey сохраняет закрытый ключ в PEM (PKCS#8) с правами 0600
+func SavePrivateKey(path string, privateKey ed25519.PrivateKey) error {
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return fmt.Errorf("failed to marshal private key: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	return os.WriteFile(path, data, 0600)
+}
+
+// LoadPrivateKey загружает закрытый ключ ed25519 из PEM файла
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("no private key found in %s", path)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return privateKey, nil
+}
+
+// SavePublicKey сохраняет открытый ключ в PEM (PKIX)
+func SavePublicKey(path string, publicKey ed25519.PublicKey) error {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return fmt.Errorf("failed to marshal public key: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadPublicKey загружает открытый ключ ed25519 из PEM файла
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("no public key found in %s", path)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+	return publicKey, nil
+}
@@ -0,0 +1,137 @@
+// Package signing реализует подпись пакетов Criage ключами ed25519
+// и проверку подписей по ключам, закрепленным за репозиториями.
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+// signaturePrefix отделяет подписи пакетов от других данных, подписанных тем же ключом
+const signaturePrefix = "criage-package-signature-v1\n"
+
+// DetachedSuffix расширение файла отсоединенной подписи
+const DetachedSuffix = ".sig"
+
+var (
+	// ErrInvalidSignature подпись не соответствует данным
+	ErrInvalidSignature = errors.New("invalid package signature")
+	// ErrDigestMismatch дайджест подписи не соответствует метаданным пакета
+	ErrDigestMismatch = errors.New("package digest mismatch")
+)
+
+// EncodeMetadata сериализует метаданные пакета в содержимое файла
+// .criage-metadata.json. Подписываются именно эти байты, поэтому метаданные
+// должны содержать корень дерева Меркла над файлами пакета.
+func EncodeMetadata(metadata *types.PackageMetadata) ([]byte, error) {
+	if metadata == nil {
+		return nil, fmt.Errorf("package metadata is required")
+	}
+	if metadata.MerkleRoot == "" {
+		return nil, fmt.Errorf("package metadata has no merkle root")
+	}
+
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	return data, nil
+}
+
+// Digest вычисляет дайджест пакета по точному содержимому файла метаданных.
+// Данные не разбираются и не сериализуются повторно: подпись покрывает числа,
+// неизвестные поля и форматирование в том виде, в каком они хранятся в архиве.
+func Digest(metadata []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(signaturePrefix))
+	hash.Write(metadata)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Sign подписывает содержимое файла метаданных пакета закрытым ключом
+func Sign(metadata []byte, privateKey ed25519.PrivateKey) (*types.PackageSignature, error) {
+	if len(metadata) == 0 {
+		return nil, fmt.Errorf("package metadata is required")
+	}
+
+	publicKey, ok := privateKey.Public().(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid private key")
+	}
+
+	digest := Digest(metadata)
+	signature := ed25519.Sign(privateKey, []byte(signaturePrefix+digest))
+
+	return &types.PackageSignature{
+		Algorithm: types.SignatureAlgorithmEd25519,
+		KeyID:     KeyID(publicKey),
+		PublicKey: EncodePublicKey(publicKey),
+		Digest:    digest,
+		Signature: base64.StdEncoding.EncodeToString(signature),
+		SignedAt:  time.Now().UTC(),
+	}, nil
+}
+
+// Verify проверяет подпись содержимого файла метаданных пакета открытым ключом
+func Verify(metadata []byte, signature *types.PackageSignature, publicKey ed25519.PublicKey) error {
+	if signature == nil {
+		return fmt.Errorf("%w: signature is missing", ErrInvalidSignature)
+	}
+	if signature.Algorithm != types.SignatureAlgorithmEd25519 {
+		return fmt.Errorf("unsupported signature algorithm: %s", signature.Algorithm)
+	}
+	if signature.KeyID != KeyID(publicKey) {
+		return fmt.Errorf("%w: signed by key %s, not %s", ErrInvalidSignature, signature.KeyID, KeyID(publicKey))
+	}
+
+	digest := Digest(metadata)
+	if digest != signature.Digest {
+		return ErrDigestMismatch
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(signature.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	if !ed25519.Verify(publicKey, []byte(signaturePrefix+digest), raw) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// WriteDetachedSignature сохраняет подпись рядом с архивом в файл <archive>.sig
+func WriteDetachedSignature(archivePath string, signature *types.PackageSignature) error {
+	data, err := json.MarshalIndent(signature, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(archivePath+DetachedSuffix, data, 0644)
+}
+
+// ReadDetachedSignature загружает подпись из файла <archive>.sig
+func ReadDetachedSignature(archivePath string) (*types.PackageSignature, error) {
+	data, err := os.ReadFile(archivePath + DetachedSuffix)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSignature(data)
+}
+
+// ParseSignature разбирает подпись пакета из JSON
+func ParseSignature(data []byte) (*types.PackageSignature, error) {
+	var signature types.PackageSignature
+	if err := json.Unmarshal(data, &signature); err != nil {
+		return nil, fmt.Errorf("failed to parse signature: %w", err)
+	}
+	return &signature, nil
+}
@@ -0,0 +1,146 @@
+package signing
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+func TestSignVerify(t *testing.T) {
+	publicKey, privateKey, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := EncodeMetadata(&types.PackageMetadata{CreatedBy: "criage", MerkleRoot: "abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signature, err := Sign(data, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(data, signature, publicKey); err != nil {
+		t.Fatalf("Verify() = %v", err)
+	}
+
+	otherKey, _, _ := GenerateKey()
+	if err := Verify(data, signature, otherKey); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify() with other key = %v, want %v", err, ErrInvalidSignature)
+	}
+
+	forged := *signature
+	forged.Digest = Digest(append(data, ' '))
+	if err := Verify(append(data, ' '), &forged, publicKey); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify() with forged digest = %v, want %v", err, ErrInvalidSignature)
+	}
+}
+
+func TestVerifyCoversExactBytes(t *testing.T) {
+	publicKey, privateKey, _ := GenerateKey()
+
+	// Большие целые в PackageManifest.Metadata и неизвестные поля теряются
+	// при разборе в types.PackageMetadata и повторной сериализации
+	data := []byte(`{"package":{"name":"app","version":"1.0.0","metadata":{"build":9007199254740993}},"createdBy":"criage","merkleRoot":"abc","futureField":"x"}`)
+	signature, err := Sign(data, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"large integer", strings.Replace(string(data), "9007199254740993", "9007199254740992", 1)},
+		{"unknown field", strings.Replace(string(data), `"futureField":"x"`, `"futureField":"y"`, 1)},
+		{"formatting", string(data) + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Verify([]byte(tt.data), signature, publicKey); !errors.Is(err, ErrDigestMismatch) {
+				t.Errorf("Verify() = %v, want %v", err, ErrDigestMismatch)
+			}
+		})
+	}
+
+	// Повторная сериализация разобранных метаданных не совпадает с подписанной
+	var metadata types.PackageMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		t.Fatal(err)
+	}
+	reencoded, err := json.Marshal(&metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(reencoded, signature, publicKey); !errors.Is(err, ErrDigestMismatch) {
+		t.Errorf("Verify() of re-encoded metadata = %v, want %v", err, ErrDigestMismatch)
+	}
+}
+
+func TestEncodeMetadataRequiresMerkleRoot(t *testing.T) {
+	if _, err := EncodeMetadata(&types.PackageMetadata{CreatedBy: "criage"}); err == nil {
+		t.Error("EncodeMetadata() without merkle root succeeded")
+	}
+	if _, err := EncodeMetadata(nil); err == nil {
+		t.Error("EncodeMetadata(nil) succeeded")
+	}
+}
+
+func TestTrustStoreVerify(t *testing.T) {
+	store, _, privateKey := newTestStore(t)
+	data := []byte(`{"merkleRoot":"abc"}`)
+	signature, err := Sign(data, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Verify("official", data, signature); err != nil {
+		t.Errorf("Verify() = %v", err)
+	}
+	if err := store.Verify("official", []byte(`{"merkleRoot":"abd"}`), signature); !errors.Is(err, ErrDigestMismatch) {
+		t.Errorf("Verify() of modified metadata = %v, want %v", err, ErrDigestMismatch)
+	}
+}
+
+func TestTrustStoreUnsignedPackages(t *testing.T) {
+	_, privateKey, _ := GenerateKey()
+	data := []byte(`{"merkleRoot":"abc"}`)
+	signature, err := Sign(data, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pinned, _, _ := newTestStore(t)
+	unpinned, err := NewTrustStore([]types.Repository{{Name: "plain"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	required, err := NewTrustStore([]types.Repository{{Name: "plain", RequireSignature: true}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		store     *TrustStore
+		repo      string
+		signature *types.PackageSignature
+		want      error
+	}{
+		{"stripped signature with pinned keys", pinned, "official", nil, ErrSignatureRequired},
+		{"unsigned without keys", unpinned, "plain", nil, nil},
+		{"unsigned with required signature", required, "plain", nil, ErrSignatureRequired},
+		{"strict mode without keys", unpinned, "plain", signature, ErrUntrustedKey},
+		{"signed by another key", pinned, "official", signature, ErrUntrustedKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.store.Verify(tt.repo, data, tt.signature)
+			if (tt.want == nil && err != nil) || !errors.Is(err, tt.want) {
+				t.Errorf("Verify() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
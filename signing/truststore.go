@@ -0,0 +1,305 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+var (
+	// ErrUntrustedKey пакет подписан ключом, которому репозиторий не доверяет
+	ErrUntrustedKey = errors.New("package signed by untrusted key")
+	// ErrSignatureRequired репозиторий требует подпись, но пакет не подписан
+	ErrSignatureRequired = errors.New("package signature required")
+	// ErrUnknownRepository репозиторий отсутствует в хранилище доверия
+	ErrUnknownRepository = errors.New("unknown repository")
+	// ErrRevokedKey ключ подписи отозван
+	ErrRevokedKey = errors.New("signing key revoked")
+	// ErrExpiredKey срок действия ключа подписи истек
+	ErrExpiredKey = errors.New("signing key expired")
+	// ErrNoKeyring операция требует локального хранилища ключей
+	ErrNoKeyring = errors.New("keyring is not configured")
+)
+
+// TrustStore хранит доверенные ключи, закрепленные за репозиториями.
+// Ключи из конфигурации дополняются ключами из локального хранилища
+// (TOFU, ротация) с учетом сроков действия и отзывов.
+type TrustStore struct {
+	repositories map[string]*repositoryTrust
+	keyring      *Keyring
+
+	// now источник текущего времени для проверки сроков действия
+	now func() time.Time
+}
+
+// repositoryTrust настройки доверия одного репозитория
+type repositoryTrust struct {
+	pinned           map[string]ed25519.PublicKey
+	requireSignature bool
+	mode             types.TrustMode
+}
+
+// NewTrustStore создает хранилище доверия из настроек репозиториев.
+// keyring может быть nil, тогда доступны только закрепленные в конфигурации ключи.
+func NewTrustStore(repositories []types.Repository, keyring *Keyring) (*TrustStore, error) {
+	store := &TrustStore{
+		repositories: make(map[string]*repositoryTrust),
+		keyring:      keyring,
+		now:          time.Now,
+	}
+
+	for _, repo := range repositories {
+		trust := &repositoryTrust{
+			pinned:           make(map[string]ed25519.PublicKey),
+			requireSignature: repo.RequireSignature,
+			mode:             repo.TrustMode,
+		}
+		if trust.mode == "" {
+			trust.mode = types.TrustModeStrict
+		}
+
+		switch trust.mode {
+		case types.TrustModeStrict, types.TrustModeTOFU, types.TrustModeOff:
+		default:
+			return nil, fmt.Errorf("repository %s: unknown trust mode %q", repo.Name, repo.TrustMode)
+		}
+
+		for _, encoded := range repo.SigningKeys {
+			publicKey, err := ParsePublicKey(encoded)
+			if err != nil {
+				return nil, fmt.Errorf("repository %s: %w", repo.Name, err)
+			}
+			trust.pinned[KeyID(publicKey)] = publicKey
+		}
+		store.repositories[repo.Name] = trust
+	}
+
+	return store, nil
+}
+
+// Verify проверяет подпись пакета из репозитория repoName по точному содержимому
+// файла метаданных. Неподписанный пакет допускается, только если репозиторий
+// не требует подписи и ему не известен ни один ключ.
+func (s *TrustStore) Verify(repoName string, metadata []byte, signature *types.PackageSignature) error {
+	trust, ok := s.repositories[repoName]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownRepository, repoName)
+	}
+
+	if trust.mode == types.TrustModeOff {
+		return nil
+	}
+
+	hasKeys := s.hasKeys(repoName, trust)
+
+	// Удаление подписи не должно превращать пакет из подписывающего
+	// репозитория в неподписанный
+	if signature == nil {
+		if trust.requireSignature || hasKeys {
+			return fmt.Errorf("%w: repository %s", ErrSignatureRequired, repoName)
+		}
+		return nil
+	}
+
+	// В строгом режиме без известных ключей lookupKey возвращает ErrUntrustedKey
+	publicKey, err := s.lookupKey(repoName, trust, signature.KeyID)
+	if errors.Is(err, ErrUntrustedKey) && !hasKeys && trust.mode == types.TrustModeTOFU {
+		return s.trustOnFirstUse(repoName, metadata, signature)
+	}
+	if err != nil {
+		return err
+	}
+
+	return Verify(metadata, signature, publicKey)
+}
+
+// TrustedKeys возвращает действующие ключи репозитория
+func (s *TrustStore) TrustedKeys(repoName string) ([]ed25519.PublicKey, error) {
+	trust, ok := s.repositories[repoName]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRepository, repoName)
+	}
+
+	var keys []ed25519.PublicKey
+	for keyID := range s.knownKeyIDs(repoName, trust) {
+		if publicKey, err := s.lookupKey(repoName, trust, keyID); err == nil {
+			keys = append(keys, publicKey)
+		}
+	}
+	return keys, nil
+}
+
+// ApplyRotation принимает заявление о смене ключа, подписанное действующим ключом.
+// Заявление с версией не выше уже принятой отклоняется (защита от повтора).
+func (s *TrustStore) ApplyRotation(rotation *types.KeyRotation) error {
+	if s.keyring == nil {
+		return ErrNoKeyring
+	}
+
+	trust, ok := s.repositories[rotation.Repository]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownRepository, rotation.Repository)
+	}
+
+	oldKey, err := s.lookupKey(rotation.Repository, trust, rotation.OldKeyID)
+	if err != nil {
+		return fmt.Errorf("rotation not signed by a trusted key: %w", err)
+	}
+
+	payload, err := rotationPayload(rotation)
+	if err != nil {
+		return err
+	}
+	if err := verifyRaw(oldKey, payload, rotation.Signature); err != nil {
+		return fmt.Errorf("invalid key rotation: %w", err)
+	}
+
+	newKey, err := ParsePublicKey(rotation.NewPublicKey)
+	if err != nil {
+		return err
+	}
+
+	if err := s.keyring.applyRotation(rotation, newKey, oldKey); err != nil {
+		return err
+	}
+	return s.keyring.Save()
+}
+
+// ApplyRevocationList принимает список отзывов, подписанный доверенным ключом репозитория.
+// Список с версией не выше уже принятой отклоняется (защита от отката).
+// Отозванным или просроченным ключом можно подписать только отзыв самого этого ключа;
+// такой отзыв не меняет версию списка.
+func (s *TrustStore) ApplyRevocationList(list *types.RevocationList) error {
+	if s.keyring == nil {
+		return ErrNoKeyring
+	}
+
+	trust, ok := s.repositories[list.Repository]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownRepository, list.Repository)
+	}
+
+	publicKey, err := s.lookupKey(list.Repository, trust, list.KeyID)
+	selfRevocation := false
+	if errors.Is(err, ErrRevokedKey) || errors.Is(err, ErrExpiredKey) {
+		if !isSelfRevocation(list) {
+			return fmt.Errorf("revocation list signed by an untrusted key: %w", err)
+		}
+		publicKey = s.rawKey(list.Repository, trust, list.KeyID)
+		selfRevocation = true
+	} else if err != nil {
+		return fmt.Errorf("revocation list not signed by a trusted key: %w", err)
+	}
+	if publicKey == nil {
+		return fmt.Errorf("%w: key %s for repository %s", ErrUntrustedKey, list.KeyID, list.Repository)
+	}
+
+	payload, err := revocationPayload(list)
+	if err != nil {
+		return err
+	}
+	if err := verifyRaw(publicKey, payload, list.Signature); err != nil {
+		return fmt.Errorf("invalid revocation list: %w", err)
+	}
+
+	if selfRevocation {
+		s.keyring.revokeKey(list.Repository, list.Revocations[0])
+	} else if err := s.keyring.applyRevocations(list.Repository, list.Version, list.Revocations); err != nil {
+		return err
+	}
+	return s.keyring.Save()
+}
+
+// isSelfRevocation сообщает, отзывает ли список только ключ, которым он подписан
+func isSelfRevocation(list *types.RevocationList) bool {
+	return len(list.Revocations) == 1 && list.Revocations[0].KeyID == list.KeyID
+}
+
+// trustOnFirstUse закрепляет ключ из подписи, если она действительна
+func (s *TrustStore) trustOnFirstUse(repoName string, metadata []byte, signature *types.PackageSignature) error {
+	if s.keyring == nil {
+		return ErrNoKeyring
+	}
+	if signature.PublicKey == "" {
+		return fmt.Errorf("%w: signature carries no public key for trust on first use", ErrUntrustedKey)
+	}
+
+	publicKey, err := ParsePublicKey(signature.PublicKey)
+	if err != nil {
+		return err
+	}
+	if s.keyring.IsRevoked(repoName, KeyID(publicKey)) {
+		return fmt.Errorf("%w: key %s", ErrRevokedKey, KeyID(publicKey))
+	}
+
+	if err := Verify(metadata, signature, publicKey); err != nil {
+		return err
+	}
+
+	s.keyring.AddKey(repoName, publicKey, types.KeySourceTOFU, nil)
+	return s.keyring.Save()
+}
+
+// lookupKey возвращает действующий ключ репозитория по идентификатору
+func (s *TrustStore) lookupKey(repoName string, trust *repositoryTrust, keyID string) (ed25519.PublicKey, error) {
+	if s.keyring != nil && s.keyring.IsRevoked(repoName, keyID) {
+		return nil, fmt.Errorf("%w: key %s for repository %s", ErrRevokedKey, keyID, repoName)
+	}
+
+	publicKey := s.rawKey(repoName, trust, keyID)
+	if publicKey == nil {
+		return nil, fmt.Errorf("%w: key %s for repository %s", ErrUntrustedKey, keyID, repoName)
+	}
+
+	if s.keyring != nil {
+		for _, key := range s.keyring.Keys(repoName) {
+			if key.KeyID == keyID && key.ExpiresAt != nil && !s.now().Before(*key.ExpiresAt) {
+				return nil, fmt.Errorf("%w: key %s expired at %s", ErrExpiredKey, keyID, key.ExpiresAt.Format(time.RFC3339))
+			}
+		}
+	}
+
+	return publicKey, nil
+}
+
+// rawKey ищет ключ без учета отзывов и сроков действия
+func (s *TrustStore) rawKey(repoName string, trust *repositoryTrust, keyID string) ed25519.PublicKey {
+	if publicKey, ok := trust.pinned[keyID]; ok {
+		return publicKey
+	}
+	if s.keyring == nil {
+		return nil
+	}
+	for _, key := range s.keyring.Keys(repoName) {
+		if key.KeyID == keyID {
+			publicKey, err := ParsePublicKey(key.PublicKey)
+			if err != nil {
+				return nil
+			}
+			return publicKey
+		}
+	}
+	return nil
+}
+
+// knownKeyIDs возвращает идентификаторы всех известных ключей репозитория
+func (s *TrustStore) knownKeyIDs(repoName string, trust *repositoryTrust) map[string]bool {
+	ids := make(map[string]bool, len(trust.pinned))
+	for keyID := range trust.pinned {
+		ids[keyID] = true
+	}
+	if s.keyring != nil {
+		for _, key := range s.keyring.Keys(repoName) {
+			ids[key.KeyID] = true
+		}
+	}
+	return ids
+}
+
+// hasKeys проверяет, известен ли репозиторию хотя бы один ключ
+func (s *TrustStore) hasKeys(repoName string, trust *repositoryTrust) bool {
+	return len(s.knownKeyIDs(repoName, trust)) > 0
+}
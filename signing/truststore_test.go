@@ -0,0 +1,221 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+// newTestStore создает хранилище доверия репозитория official с закрепленным ключом
+func newTestStore(t *testing.T) (*TrustStore, *Keyring, ed25519.PrivateKey) {
+	t.Helper()
+	publicKey, privateKey, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring := NewKeyring(filepath.Join(t.TempDir(), "keyring.json"))
+	store, err := NewTrustStore([]types.Repository{{
+		Name:        "official",
+		URL:         "https://packages.example.com",
+		Enabled:     true,
+		SigningKeys: []string{EncodePublicKey(publicKey)},
+	}}, keyring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store, keyring, privateKey
+}
+
+// signedRotation подписывает смену ключа oldKey на newKey
+func signedRotation(t *testing.T, version int, oldKey ed25519.PrivateKey, newKey ed25519.PublicKey, oldExpiresAt *time.Time) *types.KeyRotation {
+	t.Helper()
+	rotation := &types.KeyRotation{
+		Repository:      "official",
+		Version:         version,
+		NewPublicKey:    EncodePublicKey(newKey),
+		OldKeyExpiresAt: oldExpiresAt,
+		CreatedAt:       time.Now().UTC(),
+	}
+	if err := SignRotation(rotation, oldKey); err != nil {
+		t.Fatal(err)
+	}
+	return rotation
+}
+
+func TestApplyRotationRejectsReplay(t *testing.T) {
+	store, _, firstKey := newTestStore(t)
+	secondPublic, secondKey, _ := GenerateKey()
+	thirdPublic, _, _ := GenerateKey()
+
+	first := signedRotation(t, 1, firstKey, secondPublic, nil)
+	if err := store.ApplyRotation(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.ApplyRotation(first); err == nil {
+		t.Fatal("replayed rotation accepted")
+	}
+
+	// Вторая смена выводит промежуточный ключ из обращения
+	retired := time.Now().Add(-time.Hour).UTC()
+	if err := store.ApplyRotation(signedRotation(t, 2, secondKey, thirdPublic, &retired)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Повтор первой смены не должен вернуть ключу срок действия
+	if err := store.ApplyRotation(first); err == nil {
+		t.Fatal("older rotation accepted after a newer one")
+	}
+	trust := store.repositories["official"]
+	if _, err := store.lookupKey("official", trust, KeyID(secondPublic)); !errors.Is(err, ErrExpiredKey) {
+		t.Errorf("retired key is usable again: %v", err)
+	}
+	if _, err := store.lookupKey("official", trust, KeyID(thirdPublic)); err != nil {
+		t.Errorf("rotated key is not trusted: %v", err)
+	}
+}
+
+func TestApplyRotationRejectsZeroVersion(t *testing.T) {
+	store, _, firstKey := newTestStore(t)
+	newPublic, _, _ := GenerateKey()
+	if err := store.ApplyRotation(signedRotation(t, 0, firstKey, newPublic, nil)); err == nil {
+		t.Fatal("rotation without version accepted")
+	}
+}
+
+func TestApplyRotationCannotExtendExpiry(t *testing.T) {
+	store, keyring, firstKey := newTestStore(t)
+	secondPublic, _, _ := GenerateKey()
+
+	soon := time.Now().Add(time.Hour).UTC()
+	if err := store.ApplyRotation(signedRotation(t, 1, firstKey, secondPublic, &soon)); err != nil {
+		t.Fatal(err)
+	}
+	later := soon.Add(24 * time.Hour)
+	if err := store.ApplyRotation(signedRotation(t, 2, firstKey, secondPublic, &later)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range keyring.Keys("official") {
+		if key.KeyID == KeyID(firstKey.Public().(ed25519.PublicKey)) && !key.ExpiresAt.Equal(soon) {
+			t.Errorf("old key expiry changed to %v, want %v", key.ExpiresAt, soon)
+		}
+	}
+}
+
+func TestAddKeyKeepsExpiry(t *testing.T) {
+	keyring := NewKeyring(filepath.Join(t.TempDir(), "keyring.json"))
+	publicKey, _, _ := GenerateKey()
+
+	expiresAt := time.Now().Add(-time.Minute).UTC()
+	keyring.AddKey("official", publicKey, types.KeySourceTOFU, nil)
+	keyring.SetExpiry("official", publicKey, expiresAt)
+
+	key := keyring.AddKey("official", publicKey, types.KeySourceRotation, nil)
+	if key.ExpiresAt == nil || !key.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("AddKey reset expiry to %v", key.ExpiresAt)
+	}
+	if key.Source != types.KeySourceTOFU {
+		t.Errorf("AddKey changed source to %s", key.Source)
+	}
+}
+
+func TestApplyRevocationListRejectsRollback(t *testing.T) {
+	store, keyring, key := newTestStore(t)
+	revokedPublic, _, _ := GenerateKey()
+
+	list := &types.RevocationList{
+		Repository:  "official",
+		Version:     2,
+		Revocations: []types.KeyRevocation{{KeyID: KeyID(revokedPublic), RevokedAt: time.Now().UTC()}},
+	}
+	if err := SignRevocationList(list, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.ApplyRevocationList(list); err != nil {
+		t.Fatal(err)
+	}
+	if !keyring.IsRevoked("official", KeyID(revokedPublic)) {
+		t.Error("key not revoked")
+	}
+
+	older := &types.RevocationList{Repository: "official", Version: 1}
+	if err := SignRevocationList(older, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.ApplyRevocationList(older); err == nil {
+		t.Error("older revocation list accepted")
+	}
+}
+
+func TestApplyRevocationListRequiresTrustedKey(t *testing.T) {
+	store, keyring, firstKey := newTestStore(t)
+	secondPublic, secondKey, _ := GenerateKey()
+	otherPublic, _, _ := GenerateKey()
+
+	// Первый ключ выводится из обращения сменой ключа
+	retired := time.Now().Add(-time.Hour).UTC()
+	if err := store.ApplyRotation(signedRotation(t, 1, firstKey, secondPublic, &retired)); err != nil {
+		t.Fatal(err)
+	}
+	firstID := KeyID(firstKey.Public().(ed25519.PublicKey))
+
+	// Просроченный ключ не может отзывать другие ключи
+	list := &types.RevocationList{
+		Repository:  "official",
+		Version:     1,
+		Revocations: []types.KeyRevocation{{KeyID: KeyID(secondPublic), RevokedAt: time.Now().UTC()}},
+	}
+	if err := SignRevocationList(list, firstKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.ApplyRevocationList(list); !errors.Is(err, ErrExpiredKey) {
+		t.Errorf("ApplyRevocationList() = %v, want %v", err, ErrExpiredKey)
+	}
+	if keyring.IsRevoked("official", KeyID(secondPublic)) {
+		t.Fatal("current key revoked by an expired key")
+	}
+
+	// Но может отозвать сам себя, не сдвигая версию списка
+	self := &types.RevocationList{
+		Repository:  "official",
+		Version:     100,
+		Revocations: []types.KeyRevocation{{KeyID: firstID, RevokedAt: time.Now().UTC()}},
+	}
+	if err := SignRevocationList(self, firstKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.ApplyRevocationList(self); err != nil {
+		t.Fatal(err)
+	}
+	if !keyring.IsRevoked("official", firstID) {
+		t.Error("self-revocation not applied")
+	}
+
+	// Отозванный ключ также не может отзывать другие ключи
+	if err := SignRevocationList(list, firstKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.ApplyRevocationList(list); !errors.Is(err, ErrRevokedKey) {
+		t.Errorf("ApplyRevocationList() = %v, want %v", err, ErrRevokedKey)
+	}
+
+	// Доверенный ключ по-прежнему принимает список версии 1
+	current := &types.RevocationList{
+		Repository:  "official",
+		Version:     1,
+		Revocations: []types.KeyRevocation{{KeyID: KeyID(otherPublic), RevokedAt: time.Now().UTC()}},
+	}
+	if err := SignRevocationList(current, secondKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.ApplyRevocationList(current); err != nil {
+		t.Fatal(err)
+	}
+	if !keyring.IsRevoked("official", KeyID(otherPublic)) {
+		t.Error("key not revoked by the trusted key")
+	}
+}
@@ -0,0 +1,393 @@
+package tuf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+// Ограничения размера загружаемых файлов, длина которых заранее неизвестна
+const (
+	maxRootLength      = 512 * 1024
+	maxTimestampLength = 64 * 1024
+	maxMetadataLength  = 16 * 1024 * 1024
+	maxRootRotations   = 1024
+)
+
+// ErrNotFound файл метаданных отсутствует в источнике
+var ErrNotFound = errors.New("metadata not found")
+
+// Fetcher загружает файлы метаданных репозитория
+type Fetcher interface {
+	// Fetch загружает файл name размером не более maxLength байт.
+	// Отсутствующий файл должен возвращать ошибку, оборачивающую ErrNotFound.
+	Fetch(name string, maxLength int64) ([]byte, error)
+}
+
+// LocalFetcher загружает метаданные из локальной директории (зеркало, тесты)
+type LocalFetcher struct {
+	Dir string
+}
+
+// Fetch загружает файл из директории
+func (f *LocalFetcher) Fetch(name string, maxLength int64) ([]byte, error) {
+	file, err := os.Open(filepath.Join(f.Dir, filepath.Base(name)))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxLength+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxLength {
+		return nil, fmt.Errorf("%s exceeds maximum length %d", name, maxLength)
+	}
+	return data, nil
+}
+
+// Client проверяет метаданные репозитория и хранит последние доверенные версии
+// в локальной директории. Начальный root.json доставляется вне канала обновлений.
+type Client struct {
+	dir     string
+	fetcher Fetcher
+	now     func() time.Time
+
+	root *types.RootMetadata
+}
+
+// NewClient создает клиента с доверенным root.json из директории metadataDir
+func NewClient(metadataDir string, fetcher Fetcher) (*Client, error) {
+	data, err := os.ReadFile(filepath.Join(metadataDir, RootFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trusted root: %w", err)
+	}
+
+	signed, err := parseSigned(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var root types.RootMetadata
+	if err := decodeRole(signed, types.RoleRoot, &root); err != nil {
+		return nil, err
+	}
+	// Доверенный root должен быть подписан своими же ключами
+	if err := verifyRole(signed, &root, types.RoleRoot); err != nil {
+		return nil, fmt.Errorf("trusted root: %w", err)
+	}
+
+	return &Client{
+		dir:     metadataDir,
+		fetcher: fetcher,
+		now:     time.Now,
+		root:    &root,
+	}, nil
+}
+
+// SetClock задает источник текущего времени
+func (c *Client) SetClock(now func() time.Time) {
+	c.now = now
+}
+
+// Root возвращает текущие доверенные корневые метаданные
+func (c *Client) Root() *types.RootMetadata {
+	return c.root
+}
+
+// Update обновляет метаданные и возвращает проверенный индекс репозитория.
+// Порядок проверки: root, timestamp, snapshot, targets, индекс.
+func (c *Client) Update() (*types.RepositoryIndex, error) {
+	now := c.now()
+
+	if err := c.updateRoot(); err != nil {
+		return nil, err
+	}
+	if err := checkExpiry(types.RoleRoot, c.root.Expires, now); err != nil {
+		return nil, err
+	}
+
+	timestamp, err := c.updateTimestamp(now)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := c.updateSnapshot(timestamp, now)
+	if err != nil {
+		return nil, err
+	}
+
+	targets, err := c.updateTargets(snapshot, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.fetchIndex(targets)
+}
+
+// updateRoot последовательно применяет новые версии root (N+1).root.json
+func (c *Client) updateRoot() error {
+	rotated := false
+
+	for i := 0; i < maxRootRotations; i++ {
+		next := c.root.Version + 1
+		name := fmt.Sprintf("%d.%s", next, RootFile)
+
+		data, err := c.fetcher.Fetch(name, maxRootLength)
+		if errors.Is(err, ErrNotFound) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to fetch %s: %w", name, err)
+		}
+
+		signed, err := parseSigned(data)
+		if err != nil {
+			return err
+		}
+
+		// Новый root подписан и старыми, и новыми ключами root
+		if err := verifyRole(signed, c.root, types.RoleRoot); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		var root types.RootMetadata
+		if err := decodeRole(signed, types.RoleRoot, &root); err != nil {
+			return err
+		}
+		if err := verifyRole(signed, &root, types.RoleRoot); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if root.Version != next {
+			return fmt.Errorf("%w: %s has version %d", ErrVersionMismatch, name, root.Version)
+		}
+
+		if err := writeFileAtomic(filepath.Join(c.dir, RootFile), data); err != nil {
+			return err
+		}
+		c.root = &root
+		rotated = true
+	}
+
+	// После смены root ключи timestamp/snapshot могли быть заменены,
+	// поэтому ранее доверенные версии больше не используются
+	if rotated {
+		for _, name := range []string{TimestampFile, SnapshotFile} {
+			if err := os.Remove(filepath.Join(c.dir, name)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// updateTimestamp загружает и проверяет timestamp
+func (c *Client) updateTimestamp(now time.Time) (*types.TimestampMetadata, error) {
+	data, err := c.fetcher.Fetch(TimestampFile, maxTimestampLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", TimestampFile, err)
+	}
+
+	signed, err := parseSigned(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyRole(signed, c.root, types.RoleTimestamp); err != nil {
+		return nil, err
+	}
+
+	var timestamp types.TimestampMetadata
+	if err := decodeRole(signed, types.RoleTimestamp, &timestamp); err != nil {
+		return nil, err
+	}
+
+	trusted := c.trustedVersion(TimestampFile)
+	if timestamp.Version < trusted {
+		return nil, fmt.Errorf("%w: timestamp version %d is older than trusted %d", ErrRollback, timestamp.Version, trusted)
+	}
+
+	snapshotMeta, ok := timestamp.Meta[SnapshotFile]
+	if !ok {
+		return nil, fmt.Errorf("timestamp does not reference %s", SnapshotFile)
+	}
+	if previous := c.trustedTimestampSnapshot(); snapshotMeta.Version < previous {
+		return nil, fmt.Errorf("%w: snapshot version %d is older than trusted %d", ErrRollback, snapshotMeta.Version, previous)
+	}
+
+	if err := checkExpiry(types.RoleTimestamp, timestamp.Expires, now); err != nil {
+		return nil, err
+	}
+
+	if err := writeFileAtomic(filepath.Join(c.dir, TimestampFile), data); err != nil {
+		return nil, err
+	}
+	return &timestamp, nil
+}
+
+// updateSnapshot загружает и проверяет snapshot, на который указывает timestamp
+func (c *Client) updateSnapshot(timestamp *types.TimestampMetadata, now time.Time) (*types.SnapshotMetadata, error) {
+	meta := timestamp.Meta[SnapshotFile]
+
+	data, err := c.fetcher.Fetch(SnapshotFile, maxLength(meta.Length))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", SnapshotFile, err)
+	}
+	if err := checkHashes(SnapshotFile, data, meta.Length, meta.Hashes); err != nil {
+		return nil, err
+	}
+
+	signed, err := parseSigned(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyRole(signed, c.root, types.RoleSnapshot); err != nil {
+		return nil, err
+	}
+
+	var snapshot types.SnapshotMetadata
+	if err := decodeRole(signed, types.RoleSnapshot, &snapshot); err != nil {
+		return nil, err
+	}
+	if snapshot.Version != meta.Version {
+		return nil, fmt.Errorf("%w: snapshot version %d, timestamp expects %d", ErrVersionMismatch, snapshot.Version, meta.Version)
+	}
+
+	targetsMeta, ok := snapshot.Meta[TargetsFile]
+	if !ok {
+		return nil, fmt.Errorf("snapshot does not reference %s", TargetsFile)
+	}
+	if previous := c.trustedSnapshotTargets(); targetsMeta.Version < previous {
+		return nil, fmt.Errorf("%w: targets version %d is older than trusted %d", ErrRollback, targetsMeta.Version, previous)
+	}
+
+	if err := checkExpiry(types.RoleSnapshot, snapshot.Expires, now); err != nil {
+		return nil, err
+	}
+
+	if err := writeFileAtomic(filepath.Join(c.dir, SnapshotFile), data); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// updateTargets загружает и проверяет targets, на который указывает snapshot
+func (c *Client) updateTargets(snapshot *types.SnapshotMetadata, now time.Time) (*types.TargetsMetadata, error) {
+	meta := snapshot.Meta[TargetsFile]
+
+	data, err := c.fetcher.Fetch(TargetsFile, maxLength(meta.Length))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", TargetsFile, err)
+	}
+	if err := checkHashes(TargetsFile, data, meta.Length, meta.Hashes); err != nil {
+		return nil, err
+	}
+
+	signed, err := parseSigned(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyRole(signed, c.root, types.RoleTargets); err != nil {
+		return nil, err
+	}
+
+	var targets types.TargetsMetadata
+	if err := decodeRole(signed, types.RoleTargets, &targets); err != nil {
+		return nil, err
+	}
+	if targets.Version != meta.Version {
+		return nil, fmt.Errorf("%w: targets version %d, snapshot expects %d", ErrVersionMismatch, targets.Version, meta.Version)
+	}
+
+	if err := checkExpiry(types.RoleTargets, targets.Expires, now); err != nil {
+		return nil, err
+	}
+
+	if err := writeFileAtomic(filepath.Join(c.dir, TargetsFile), data); err != nil {
+		return nil, err
+	}
+	return &targets, nil
+}
+
+// fetchIndex загружает индекс репозитория и сверяет его с targets
+func (c *Client) fetchIndex(targets *types.TargetsMetadata) (*types.RepositoryIndex, error) {
+	target, ok := targets.Targets[IndexTarget]
+	if !ok {
+		return nil, fmt.Errorf("targets do not reference %s", IndexTarget)
+	}
+	if target.Length <= 0 || len(target.Hashes) == 0 {
+		return nil, fmt.Errorf("targets entry for %s has no length or hashes", IndexTarget)
+	}
+
+	data, err := c.fetcher.Fetch(IndexTarget, target.Length)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", IndexTarget, err)
+	}
+	if err := checkHashes(IndexTarget, data, target.Length, target.Hashes); err != nil {
+		return nil, err
+	}
+
+	var index types.RepositoryIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse index: %w", err)
+	}
+	return &index, nil
+}
+
+// trustedVersion возвращает версию ранее доверенного файла (0, если его нет)
+func (c *Client) trustedVersion(name string) int {
+	data, err := os.ReadFile(filepath.Join(c.dir, name))
+	if err != nil {
+		return 0
+	}
+	return signedVersion(data)
+}
+
+// trustedTimestampSnapshot возвращает версию snapshot из доверенного timestamp
+func (c *Client) trustedTimestampSnapshot() int {
+	var timestamp types.TimestampMetadata
+	if !c.readTrusted(TimestampFile, &timestamp) {
+		return 0
+	}
+	return timestamp.Meta[SnapshotFile].Version
+}
+
+// trustedSnapshotTargets возвращает версию targets из доверенного snapshot
+func (c *Client) trustedSnapshotTargets() int {
+	var snapshot types.SnapshotMetadata
+	if !c.readTrusted(SnapshotFile, &snapshot) {
+		return 0
+	}
+	return snapshot.Meta[TargetsFile].Version
+}
+
+// readTrusted читает ранее проверенный документ из локальной директории
+func (c *Client) readTrusted(name string, out any) bool {
+	data, err := os.ReadFile(filepath.Join(c.dir, name))
+	if err != nil {
+		return false
+	}
+	signed, err := parseSigned(data)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(signed.Signed, out) == nil
+}
+
+// maxLength возвращает ограничение размера по заявленной длине
+func maxLength(length int64) int64 {
+	if length > 0 {
+		return length
+	}
+	return maxMetadataLength
+}
@@ -0,0 +1,245 @@
+package tuf
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/criage-oss/criage-common/signing"
+	"github.com/criage-oss/criage-common/types"
+)
+
+// roles роли метаданных
+var roles = []types.MetadataRole{types.RoleRoot, types.RoleTargets, types.RoleSnapshot, types.RoleTimestamp}
+
+// testRepository репозиторий с издателем и клиентом, который ему доверяет
+type testRepository struct {
+	dir       string
+	keys      map[types.MetadataRole][]ed25519.PrivateKey
+	publisher *Publisher
+	client    *Client
+	now       time.Time
+}
+
+// newTestRepository публикует root версии 1 и индекс и создает клиента
+func newTestRepository(t *testing.T) *testRepository {
+	t.Helper()
+	repo := &testRepository{
+		dir:  t.TempDir(),
+		keys: generateRoleKeys(t),
+		now:  time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	repo.publisher = repo.newPublisher()
+	if err := repo.publisher.WriteRoot(NewRoot(1, publicKeys(repo.keys), nil, repo.now.Add(DefaultRootExpiry))); err != nil {
+		t.Fatal(err)
+	}
+	repo.publish(t)
+
+	clientDir := t.TempDir()
+	data, err := os.ReadFile(filepath.Join(repo.dir, RootFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(clientDir, RootFile), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo.client, err = NewClient(clientDir, &LocalFetcher{Dir: repo.dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.client.SetClock(func() time.Time { return repo.now })
+	return repo
+}
+
+// newPublisher создает издателя с текущими ключами и часами репозитория
+func (r *testRepository) newPublisher() *Publisher {
+	publisher := NewPublisher(r.dir, r.keys)
+	publisher.SetClock(func() time.Time { return r.now })
+	return publisher
+}
+
+// publish публикует индекс с одним пакетом
+func (r *testRepository) publish(t *testing.T) {
+	t.Helper()
+	index := &types.RepositoryIndex{LastUpdated: r.now, TotalPackages: 1}
+	if err := r.publisher.Publish(index); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// save возвращает содержимое опубликованных файлов метаданных
+func (r *testRepository) save(t *testing.T, names ...string) map[string][]byte {
+	t.Helper()
+	files := make(map[string][]byte, len(names))
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(r.dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[name] = data
+	}
+	return files
+}
+
+// restore возвращает сохраненные файлы метаданных в репозиторий
+func (r *testRepository) restore(t *testing.T, files map[string][]byte) {
+	t.Helper()
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(r.dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// generateRoleKeys создает по ключу на каждую роль
+func generateRoleKeys(t *testing.T) map[types.MetadataRole][]ed25519.PrivateKey {
+	t.Helper()
+	keys := make(map[types.MetadataRole][]ed25519.PrivateKey)
+	for _, role := range roles {
+		_, privateKey, err := signing.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[role] = []ed25519.PrivateKey{privateKey}
+	}
+	return keys
+}
+
+// publicKeys возвращает открытые ключи ролей
+func publicKeys(keys map[types.MetadataRole][]ed25519.PrivateKey) map[types.MetadataRole][]ed25519.PublicKey {
+	public := make(map[types.MetadataRole][]ed25519.PublicKey)
+	for role, roleKeys := range keys {
+		for _, key := range roleKeys {
+			public[role] = append(public[role], key.Public().(ed25519.PublicKey))
+		}
+	}
+	return public
+}
+
+func TestClientUpdate(t *testing.T) {
+	repo := newTestRepository(t)
+
+	index, err := repo.client.Update()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index.TotalPackages != 1 {
+		t.Errorf("TotalPackages = %d, want 1", index.TotalPackages)
+	}
+
+	// Повторное обновление без новых версий допустимо
+	if _, err := repo.client.Update(); err != nil {
+		t.Errorf("second Update() = %v", err)
+	}
+}
+
+func TestClientRejectsTimestampRollback(t *testing.T) {
+	repo := newTestRepository(t)
+	old := repo.save(t, TimestampFile, SnapshotFile, TargetsFile, IndexTarget)
+
+	repo.publish(t)
+	if _, err := repo.client.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Зеркало отдает прежние, действительно подписанные метаданные
+	repo.restore(t, old)
+	if _, err := repo.client.Update(); !errors.Is(err, ErrRollback) {
+		t.Errorf("Update() = %v, want %v", err, ErrRollback)
+	}
+}
+
+func TestClientRejectsSnapshotRollback(t *testing.T) {
+	repo := newTestRepository(t)
+	old := repo.save(t, SnapshotFile, TargetsFile, IndexTarget)
+
+	repo.publish(t)
+	if _, err := repo.client.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Новый timestamp указывает на прежний snapshot
+	repo.restore(t, old)
+	if err := repo.publisher.RefreshTimestamp(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.client.Update(); !errors.Is(err, ErrRollback) {
+		t.Errorf("Update() = %v, want %v", err, ErrRollback)
+	}
+}
+
+func TestClientRejectsExpiredTimestamp(t *testing.T) {
+	repo := newTestRepository(t)
+	if _, err := repo.client.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Зеркало перестало обновлять timestamp (атака заморозки)
+	repo.now = repo.now.Add(DefaultTimestampExpiry + time.Minute)
+	if _, err := repo.client.Update(); !errors.Is(err, ErrExpired) {
+		t.Errorf("Update() = %v, want %v", err, ErrExpired)
+	}
+
+	if err := repo.publisher.RefreshTimestamp(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.client.Update(); err != nil {
+		t.Errorf("Update() after refresh = %v", err)
+	}
+}
+
+func TestClientRootRotation(t *testing.T) {
+	repo := newTestRepository(t)
+	if _, err := repo.client.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Смена всех ключей: root версии 2 подписан новым и прежним ключом root
+	oldRootKeys := repo.keys[types.RoleRoot]
+	repo.keys = generateRoleKeys(t)
+	repo.publisher = repo.newPublisher()
+	root := NewRoot(2, publicKeys(repo.keys), nil, repo.now.Add(DefaultRootExpiry))
+	if err := repo.publisher.WriteRoot(root, oldRootKeys...); err != nil {
+		t.Fatal(err)
+	}
+	repo.publish(t)
+
+	if _, err := repo.client.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if version := repo.client.Root().Version; version != 2 {
+		t.Errorf("root version = %d, want 2", version)
+	}
+
+	// Доверенный root сохранен и используется новым клиентом
+	client, err := NewClient(repo.client.dir, &LocalFetcher{Dir: repo.dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version := client.Root().Version; version != 2 {
+		t.Errorf("stored root version = %d, want 2", version)
+	}
+}
+
+func TestClientRejectsRootWithoutPreviousSignature(t *testing.T) {
+	repo := newTestRepository(t)
+
+	// root версии 2 подписан только новыми ключами
+	repo.keys = generateRoleKeys(t)
+	repo.publisher = repo.newPublisher()
+	root := NewRoot(2, publicKeys(repo.keys), nil, repo.now.Add(DefaultRootExpiry))
+	if err := repo.publisher.WriteRoot(root); err != nil {
+		t.Fatal(err)
+	}
+	repo.publish(t)
+
+	if _, err := repo.client.Update(); !errors.Is(err, ErrThreshold) {
+		t.Errorf("Update() = %v, want %v", err, ErrThreshold)
+	}
+	if version := repo.client.Root().Version; version != 1 {
+		t.Errorf("root version = %d, want 1", version)
+	}
+}
@@ -0,0 +1,178 @@
+// Package tuf реализует подписанные метаданные репозитория по модели TUF
+// (root, targets, snapshot, timestamp) вокруг types.RepositoryIndex и
+// клиентскую проверку, обнаруживающую атаки отката и заморозки.
+package tuf
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/criage-oss/criage-common/signing"
+	"github.com/criage-oss/criage-common/types"
+)
+
+// Имена файлов метаданных
+const (
+	RootFile      = "root.json"
+	TargetsFile   = "targets.json"
+	SnapshotFile  = "snapshot.json"
+	TimestampFile = "timestamp.json"
+
+	// IndexTarget имя файла индекса репозитория среди целевых файлов
+	IndexTarget = "index.json"
+)
+
+var (
+	// ErrThreshold недостаточно действительных подписей роли
+	ErrThreshold = errors.New("signature threshold not met")
+	// ErrRollback получены метаданные более старой версии, чем доверенные
+	ErrRollback = errors.New("metadata rollback detected")
+	// ErrExpired срок действия метаданных истек (атака заморозки)
+	ErrExpired = errors.New("metadata expired")
+	// ErrHashMismatch длина или хеш файла не совпадает с заявленными
+	ErrHashMismatch = errors.New("metadata hash mismatch")
+	// ErrVersionMismatch версия файла не совпадает с указанной в родительских метаданных
+	ErrVersionMismatch = errors.New("metadata version mismatch")
+)
+
+// Sign сериализует документ роли и подписывает его ключами
+func Sign(signed any, keys ...ed25519.PrivateKey) (*types.SignedMetadata, error) {
+	raw, err := json.Marshal(signed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode metadata: %w", err)
+	}
+
+	metadata := &types.SignedMetadata{Signed: raw}
+	for _, key := range keys {
+		publicKey, ok := key.Public().(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("invalid private key")
+		}
+		metadata.Signatures = append(metadata.Signatures, types.MetadataSignature{
+			KeyID:     signing.KeyID(publicKey),
+			Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, raw)),
+		})
+	}
+
+	return metadata, nil
+}
+
+// MetadataKeyFor возвращает описание открытого ключа для корневых метаданных
+func MetadataKeyFor(publicKey ed25519.PublicKey) (string, types.MetadataKey) {
+	return signing.KeyID(publicKey), types.MetadataKey{
+		Algorithm: types.SignatureAlgorithmEd25519,
+		PublicKey: signing.EncodePublicKey(publicKey),
+	}
+}
+
+// verifyRole проверяет, что документ подписан не менее чем threshold ключами роли
+func verifyRole(metadata *types.SignedMetadata, root *types.RootMetadata, role types.MetadataRole) error {
+	roleKeys, ok := root.Roles[role]
+	if !ok {
+		return fmt.Errorf("role %s is not defined in root metadata", role)
+	}
+	if roleKeys.Threshold < 1 {
+		return fmt.Errorf("role %s has invalid threshold %d", role, roleKeys.Threshold)
+	}
+
+	allowed := make(map[string]bool, len(roleKeys.KeyIDs))
+	for _, keyID := range roleKeys.KeyIDs {
+		allowed[keyID] = true
+	}
+
+	valid := make(map[string]bool)
+	for _, signature := range metadata.Signatures {
+		if !allowed[signature.KeyID] || valid[signature.KeyID] {
+			continue
+		}
+
+		key, ok := root.Keys[signature.KeyID]
+		if !ok || key.Algorithm != types.SignatureAlgorithmEd25519 {
+			continue
+		}
+		publicKey, err := signing.ParsePublicKey(key.PublicKey)
+		if err != nil || signing.KeyID(publicKey) != signature.KeyID {
+			continue
+		}
+
+		raw, err := base64.StdEncoding.DecodeString(signature.Signature)
+		if err != nil {
+			continue
+		}
+		if ed25519.Verify(publicKey, metadata.Signed, raw) {
+			valid[signature.KeyID] = true
+		}
+	}
+
+	if len(valid) < roleKeys.Threshold {
+		return fmt.Errorf("%w: role %s has %d of %d required signatures", ErrThreshold, role, len(valid), roleKeys.Threshold)
+	}
+	return nil
+}
+
+// parseSigned разбирает подписанный документ
+func parseSigned(data []byte) (*types.SignedMetadata, error) {
+	var metadata types.SignedMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse signed metadata: %w", err)
+	}
+	return &metadata, nil
+}
+
+// decodeRole разбирает содержимое документа и проверяет его тип
+func decodeRole(metadata *types.SignedMetadata, role types.MetadataRole, out any) error {
+	if err := json.Unmarshal(metadata.Signed, out); err != nil {
+		return fmt.Errorf("failed to parse %s metadata: %w", role, err)
+	}
+
+	var header struct {
+		Type string `json:"_type"`
+	}
+	if err := json.Unmarshal(metadata.Signed, &header); err != nil {
+		return err
+	}
+	if types.MetadataRole(header.Type) != role {
+		return fmt.Errorf("expected %s metadata, got %q", role, header.Type)
+	}
+	return nil
+}
+
+// checkExpiry проверяет срок действия документа
+func checkExpiry(role types.MetadataRole, expires, now time.Time) error {
+	if !now.Before(expires) {
+		return fmt.Errorf("%w: %s metadata expired at %s", ErrExpired, role, expires.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// fileHashes вычисляет длину и хеши данных
+func fileHashes(data []byte) (int64, map[string]string) {
+	sum := sha256.Sum256(data)
+	return int64(len(data)), map[string]string{"sha256": hex.EncodeToString(sum[:])}
+}
+
+// checkHashes сверяет данные с ожидаемой длиной и хешами
+func checkHashes(name string, data []byte, length int64, hashes map[string]string) error {
+	if length > 0 && int64(len(data)) != length {
+		return fmt.Errorf("%w: %s has length %d, expected %d", ErrHashMismatch, name, len(data), length)
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	expected, ok := hashes["sha256"]
+	if !ok {
+		return fmt.Errorf("%w: %s has no supported hash", ErrHashMismatch, name)
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != expected {
+		return fmt.Errorf("%w: %s sha256 does not match", ErrHashMismatch, name)
+	}
+	return nil
+}
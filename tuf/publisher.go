@@ -0,0 +1,249 @@
+package tuf
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/criage-oss/criage-common/types"
+)
+
+// Сроки действия метаданных по умолчанию
+const (
+	DefaultRootExpiry      = 365 * 24 * time.Hour
+	DefaultTargetsExpiry   = 90 * 24 * time.Hour
+	DefaultSnapshotExpiry  = 7 * 24 * time.Hour
+	DefaultTimestampExpiry = 24 * time.Hour
+)
+
+// Publisher публикует подписанные метаданные в директорию репозитория
+type Publisher struct {
+	dir    string
+	keys   map[types.MetadataRole][]ed25519.PrivateKey
+	expiry map[types.MetadataRole]time.Duration
+	now    func() time.Time
+}
+
+// NewPublisher создает издателя метаданных с ключами ролей
+func NewPublisher(dir string, keys map[types.MetadataRole][]ed25519.PrivateKey) *Publisher {
+	return &Publisher{
+		dir:  dir,
+		keys: keys,
+		expiry: map[types.MetadataRole]time.Duration{
+			types.RoleRoot:      DefaultRootExpiry,
+			types.RoleTargets:   DefaultTargetsExpiry,
+			types.RoleSnapshot:  DefaultSnapshotExpiry,
+			types.RoleTimestamp: DefaultTimestampExpiry,
+		},
+		now: time.Now,
+	}
+}
+
+// SetExpiry задает срок действия метаданных роли
+func (p *Publisher) SetExpiry(role types.MetadataRole, expiry time.Duration) {
+	p.expiry[role] = expiry
+}
+
+// SetClock задает источник текущего времени
+func (p *Publisher) SetClock(now func() time.Time) {
+	p.now = now
+}
+
+// NewRoot создает корневые метаданные для открытых ключей ролей
+func NewRoot(version int, publicKeys map[types.MetadataRole][]ed25519.PublicKey, thresholds map[types.MetadataRole]int, expires time.Time) *types.RootMetadata {
+	root := &types.RootMetadata{
+		Type:    types.RoleRoot,
+		Version: version,
+		Expires: expires.UTC(),
+		Keys:    make(map[string]types.MetadataKey),
+		Roles:   make(map[types.MetadataRole]types.RoleKeys),
+	}
+
+	for role, keys := range publicKeys {
+		roleKeys := types.RoleKeys{Threshold: thresholds[role]}
+		if roleKeys.Threshold < 1 {
+			roleKeys.Threshold = 1
+		}
+		for _, publicKey := range keys {
+			keyID, key := MetadataKeyFor(publicKey)
+			root.Keys[keyID] = key
+			roleKeys.KeyIDs = append(roleKeys.KeyIDs, keyID)
+		}
+		root.Roles[role] = roleKeys
+	}
+
+	return root
+}
+
+// WriteRoot подписывает и публикует корневые метаданные как root.json и <version>.root.json.
+// При смене ключей root новую версию нужно дополнительно подписать ключами предыдущей.
+func (p *Publisher) WriteRoot(root *types.RootMetadata, previousKeys ...ed25519.PrivateKey) error {
+	keys := append(append([]ed25519.PrivateKey(nil), p.keys[types.RoleRoot]...), previousKeys...)
+
+	signed, err := Sign(root, keys...)
+	if err != nil {
+		return err
+	}
+
+	// Без отступов: MarshalIndent переформатировал бы подписанные байты Signed
+	data, err := json.Marshal(signed)
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(filepath.Join(p.dir, fmt.Sprintf("%d.%s", root.Version, RootFile)), data); err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(p.dir, RootFile), data)
+}
+
+// Publish публикует индекс репозитория и обновляет targets, snapshot и timestamp
+func (p *Publisher) Publish(index *types.RepositoryIndex) error {
+	indexData, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode index: %w", err)
+	}
+
+	now := p.now().UTC()
+
+	// targets
+	indexLength, indexHashes := fileHashes(indexData)
+	targets := &types.TargetsMetadata{
+		Type:    types.RoleTargets,
+		Version: p.currentVersion(TargetsFile) + 1,
+		Expires: now.Add(p.expiry[types.RoleTargets]),
+		Targets: map[string]types.TargetFile{
+			IndexTarget: {Length: indexLength, Hashes: indexHashes},
+		},
+	}
+	targetsData, err := p.sign(types.RoleTargets, targets)
+	if err != nil {
+		return err
+	}
+
+	// snapshot
+	targetsLength, targetsHashes := fileHashes(targetsData)
+	snapshot := &types.SnapshotMetadata{
+		Type:    types.RoleSnapshot,
+		Version: p.currentVersion(SnapshotFile) + 1,
+		Expires: now.Add(p.expiry[types.RoleSnapshot]),
+		Meta: map[string]types.MetaFile{
+			TargetsFile: {Version: targets.Version, Length: targetsLength, Hashes: targetsHashes},
+		},
+	}
+	snapshotData, err := p.sign(types.RoleSnapshot, snapshot)
+	if err != nil {
+		return err
+	}
+
+	// Индекс и метаданные записываются в порядке зависимостей, timestamp последним
+	if err := writeFileAtomic(filepath.Join(p.dir, IndexTarget), indexData); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(p.dir, TargetsFile), targetsData); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(p.dir, SnapshotFile), snapshotData); err != nil {
+		return err
+	}
+
+	return p.writeTimestamp(snapshot.Version, snapshotData)
+}
+
+// RefreshTimestamp переподписывает timestamp с новым сроком действия.
+// Должен вызываться чаще, чем истекает DefaultTimestampExpiry.
+func (p *Publisher) RefreshTimestamp() error {
+	snapshotData, err := os.ReadFile(filepath.Join(p.dir, SnapshotFile))
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	return p.writeTimestamp(p.currentVersion(SnapshotFile), snapshotData)
+}
+
+// writeTimestamp публикует timestamp, указывающий на snapshot
+func (p *Publisher) writeTimestamp(snapshotVersion int, snapshotData []byte) error {
+	snapshotLength, snapshotHashes := fileHashes(snapshotData)
+	timestamp := &types.TimestampMetadata{
+		Type:    types.RoleTimestamp,
+		Version: p.currentVersion(TimestampFile) + 1,
+		Expires: p.now().UTC().Add(p.expiry[types.RoleTimestamp]),
+		Meta: map[string]types.MetaFile{
+			SnapshotFile: {Version: snapshotVersion, Length: snapshotLength, Hashes: snapshotHashes},
+		},
+	}
+
+	data, err := p.sign(types.RoleTimestamp, timestamp)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(p.dir, TimestampFile), data)
+}
+
+// sign подписывает документ ключами роли
+func (p *Publisher) sign(role types.MetadataRole, signed any) ([]byte, error) {
+	keys := p.keys[role]
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no keys for role %s", role)
+	}
+
+	metadata, err := Sign(signed, keys...)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(metadata)
+}
+
+// currentVersion возвращает версию опубликованного файла метаданных (0, если его нет)
+func (p *Publisher) currentVersion(name string) int {
+	data, err := os.ReadFile(filepath.Join(p.dir, name))
+	if err != nil {
+		return 0
+	}
+	return signedVersion(data)
+}
+
+// signedVersion читает версию из подписанного документа без проверки подписей
+func signedVersion(data []byte) int {
+	metadata, err := parseSigned(data)
+	if err != nil {
+		return 0
+	}
+
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(metadata.Signed, &header); err != nil {
+		return 0
+	}
+	return header.Version
+}
+
+// writeFileAtomic записывает файл через временный файл и переименование
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Chmod(0644); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), path)
+}
@@ -0,0 +1,125 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/criage-oss/criage-common/semver"
+)
+
+// IncompatibleError пакет не может быть обработан текущей версией criage
+type IncompatibleError struct {
+	Package string
+	Version string
+
+	// Required минимальная версия criage, необходимая пакету
+	Required string
+	// Current версия criage, которая обрабатывает пакет
+	Current string
+	// Reason причина несовместимости
+	Reason string
+
+	// Metadata метаданные пакета, если они были прочитаны из архива
+	Metadata *PackageMetadata
+}
+
+func (e *IncompatibleError) Error() string {
+	name := "package"
+	if e.Package != "" {
+		name = "package " + e.Package
+		if e.Version != "" {
+			name += "@" + e.Version
+		}
+	}
+	return fmt.Sprintf("%s is incompatible with criage %s: %s", name, e.Current, e.Reason)
+}
+
+// CheckCompatibility проверяет, что манифест поддерживается версией criage toolVersion.
+// Версии, не соответствующие semver (например, "dev"), проверку пропускают.
+func (m *PackageManifest) CheckCompatibility(toolVersion string) error {
+	if m == nil || strings.TrimSpace(m.MinVersion) == "" {
+		return nil
+	}
+
+	current, ok := toolSemver(toolVersion)
+	if !ok {
+		return nil
+	}
+
+	required, err := semver.ParseTolerant(m.MinVersion)
+	if err != nil {
+		if m.Name == "" {
+			return fmt.Errorf("invalid minVersion: %w", err)
+		}
+		return fmt.Errorf("invalid minVersion of package %s: %w", m.Name, err)
+	}
+
+	if current.LessThan(required) {
+		return &IncompatibleError{
+			Package:  m.Name,
+			Version:  m.Version,
+			Required: required.String(),
+			Current:  toolVersion,
+			Reason:   fmt.Sprintf("requires criage >= %s", required),
+		}
+	}
+	return nil
+}
+
+// CheckCompatibility проверяет метаданные архива: minVersion манифеста и
+// версию criage, создавшей архив. Архив, созданный более новой мажорной
+// версией, может использовать неизвестный формат и считается несовместимым.
+func (m *PackageMetadata) CheckCompatibility(toolVersion string) error {
+	if m == nil {
+		return nil
+	}
+
+	var name, version string
+	if m.PackageManifest != nil {
+		name, version = m.PackageManifest.Name, m.PackageManifest.Version
+	}
+
+	if err := m.PackageManifest.CheckCompatibility(toolVersion); err != nil {
+		if incompatible, ok := err.(*IncompatibleError); ok {
+			incompatible.Metadata = m
+		}
+		return err
+	}
+
+	if m.CreatedBy != "" && m.CreatedBy != "criage" {
+		return nil
+	}
+	current, ok := toolSemver(toolVersion)
+	if !ok {
+		return nil
+	}
+	creator, ok := toolSemver(m.Version)
+	if !ok {
+		return nil
+	}
+
+	if creator.Major > current.Major {
+		return &IncompatibleError{
+			Package:  name,
+			Version:  version,
+			Required: fmt.Sprintf("%d.0.0", creator.Major),
+			Current:  toolVersion,
+			Reason:   fmt.Sprintf("archive was created by criage %s with a newer format", m.Version),
+			Metadata: m,
+		}
+	}
+	return nil
+}
+
+// toolSemver разбирает версию criage, игнорируя нестандартные сборки
+func toolSemver(value string) (*semver.Version, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, false
+	}
+	v, err := semver.ParseTolerant(value)
+	if err != nil {
+		return nil, false
+	}
+	return v, true
+}
@@ -0,0 +1,81 @@
+package types
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestManifestCheckCompatibility(t *testing.T) {
+	tests := []struct {
+		name         string
+		minVersion   string
+		toolVersion  string
+		incompatible bool
+		wantErr      bool
+	}{
+		{"no minVersion", "", "1.0.0", false, false},
+		{"satisfied", "1.2.0", "1.2.0", false, false},
+		{"newer tool", "1.2", "2.0.0", false, false},
+		{"older tool", "1.3.0", "1.2.9", true, true},
+		{"tool with v prefix", "1.3.0", "v1.2.0", true, true},
+		{"development build", "9.0.0", "dev", false, false},
+		{"unknown tool version", "9.0.0", "", false, false},
+		{"invalid minVersion", "latest", "1.0.0", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest := &PackageManifest{Name: "app", Version: "1.0.0", MinVersion: tt.minVersion}
+			err := manifest.CheckCompatibility(tt.toolVersion)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckCompatibility(%q) = %v, wantErr %v", tt.toolVersion, err, tt.wantErr)
+			}
+			var incompatible *IncompatibleError
+			if errors.As(err, &incompatible) != tt.incompatible {
+				t.Fatalf("CheckCompatibility(%q) = %v, want IncompatibleError %v", tt.toolVersion, err, tt.incompatible)
+			}
+			if incompatible != nil && (incompatible.Package != "app" || incompatible.Required != "1.3.0" || incompatible.Current != tt.toolVersion) {
+				t.Errorf("IncompatibleError = %+v", incompatible)
+			}
+		})
+	}
+
+	var nilManifest *PackageManifest
+	if err := nilManifest.CheckCompatibility("1.0.0"); err != nil {
+		t.Errorf("nil manifest: %v", err)
+	}
+}
+
+func TestMetadataCheckCompatibility(t *testing.T) {
+	tests := []struct {
+		name         string
+		metadata     *PackageMetadata
+		toolVersion  string
+		incompatible bool
+	}{
+		{"same major", &PackageMetadata{CreatedBy: "criage", Version: "1.9.0"}, "1.2.0", false},
+		{"older major", &PackageMetadata{CreatedBy: "criage", Version: "1.0.0"}, "2.0.0", false},
+		{"newer major", &PackageMetadata{CreatedBy: "criage", Version: "2.0.0"}, "1.9.0", true},
+		{"creator without name", &PackageMetadata{Version: "3.0.0"}, "1.0.0", true},
+		{"other creator", &PackageMetadata{CreatedBy: "other-tool", Version: "5.0.0"}, "1.0.0", false},
+		{"development creator", &PackageMetadata{CreatedBy: "criage", Version: "dev"}, "1.0.0", false},
+		{"development tool", &PackageMetadata{CreatedBy: "criage", Version: "2.0.0"}, "dev", false},
+		{"manifest minVersion", &PackageMetadata{
+			CreatedBy:       "criage",
+			Version:         "1.0.0",
+			PackageManifest: &PackageManifest{Name: "app", Version: "1.0.0", MinVersion: "1.5.0"},
+		}, "1.4.0", true},
+		{"nil metadata", nil, "1.0.0", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.metadata.CheckCompatibility(tt.toolVersion)
+			var incompatible *IncompatibleError
+			if errors.As(err, &incompatible) != tt.incompatible || (!tt.incompatible && err != nil) {
+				t.Fatalf("CheckCompatibility(%q) = %v, want incompatible %v", tt.toolVersion, err, tt.incompatible)
+			}
+			if incompatible != nil && incompatible.Metadata != tt.metadata {
+				t.Error("IncompatibleError does not carry the metadata")
+			}
+		})
+	}
+}
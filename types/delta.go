@@ -0,0 +1,46 @@
+package types
+
+import "time"
+
+// DeltaOperation тип операции над файлом при применении дельты
+type DeltaOperation string
+
+const (
+	// DeltaKeep файл не изменился и копируется из базовой версии
+	DeltaKeep DeltaOperation = "keep"
+	// DeltaAdd файл добавлен целиком (сжат zstd)
+	DeltaAdd DeltaOperation = "add"
+	// DeltaPatch файл закодирован zstd с базовой версией файла в качестве словаря
+	DeltaPatch DeltaOperation = "patch"
+	// DeltaDir директория целевой версии, в том числе пустая
+	DeltaDir DeltaOperation = "dir"
+)
+
+// DeltaManifest описание бинарной дельты между двумя версиями пакета
+type DeltaManifest struct {
+	FormatVersion int    `json:"formatVersion"`
+	Name          string `json:"name,omitempty"`
+	FromVersion   string `json:"fromVersion,omitempty"`
+	ToVersion     string `json:"toVersion,omitempty"`
+
+	// Контрольная сумма SHA-256 исходного архива. Целевой архив не
+	// восстанавливается побайтно: проверяется каждый восстановленный файл.
+	BaseChecksum string        `json:"baseChecksum"`
+	TargetFormat ArchiveFormat `json:"targetFormat"`
+
+	// Файлы и директории целевой версии; файлы базовой версии, отсутствующие
+	// в списке, удаляются
+	Files     []DeltaFile `json:"files"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+// DeltaFile операция над отдельным файлом целевой версии
+type DeltaFile struct {
+	Path         string         `json:"path"`
+	Op           DeltaOperation `json:"op"`
+	Mode         uint32         `json:"mode"`
+	Size         int64          `json:"size"`
+	Checksum     string         `json:"checksum"`
+	BaseChecksum string         `json:"baseChecksum,omitempty"`
+	Payload      string         `json:"payload,omitempty"`
+}
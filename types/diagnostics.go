@@ -0,0 +1,159 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity уровень важности диагностического сообщения
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Коды диагностических сообщений
+const (
+	CodeRequired          = "required"
+	CodeInvalidName       = "invalid-name"
+	CodeInvalidVersion    = "invalid-version"
+	CodeInvalidConstraint = "invalid-constraint"
+	CodeInvalidPattern    = "invalid-pattern"
+	CodeInvalidURL        = "invalid-url"
+	CodeInvalidValue      = "invalid-value"
+	CodeUnknownFormat     = "unknown-format"
+	CodeUnknownPlatform   = "unknown-platform"
+	CodeDuplicate         = "duplicate"
+	CodeSelfReference     = "self-reference"
+	CodeOverlap           = "overlap"
+	CodeEmpty             = "empty"
+)
+
+// Diagnostic сообщение о проблеме в манифесте
+type Diagnostic struct {
+	// Path путь к полю, например "dependencies.lodash" или "targets[1].arch"
+	Path       string   `json:"path"`
+	Severity   Severity `json:"severity"`
+	Code       string   `json:"code"`
+	Message    string   `json:"message"`
+	Suggestion string   `json:"suggestion,omitempty"`
+}
+
+func (d Diagnostic) String() string {
+	s := fmt.Sprintf("%s: %s", d.Severity, d.Message)
+	if d.Path != "" {
+		s = fmt.Sprintf("%s: %s: %s", d.Severity, d.Path, d.Message)
+	}
+	if d.Suggestion != "" {
+		s += " (" + d.Suggestion + ")"
+	}
+	return s
+}
+
+// Diagnostics список диагностических сообщений
+type Diagnostics []Diagnostic
+
+// HasErrors проверяет наличие сообщений уровня error
+func (d Diagnostics) HasErrors() bool {
+	for _, diag := range d {
+		if diag.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Errors возвращает сообщения уровня error
+func (d Diagnostics) Errors() Diagnostics {
+	return d.filter(SeverityError)
+}
+
+// Warnings возвращает сообщения уровня warning
+func (d Diagnostics) Warnings() Diagnostics {
+	return d.filter(SeverityWarning)
+}
+
+// Err возвращает *DiagnosticsError, если среди сообщений есть ошибки
+func (d Diagnostics) Err() error {
+	if !d.HasErrors() {
+		return nil
+	}
+	return &DiagnosticsError{Diagnostics: d}
+}
+
+func (d Diagnostics) filter(severity Severity) Diagnostics {
+	var result Diagnostics
+	for _, diag := range d {
+		if diag.Severity == severity {
+			result = append(result, diag)
+		}
+	}
+	return result
+}
+
+// withPrefix добавляет префикс к путям сообщений
+func (d Diagnostics) withPrefix(prefix string) Diagnostics {
+	result := make(Diagnostics, len(d))
+	for i, diag := range d {
+		diag.Path = joinPath(prefix, diag.Path)
+		result[i] = diag
+	}
+	return result
+}
+
+// DiagnosticsError манифест содержит ошибки
+type DiagnosticsError struct {
+	Diagnostics Diagnostics
+}
+
+func (e *DiagnosticsError) Error() string {
+	errs := e.Diagnostics.Errors()
+	lines := make([]string, 0, len(errs))
+	for _, diag := range errs {
+		line := diag.Message
+		if diag.Path != "" {
+			line = diag.Path + ": " + line
+		}
+		lines = append(lines, line)
+	}
+	return "invalid manifest:\n  - " + strings.Join(lines, "\n  - ")
+}
+
+// diagnostics накапливает сообщения при проверке
+type diagnostics struct {
+	list Diagnostics
+}
+
+func (d *diagnostics) add(severity Severity, path, code, message, suggestion string) {
+	d.list = append(d.list, Diagnostic{
+		Path:       path,
+		Severity:   severity,
+		Code:       code,
+		Message:    message,
+		Suggestion: suggestion,
+	})
+}
+
+func (d *diagnostics) errorf(path, code, suggestion, format string, args ...any) {
+	d.add(SeverityError, path, code, fmt.Sprintf(format, args...), suggestion)
+}
+
+func (d *diagnostics) warnf(path, code, suggestion, format string, args ...any) {
+	d.add(SeverityWarning, path, code, fmt.Sprintf(format, args...), suggestion)
+}
+
+// joinPath объединяет части пути к полю
+func joinPath(prefix, path string) string {
+	switch {
+	case prefix == "":
+		return path
+	case path == "":
+		return prefix
+	case strings.HasPrefix(path, "["):
+		return prefix + path
+	default:
+		return prefix + "." + path
+	}
+}
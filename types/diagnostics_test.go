@@ -0,0 +1,66 @@
+package types
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDiagnosticString(t *testing.T) {
+	tests := []struct {
+		diag Diagnostic
+		want string
+	}{
+		{Diagnostic{Severity: SeverityError, Path: "name", Message: "package name is required"}, "error: name: package name is required"},
+		{Diagnostic{Severity: SeverityWarning, Message: "no build targets", Suggestion: "add a target"}, "warning: no build targets (add a target)"},
+	}
+	for _, tt := range tests {
+		if got := tt.diag.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestDiagnosticsErr(t *testing.T) {
+	warnings := Diagnostics{{Severity: SeverityWarning, Path: "license", Message: "license is not specified"}}
+	if warnings.HasErrors() || warnings.Err() != nil {
+		t.Errorf("warnings only: HasErrors() = %v, Err() = %v", warnings.HasErrors(), warnings.Err())
+	}
+
+	list := append(warnings,
+		Diagnostic{Severity: SeverityError, Path: "name", Message: "package name is required"},
+		Diagnostic{Severity: SeverityInfo, Message: "info"},
+		Diagnostic{Severity: SeverityError, Message: "broken"},
+	)
+	if len(list.Errors()) != 2 || len(list.Warnings()) != 1 {
+		t.Errorf("Errors() = %v, Warnings() = %v", list.Errors(), list.Warnings())
+	}
+
+	err := list.Err()
+	var diagnosticsErr *DiagnosticsError
+	if !errors.As(err, &diagnosticsErr) {
+		t.Fatalf("Err() = %v, want DiagnosticsError", err)
+	}
+	message := err.Error()
+	if !strings.Contains(message, "name: package name is required") || !strings.Contains(message, "  - broken") {
+		t.Errorf("Error() = %q", message)
+	}
+	if strings.Contains(message, "license") {
+		t.Errorf("Error() includes warnings: %q", message)
+	}
+}
+
+func TestDiagnosticsWithPrefix(t *testing.T) {
+	list := Diagnostics{{Path: "name"}, {Path: "[0]"}, {Path: ""}}
+	prefixed := list.withPrefix("package")
+
+	want := []string{"package.name", "package[0]", "package"}
+	for i, diag := range prefixed {
+		if diag.Path != want[i] {
+			t.Errorf("path %d = %q, want %q", i, diag.Path, want[i])
+		}
+	}
+	if list[0].Path != "name" {
+		t.Error("withPrefix modified the original list")
+	}
+}
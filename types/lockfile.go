@@ -0,0 +1,41 @@
+package types
+
+// Имя и версия формата файла блокировки
+const (
+	LockfileName    = "criage.lock"
+	LockfileVersion = 1
+)
+
+// Lockfile зафиксированный результат разрешения зависимостей (criage.lock)
+type Lockfile struct {
+	LockfileVersion int    `json:"lockfileVersion"`
+	Name            string `json:"name,omitempty"`
+	Version         string `json:"version,omitempty"`
+
+	// Ограничения корневого манифеста на момент фиксации
+	Dependencies    map[string]string `json:"dependencies,omitempty"`
+	DevDependencies map[string]string `json:"devDependencies,omitempty"`
+	// IncludeDev зависимости разработки разрешены и зафиксированы
+	IncludeDev bool `json:"includeDev,omitempty"`
+
+	// Выбранные пакеты, упорядоченные по имени
+	Packages []LockedPackage `json:"packages"`
+}
+
+// LockedPackage зафиксированная версия пакета
+type LockedPackage struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	Repository string `json:"repository,omitempty"`
+
+	// Файл для платформы
+	OS       string `json:"os,omitempty"`
+	Arch     string `json:"arch,omitempty"`
+	Format   string `json:"format,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	Checksum string `json:"checksum,omitempty"`
+
+	Dependencies map[string]string `json:"dependencies,omitempty"`
+	Provides     []string          `json:"provides,omitempty"`
+}
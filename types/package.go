@@ -1,6 +1,7 @@
 package types
 
 import (
+	"strings"
 	"time"
 )
 
@@ -21,6 +22,13 @@ type PackageManifest struct {
 	// Зависимости
 	Dependencies map[string]string `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
 	DevDeps      map[string]string `json:"devDependencies,omitempty" yaml:"devDependencies,omitempty"`
+	OptionalDeps map[string]string `json:"optionalDependencies,omitempty" yaml:"optionalDependencies,omitempty"`
+	PeerDeps     map[string]string `json:"peerDependencies,omitempty" yaml:"peerDependencies,omitempty"`
+
+	// Отношения с другими пакетами
+	Conflicts map[string]string `json:"conflicts,omitempty" yaml:"conflicts,omitempty"`
+	Provides  []string          `json:"provides,omitempty" yaml:"provides,omitempty"`
+	Replaces  map[string]string `json:"replaces,omitempty" yaml:"replaces,omitempty"`
 
 	// Скрипты жизненного цикла
 	Scripts map[string]string `json:"scripts,omitempty" yaml:"scripts,omitempty"`
@@ -74,8 +82,10 @@ type CompressionConfig struct {
 
 // BuildTarget целевая платформа для сборки
 type BuildTarget struct {
-	OS   string `json:"os" yaml:"os"`
-	Arch string `json:"arch" yaml:"arch"`
+	OS      string `json:"os" yaml:"os"`
+	Arch    string `json:"arch" yaml:"arch"`
+	Libc    string `json:"libc,omitempty" yaml:"libc,omitempty"`
+	Variant string `json:"variant,omitempty" yaml:"variant,omitempty"`
 }
 
 // PackageMetadata метаданные пакета со встроенной информацией о сборке
@@ -86,6 +96,18 @@ type PackageMetadata struct {
 	CreatedAt       time.Time        `json:"createdAt"`
 	CreatedBy       string           `json:"createdBy"`
 	Version         string           `json:"version"`
+
+	// Манифест файлов пакета и корень дерева Меркла над ним
+	Files      []FileManifestEntry `json:"files,omitempty"`
+	MerkleRoot string              `json:"merkleRoot,omitempty"`
+}
+
+// FileManifestEntry запись о файле пакета для проверки целостности
+type FileManifestEntry struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Mode   uint32 `json:"mode"`
+	SHA256 string `json:"sha256"`
 }
 
 // PackageInfo информация об установленном пакете
@@ -114,6 +136,43 @@ const (
 	FormatZip    ArchiveFormat = "zip"
 )
 
+// ArchiveFormats возвращает все поддерживаемые форматы архивов
+func ArchiveFormats() []ArchiveFormat {
+	return []ArchiveFormat{FormatTarZst, FormatTarLZ4, FormatTarXZ, FormatTarGZ, FormatZip}
+}
+
+// Синонимы форматов архивов
+var formatAliases = map[string]ArchiveFormat{
+	"zst":  FormatTarZst,
+	"zstd": FormatTarZst,
+	"lz4":  FormatTarLZ4,
+	"xz":   FormatTarXZ,
+	"txz":  FormatTarXZ,
+	"gz":   FormatTarGZ,
+	"gzip": FormatTarGZ,
+	"tgz":  FormatTarGZ,
+}
+
+// NormalizeArchiveFormat приводит формат или его синоним ("zstd", "tgz") к ArchiveFormat
+func NormalizeArchiveFormat(value string) (ArchiveFormat, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if format := ArchiveFormat(value); format.Valid() {
+		return format, true
+	}
+	format, ok := formatAliases[value]
+	return format, ok
+}
+
+// Valid проверяет, поддерживается ли формат архива
+func (f ArchiveFormat) Valid() bool {
+	for _, format := range ArchiveFormats() {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
 // CompressionLevel уровни сжатия
 const (
 	CompressionFastest = 1
@@ -0,0 +1,40 @@
+package types
+
+import (
+	"github.com/criage-oss/criage-common/platform"
+)
+
+// Platform возвращает нормализованную платформу файла
+func (f *FileEntry) Platform() platform.Platform {
+	return platform.New(f.OS, f.Arch, f.Libc, f.Variant)
+}
+
+// Platform возвращает нормализованную платформу цели сборки
+func (t BuildTarget) Platform() platform.Platform {
+	return platform.New(t.OS, t.Arch, t.Libc, t.Variant)
+}
+
+// SupportsPlatform проверяет платформу по спискам OS и Arch манифеста
+// с учетом синонимов, подстановок и исключений ("!windows")
+func (m *PackageManifest) SupportsPlatform(target platform.Platform) bool {
+	return platform.Match(m.OS, m.Arch, target)
+}
+
+// FileFor выбирает наиболее подходящий файл версии для платформы
+func (v *VersionEntry) FileFor(target platform.Platform) (*FileEntry, bool) {
+	return SelectFile(v.Files, target)
+}
+
+// SelectFile выбирает наиболее подходящий для платформы файл
+func SelectFile(files []FileEntry, target platform.Platform) (*FileEntry, bool) {
+	platforms := make([]platform.Platform, len(files))
+	for i := range files {
+		platforms[i] = files[i].Platform()
+	}
+
+	best := platform.Best(platforms, target)
+	if best < 0 {
+		return nil, false
+	}
+	return &files[best], true
+}
@@ -0,0 +1,178 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/criage-oss/criage-common/semver"
+)
+
+// RelationsError ошибки в зависимостях и отношениях пакета
+type RelationsError struct {
+	Package  string
+	Problems []string
+}
+
+func (e *RelationsError) Error() string {
+	problems := strings.Join(e.Problems, "\n  - ")
+	if e.Package == "" {
+		return "invalid package relations:\n  - " + problems
+	}
+	return fmt.Sprintf("invalid relations of package %s:\n  - %s", e.Package, problems)
+}
+
+// Relations зависимости и отношения пакета, общие для манифеста и записи индекса
+type Relations struct {
+	Dependencies map[string]string
+	DevDeps      map[string]string
+	OptionalDeps map[string]string
+	PeerDeps     map[string]string
+	Conflicts    map[string]string
+	Provides     []string
+	Replaces     map[string]string
+}
+
+// Relations возвращает зависимости и отношения манифеста
+func (m *PackageManifest) Relations() Relations {
+	return Relations{
+		Dependencies: m.Dependencies,
+		DevDeps:      m.DevDeps,
+		OptionalDeps: m.OptionalDeps,
+		PeerDeps:     m.PeerDeps,
+		Conflicts:    m.Conflicts,
+		Provides:     m.Provides,
+		Replaces:     m.Replaces,
+	}
+}
+
+// Relations возвращает зависимости и отношения версии пакета
+func (v *VersionEntry) Relations() Relations {
+	return Relations{
+		Dependencies: v.Dependencies,
+		DevDeps:      v.DevDeps,
+		OptionalDeps: v.OptionalDeps,
+		PeerDeps:     v.PeerDeps,
+		Conflicts:    v.Conflicts,
+		Provides:     v.Provides,
+		Replaces:     v.Replaces,
+	}
+}
+
+// ValidateRelations проверяет зависимости и отношения манифеста
+func (m *PackageManifest) ValidateRelations() error {
+	return m.Relations().Validate(m.Name)
+}
+
+// ValidateRelations проверяет зависимости и отношения версии пакета name
+func (v *VersionEntry) ValidateRelations(name string) error {
+	return v.Relations().Validate(name)
+}
+
+// ParseProvides разбирает виртуальный пакет вида "name" или "name@1.2.3"
+func ParseProvides(value string) (name, version string, err error) {
+	name, version, _ = strings.Cut(strings.TrimSpace(value), "@")
+	if name == "" {
+		return "", "", fmt.Errorf("provides entry %q has no package name", value)
+	}
+	if version != "" {
+		if _, err := semver.ParseTolerant(version); err != nil {
+			return "", "", fmt.Errorf("provides entry %q: %w", value, err)
+		}
+	}
+	return name, version, nil
+}
+
+// Validate проверяет ограничения версий и непротиворечивость отношений пакета self
+func (r Relations) Validate(self string) error {
+	errs := r.Diagnostics(self).Errors()
+	if len(errs) == 0 {
+		return nil
+	}
+
+	problems := make([]string, 0, len(errs))
+	for _, diag := range errs {
+		problems = append(problems, diag.Path+": "+diag.Message)
+	}
+	return &RelationsError{Package: self, Problems: problems}
+}
+
+// Diagnostics проверяет отношения пакета self и возвращает найденные проблемы
+func (r Relations) Diagnostics(self string) Diagnostics {
+	var d diagnostics
+
+	groups := []struct {
+		field string
+		deps  map[string]string
+	}{
+		{"dependencies", r.Dependencies},
+		{"devDependencies", r.DevDeps},
+		{"optionalDependencies", r.OptionalDeps},
+		{"peerDependencies", r.PeerDeps},
+		{"conflicts", r.Conflicts},
+		{"replaces", r.Replaces},
+	}
+	for _, group := range groups {
+		for _, name := range sortedNames(group.deps) {
+			path := group.field + "." + name
+			if strings.TrimSpace(name) == "" {
+				d.errorf(group.field, CodeRequired, "", "empty package name")
+				continue
+			}
+			if self != "" && name == self {
+				d.errorf(path, CodeSelfReference, "remove the entry", "package refers to itself")
+			}
+			if _, err := semver.ParseConstraint(group.deps[name]); err != nil {
+				d.errorf(path, CodeInvalidConstraint, `use a range such as "^1.2.0" or "*"`, "%v", err)
+			}
+		}
+	}
+
+	// Пакет не может одновременно быть обязательной и другой зависимостью
+	overlaps := []struct {
+		field string
+		deps  map[string]string
+	}{
+		{"optionalDependencies", r.OptionalDeps},
+		{"peerDependencies", r.PeerDeps},
+		{"conflicts", r.Conflicts},
+		{"replaces", r.Replaces},
+	}
+	for _, overlap := range overlaps {
+		for _, name := range sortedNames(overlap.deps) {
+			if _, ok := r.Dependencies[name]; ok {
+				d.errorf(overlap.field+"."+name, CodeOverlap, "keep only one of the entries",
+					"%s is listed in both dependencies and %s", name, overlap.field)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i, value := range r.Provides {
+		path := fmt.Sprintf("provides[%d]", i)
+		name, _, err := ParseProvides(value)
+		if err != nil {
+			d.errorf(path, CodeInvalidValue, `use "name" or "name@1.2.3"`, "%v", err)
+			continue
+		}
+		if self != "" && name == self {
+			d.errorf(path, CodeSelfReference, "remove the entry", "package provides itself")
+		}
+		if seen[name] {
+			d.errorf(path, CodeDuplicate, "remove the duplicate", "%s is listed more than once", name)
+		}
+		seen[name] = true
+	}
+
+	return d.list
+}
+
+// sortedNames возвращает имена пакетов по алфавиту
+func sortedNames(m map[string]string) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
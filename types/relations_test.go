@@ -0,0 +1,114 @@
+package types
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseProvides(t *testing.T) {
+	tests := []struct {
+		value   string
+		name    string
+		version string
+		wantErr bool
+	}{
+		{"logger", "logger", "", false},
+		{"logger@1.4.0", "logger", "1.4.0", false},
+		{" logger@v1.4 ", "logger", "v1.4", false},
+		{"@1.0.0", "", "", true},
+		{"", "", "", true},
+		{"logger@latest", "", "", true},
+	}
+	for _, tt := range tests {
+		name, version, err := ParseProvides(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseProvides(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if name != tt.name || version != tt.version {
+			t.Errorf("ParseProvides(%q) = %q, %q, want %q, %q", tt.value, name, version, tt.name, tt.version)
+		}
+	}
+}
+
+func TestRelationsValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		relations Relations
+		// codes ожидаемые коды ошибок по путям
+		codes map[string]string
+	}{
+		{
+			name: "valid",
+			relations: Relations{
+				Dependencies: map[string]string{"lib": "^1.0.0"},
+				OptionalDeps: map[string]string{"color": "*"},
+				PeerDeps:     map[string]string{"host": ">=2"},
+				Conflicts:    map[string]string{"old": "<1.0.0"},
+				Replaces:     map[string]string{"legacy": "*"},
+				Provides:     []string{"logger@1.0.0", "writer"},
+			},
+		},
+		{
+			name:      "invalid constraint",
+			relations: Relations{Dependencies: map[string]string{"lib": "^^1"}},
+			codes:     map[string]string{"dependencies.lib": CodeInvalidConstraint},
+		},
+		{
+			name: "self reference",
+			relations: Relations{
+				DevDeps:  map[string]string{"app": "*"},
+				Provides: []string{"app"},
+			},
+			codes: map[string]string{"devDependencies.app": CodeSelfReference, "provides[0]": CodeSelfReference},
+		},
+		{
+			name: "overlap with dependencies",
+			relations: Relations{
+				Dependencies: map[string]string{"lib": "*", "old": "*"},
+				OptionalDeps: map[string]string{"lib": "*"},
+				Conflicts:    map[string]string{"old": "*"},
+			},
+			codes: map[string]string{"optionalDependencies.lib": CodeOverlap, "conflicts.old": CodeOverlap},
+		},
+		{
+			name:      "empty package name",
+			relations: Relations{PeerDeps: map[string]string{" ": "*"}},
+			codes:     map[string]string{"peerDependencies": CodeRequired},
+		},
+		{
+			name:      "invalid and duplicate provides",
+			relations: Relations{Provides: []string{"logger", "@1.0.0", "logger@2.0.0"}},
+			codes:     map[string]string{"provides[1]": CodeInvalidValue, "provides[2]": CodeDuplicate},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := tt.relations.Diagnostics("app")
+			got := make(map[string]string)
+			for _, diag := range diagnostics.Errors() {
+				got[diag.Path] = diag.Code
+			}
+			if len(got) != len(tt.codes) {
+				t.Errorf("diagnostics = %v, want %v", diagnostics, tt.codes)
+			}
+			for path, code := range tt.codes {
+				if got[path] != code {
+					t.Errorf("%s: code = %q, want %q", path, got[path], code)
+				}
+			}
+
+			err := tt.relations.Validate("app")
+			if len(tt.codes) == 0 {
+				if err != nil {
+					t.Errorf("Validate() = %v", err)
+				}
+				return
+			}
+			var relationsErr *RelationsError
+			if !errors.As(err, &relationsErr) || relationsErr.Package != "app" || len(relationsErr.Problems) != len(tt.codes) {
+				t.Errorf("Validate() = %v, want RelationsError with %d problems", err, len(tt.codes))
+			}
+		})
+	}
+}
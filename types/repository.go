@@ -1,6 +1,9 @@
 package types
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Repository информация о репозитории пакетов
 type Repository struct {
@@ -9,10 +12,69 @@ type Repository struct {
 	Priority int    `json:"priority" yaml:"priority"`
 	Enabled  bool   `json:"enabled" yaml:"enabled"`
 
+	// Зеркала с тем же содержимым, что и URL; используются те же учетные данные
+	Mirrors []Mirror `json:"mirrors,omitempty" yaml:"mirrors,omitempty"`
+
 	// Авторизация
 	AuthToken string `json:"authToken,omitempty" yaml:"authToken,omitempty"`
 	Username  string `json:"username,omitempty" yaml:"username,omitempty"`
 	Password  string `json:"password,omitempty" yaml:"password,omitempty"`
+
+	// Подписи пакетов
+	SigningKeys      []string  `json:"signingKeys,omitempty" yaml:"signingKeys,omitempty"`
+	RequireSignature bool      `json:"requireSignature,omitempty" yaml:"requireSignature,omitempty"`
+	TrustMode        TrustMode `json:"trustMode,omitempty" yaml:"trustMode,omitempty"`
+	RevocationURL    string    `json:"revocationUrl,omitempty" yaml:"revocationUrl,omitempty"`
+
+	// Сетевые настройки, переопределяющие общие: таймаут в секундах,
+	// число повторов, сертификат CA и клиентский сертификат для mTLS
+	Timeout  int    `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Retries  *int   `json:"retries,omitempty" yaml:"retries,omitempty"`
+	CAFile   string `json:"caFile,omitempty" yaml:"caFile,omitempty"`
+	CertFile string `json:"certFile,omitempty" yaml:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty" yaml:"keyFile,omitempty"`
+}
+
+// Mirror зеркало репозитория
+type Mirror struct {
+	URL string `json:"url" yaml:"url"`
+	// Priority явное предпочтение: зеркала с большим значением выбираются первыми
+	Priority int `json:"priority,omitempty" yaml:"priority,omitempty"`
+	// Region регион размещения, например "eu" или "ru-msk"
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+}
+
+// Endpoints возвращает основной адрес и зеркала без повторов.
+// Зеркало с адресом, совпадающим с URL, задает приоритет и регион основного адреса.
+func (r Repository) Endpoints() []Mirror {
+	endpoints := make([]Mirror, 0, len(r.Mirrors)+1)
+	if r.URL != "" {
+		endpoints = append(endpoints, Mirror{URL: r.URL})
+	}
+
+	seen := make(map[string]int, len(r.Mirrors)+1)
+	for i, endpoint := range endpoints {
+		seen[NormalizeMirrorURL(endpoint.URL)] = i
+	}
+	for _, mirror := range r.Mirrors {
+		key := NormalizeMirrorURL(mirror.URL)
+		if key == "" {
+			continue
+		}
+		if i, ok := seen[key]; ok {
+			endpoints[i].Priority = mirror.Priority
+			endpoints[i].Region = mirror.Region
+			continue
+		}
+		seen[key] = len(endpoints)
+		endpoints = append(endpoints, mirror)
+	}
+	return endpoints
+}
+
+// normalizeMirrorURL приводит адрес к виду для сравнения
+func NormalizeMirrorURL(url string) string {
+	return strings.TrimRight(strings.TrimSpace(url), "/")
 }
 
 // PackageEntry запись о пакете в репозитории
@@ -36,6 +98,11 @@ type VersionEntry struct {
 	Description  string            `json:"description"`
 	Dependencies map[string]string `json:"dependencies,omitempty"`
 	DevDeps      map[string]string `json:"devDependencies,omitempty"`
+	OptionalDeps map[string]string `json:"optionalDependencies,omitempty"`
+	PeerDeps     map[string]string `json:"peerDependencies,omitempty"`
+	Conflicts    map[string]string `json:"conflicts,omitempty"`
+	Provides     []string          `json:"provides,omitempty"`
+	Replaces     map[string]string `json:"replaces,omitempty"`
 	Files        []FileEntry       `json:"files"`
 	Size         int64             `json:"size"`
 	Checksum     string            `json:"checksum"`
@@ -47,10 +114,24 @@ type VersionEntry struct {
 type FileEntry struct {
 	OS       string `json:"os"`
 	Arch     string `json:"arch"`
+	Libc     string `json:"libc,omitempty"`
+	Variant  string `json:"variant,omitempty"`
 	Format   string `json:"format"`
 	Filename string `json:"filename"`
 	Size     int64  `json:"size"`
 	Checksum string `json:"checksum"`
+
+	// Доступные дельты от предыдущих версий
+	Deltas []DeltaEntry `json:"deltas,omitempty"`
+}
+
+// DeltaEntry информация о бинарной дельте от предыдущей версии пакета
+type DeltaEntry struct {
+	FromVersion  string `json:"fromVersion"`
+	Filename     string `json:"filename"`
+	Size         int64  `json:"size"`
+	Checksum     string `json:"checksum"`
+	BaseChecksum string `json:"baseChecksum"`
 }
 
 // SearchResult результат поиска
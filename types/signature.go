@@ -0,0 +1,16 @@
+package types
+
+import "time"
+
+// SignatureAlgorithmEd25519 алгоритм подписи пакетов
+const SignatureAlgorithmEd25519 = "ed25519"
+
+// PackageSignature подпись пакета над каноническим дайджестом метаданных и содержимого
+type PackageSignature struct {
+	Algorithm string    `json:"algorithm"`
+	KeyID     string    `json:"keyId"`
+	PublicKey string    `json:"publicKey,omitempty"`
+	Digest    string    `json:"digest"`
+	Signature string    `json:"signature"`
+	SignedAt  time.Time `json:"signedAt"`
+}
@@ -0,0 +1,80 @@
+package types
+
+import "time"
+
+// TrustMode режим доверия ключам подписи репозитория
+type TrustMode string
+
+const (
+	// TrustModeStrict доверять только закрепленным ключам
+	TrustModeStrict TrustMode = "strict"
+	// TrustModeTOFU закрепить первый увиденный ключ (trust on first use)
+	TrustModeTOFU TrustMode = "tofu"
+	// TrustModeOff не проверять подписи
+	TrustModeOff TrustMode = "off"
+)
+
+// KeySource происхождение доверенного ключа
+type KeySource string
+
+const (
+	KeySourceConfig   KeySource = "config"
+	KeySourceManual   KeySource = "manual"
+	KeySourceTOFU     KeySource = "tofu"
+	KeySourceRotation KeySource = "rotation"
+)
+
+// TrustedKey доверенный открытый ключ репозитория
+type TrustedKey struct {
+	KeyID     string     `json:"keyId" yaml:"keyId"`
+	PublicKey string     `json:"publicKey" yaml:"publicKey"`
+	Source    KeySource  `json:"source" yaml:"source"`
+	AddedAt   time.Time  `json:"addedAt" yaml:"addedAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" yaml:"expiresAt,omitempty"`
+	Comment   string     `json:"comment,omitempty" yaml:"comment,omitempty"`
+}
+
+// KeyRevocation запись об отзыве ключа
+type KeyRevocation struct {
+	KeyID     string    `json:"keyId" yaml:"keyId"`
+	RevokedAt time.Time `json:"revokedAt" yaml:"revokedAt"`
+	Reason    string    `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// RevocationList список отозванных ключей, публикуемый репозиторием.
+// Подписывается одним из доверенных ключей репозитория.
+type RevocationList struct {
+	Repository  string          `json:"repository"`
+	Version     int             `json:"version"`
+	Revocations []KeyRevocation `json:"revocations"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+	KeyID       string          `json:"keyId,omitempty"`
+	Signature   string          `json:"signature,omitempty"`
+}
+
+// KeyRotation заявление о смене ключа, подписанное текущим ключом репозитория
+type KeyRotation struct {
+	Repository string `json:"repository"`
+	// Version монотонно возрастающий номер смены ключа в репозитории, начиная с 1
+	Version         int        `json:"version"`
+	OldKeyID        string     `json:"oldKeyId"`
+	NewPublicKey    string     `json:"newPublicKey"`
+	NewKeyExpiresAt *time.Time `json:"newKeyExpiresAt,omitempty"`
+	OldKeyExpiresAt *time.Time `json:"oldKeyExpiresAt,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	Signature       string     `json:"signature,omitempty"`
+}
+
+// Keyring локальное хранилище ключей в директории конфигурации
+type Keyring struct {
+	Version      int                        `json:"version"`
+	Repositories map[string]*RepositoryKeys `json:"repositories"`
+}
+
+// RepositoryKeys ключи и отзывы одного репозитория
+type RepositoryKeys struct {
+	Keys              []TrustedKey    `json:"keys,omitempty"`
+	Revocations       []KeyRevocation `json:"revocations,omitempty"`
+	RevocationVersion int             `json:"revocationVersion,omitempty"`
+	RotationVersion   int             `json:"rotationVersion,omitempty"`
+}
@@ -0,0 +1,87 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// MetadataRole роль подписанных метаданных репозитория
+type MetadataRole string
+
+const (
+	RoleRoot      MetadataRole = "root"
+	RoleTargets   MetadataRole = "targets"
+	RoleSnapshot  MetadataRole = "snapshot"
+	RoleTimestamp MetadataRole = "timestamp"
+)
+
+// SignedMetadata подписанный документ метаданных.
+// Подписи вычисляются над байтами поля Signed в том виде, в котором они хранятся.
+type SignedMetadata struct {
+	Signed     json.RawMessage     `json:"signed"`
+	Signatures []MetadataSignature `json:"signatures"`
+}
+
+// MetadataSignature подпись документа метаданных
+type MetadataSignature struct {
+	KeyID     string `json:"keyid"`
+	Signature string `json:"sig"`
+}
+
+// MetadataKey открытый ключ, объявленный в корневых метаданных
+type MetadataKey struct {
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"publicKey"`
+}
+
+// RoleKeys ключи роли и порог необходимых подписей
+type RoleKeys struct {
+	KeyIDs    []string `json:"keyids"`
+	Threshold int      `json:"threshold"`
+}
+
+// RootMetadata корневые метаданные: ключи и пороги всех ролей
+type RootMetadata struct {
+	Type    MetadataRole              `json:"_type"`
+	Version int                       `json:"version"`
+	Expires time.Time                 `json:"expires"`
+	Keys    map[string]MetadataKey    `json:"keys"`
+	Roles   map[MetadataRole]RoleKeys `json:"roles"`
+}
+
+// TargetsMetadata описание целевых файлов (индекса репозитория)
+type TargetsMetadata struct {
+	Type    MetadataRole          `json:"_type"`
+	Version int                   `json:"version"`
+	Expires time.Time             `json:"expires"`
+	Targets map[string]TargetFile `json:"targets"`
+}
+
+// TargetFile длина и хеши целевого файла
+type TargetFile struct {
+	Length int64             `json:"length"`
+	Hashes map[string]string `json:"hashes"`
+}
+
+// SnapshotMetadata версии метаданных targets на момент публикации
+type SnapshotMetadata struct {
+	Type    MetadataRole        `json:"_type"`
+	Version int                 `json:"version"`
+	Expires time.Time           `json:"expires"`
+	Meta    map[string]MetaFile `json:"meta"`
+}
+
+// TimestampMetadata указатель на актуальную версию snapshot
+type TimestampMetadata struct {
+	Type    MetadataRole        `json:"_type"`
+	Version int                 `json:"version"`
+	Expires time.Time           `json:"expires"`
+	Meta    map[string]MetaFile `json:"meta"`
+}
+
+// MetaFile версия, длина и хеши файла метаданных
+type MetaFile struct {
+	Version int               `json:"version"`
+	Length  int64             `json:"length,omitempty"`
+	Hashes  map[string]string `json:"hashes,omitempty"`
+}
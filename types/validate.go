@@ -0,0 +1,295 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/criage-oss/criage-common/platform"
+	"github.com/criage-oss/criage-common/semver"
+)
+
+// MaxPackageNameLength максимальная длина имени пакета
+const MaxPackageNameLength = 214
+
+// PackageNamePattern допустимое имя пакета: "name" или "@scope/name"
+const PackageNamePattern = `^(@[a-z0-9][a-z0-9._-]*/)?[a-z0-9][a-z0-9._-]*$`
+
+var packageNamePattern = regexp.MustCompile(PackageNamePattern)
+
+// Validate проверяет манифест пакета и возвращает найденные проблемы
+func (m *PackageManifest) Validate() Diagnostics {
+	var d diagnostics
+
+	validateName(&d, "name", m.Name)
+	validateVersion(&d, "version", m.Version)
+
+	if strings.TrimSpace(m.Description) == "" {
+		d.warnf("description", CodeEmpty, "add a short description", "description is empty")
+	}
+	if strings.TrimSpace(m.License) == "" {
+		d.warnf("license", CodeEmpty, `use an SPDX identifier such as "MIT"`, "license is not specified")
+	}
+	validateURL(&d, "homepage", m.Homepage)
+	validateURL(&d, "repository", m.Repository)
+
+	seen := make(map[string]bool)
+	for i, keyword := range m.Keywords {
+		keyword = strings.ToLower(strings.TrimSpace(keyword))
+		path := fmt.Sprintf("keywords[%d]", i)
+		switch {
+		case keyword == "":
+			d.warnf(path, CodeEmpty, "remove the entry", "empty keyword")
+		case seen[keyword]:
+			d.warnf(path, CodeDuplicate, "remove the duplicate", "keyword %q is listed more than once", keyword)
+		}
+		seen[keyword] = true
+	}
+
+	d.list = append(d.list, m.Relations().Diagnostics(m.Name)...)
+
+	for _, name := range sortedNames(m.Scripts) {
+		if strings.TrimSpace(m.Scripts[name]) == "" {
+			d.warnf("scripts."+name, CodeEmpty, "remove the script", "script has no command")
+		}
+	}
+	if m.Hooks != nil {
+		hooks := []struct {
+			field    string
+			commands []string
+		}{
+			{"preInstall", m.Hooks.PreInstall},
+			{"postInstall", m.Hooks.PostInstall},
+			{"preRemove", m.Hooks.PreRemove},
+			{"postRemove", m.Hooks.PostRemove},
+			{"preUpdate", m.Hooks.PreUpdate},
+			{"postUpdate", m.Hooks.PostUpdate},
+		}
+		for _, hook := range hooks {
+			for i, command := range hook.commands {
+				if strings.TrimSpace(command) == "" {
+					d.warnf(fmt.Sprintf("hooks.%s[%d]", hook.field, i), CodeEmpty, "remove the entry", "hook has no command")
+				}
+			}
+		}
+	}
+
+	validatePatterns(&d, "files", m.Files)
+	validatePatterns(&d, "exclude", m.Exclude)
+	validatePlatformList(&d, "os", m.OS, platform.KnownOS)
+	validatePlatformList(&d, "arch", m.Arch, platform.KnownArch)
+
+	if m.MinVersion != "" {
+		if _, err := semver.ParseTolerant(m.MinVersion); err != nil {
+			d.errorf("minVersion", CodeInvalidVersion, `use a version such as "1.0.0"`, "%v", err)
+		}
+	}
+
+	return d.list
+}
+
+// Validate проверяет манифест сборки и возвращает найденные проблемы
+func (b *BuildManifest) Validate() Diagnostics {
+	var d diagnostics
+
+	validateName(&d, "name", b.Name)
+	validateVersion(&d, "version", b.Version)
+
+	if strings.TrimSpace(b.OutputDir) == "" {
+		d.warnf("outputDir", CodeEmpty, `set it to a directory such as "dist"`, "output directory is not specified")
+	}
+	if len(b.IncludeFiles) == 0 {
+		d.warnf("includeFiles", CodeEmpty, "list the files to package", "no files are included")
+	}
+	validatePatterns(&d, "includeFiles", b.IncludeFiles)
+	validatePatterns(&d, "excludeFiles", b.ExcludeFiles)
+
+	b.validateCompression(&d)
+
+	if len(b.Targets) == 0 {
+		d.warnf("targets", CodeEmpty, "add at least one target", "no build targets")
+	}
+	seen := make(map[string]int)
+	for i, target := range b.Targets {
+		path := fmt.Sprintf("targets[%d]", i)
+		validateTarget(&d, path, target)
+
+		key := target.Platform().String()
+		if first, ok := seen[key]; ok {
+			d.errorf(path, CodeDuplicate, "remove the duplicate", "target %s duplicates targets[%d]", key, first)
+			continue
+		}
+		seen[key] = i
+	}
+
+	for _, name := range sortedNames(b.Environment) {
+		if strings.TrimSpace(name) == "" || strings.ContainsAny(name, "= \t\n") {
+			d.errorf("environment."+name, CodeInvalidName, "", "invalid environment variable name %q", name)
+		}
+	}
+
+	return d.list
+}
+
+// Validate проверяет манифесты пакета и сборки, вложенные в метаданные.
+// Пути сообщений начинаются с "package." и "build.".
+func (m *PackageMetadata) Validate() Diagnostics {
+	var list Diagnostics
+	if m.PackageManifest != nil {
+		list = append(list, m.PackageManifest.Validate().withPrefix("package")...)
+	}
+	if m.BuildManifest != nil {
+		list = append(list, m.BuildManifest.Validate().withPrefix("build")...)
+	}
+	return list
+}
+
+// validateCompression проверяет формат и уровень сжатия
+func (b *BuildManifest) validateCompression(d *diagnostics) {
+	format := ArchiveFormat(strings.ToLower(strings.TrimSpace(b.Compression.Format)))
+	switch {
+	case format == "":
+		d.errorf("compression.format", CodeRequired, fmt.Sprintf("use %q", FormatTarZst), "compression format is required")
+	case !format.Valid():
+		suggestion := "supported formats: " + joinFormats(ArchiveFormats())
+		if alias, ok := NormalizeArchiveFormat(string(format)); ok {
+			suggestion = fmt.Sprintf("did you mean %q?", alias)
+		}
+		d.errorf("compression.format", CodeUnknownFormat, suggestion, "unknown compression format %q", b.Compression.Format)
+	}
+
+	maxLevel := CompressionBest
+	if format == FormatTarZst {
+		maxLevel = 22
+	}
+	if b.Compression.Level < 0 || b.Compression.Level > maxLevel {
+		d.errorf("compression.level", CodeInvalidValue, fmt.Sprintf("use a level from %d to %d, 0 for default", CompressionFastest, maxLevel),
+			"compression level %d is out of range", b.Compression.Level)
+	}
+}
+
+// validateTarget проверяет целевую платформу сборки
+func validateTarget(d *diagnostics, path string, target BuildTarget) {
+	if strings.TrimSpace(target.OS) == "" {
+		d.errorf(path+".os", CodeRequired, `use a GOOS value such as "linux"`, "target OS is required")
+	} else if !platform.KnownOS(target.OS) {
+		d.warnf(path+".os", CodeUnknownPlatform, "", "unknown operating system %q", target.OS)
+	}
+
+	if strings.TrimSpace(target.Arch) == "" {
+		d.errorf(path+".arch", CodeRequired, `use a GOARCH value such as "amd64"`, "target architecture is required")
+	} else if !platform.KnownArch(target.Arch) {
+		d.warnf(path+".arch", CodeUnknownPlatform, "", "unknown architecture %q", target.Arch)
+	}
+
+	p := target.Platform()
+	if target.Libc != "" {
+		if p.OS != "linux" {
+			d.warnf(path+".libc", CodeInvalidValue, "remove the field", "libc only applies to linux targets")
+		} else if p.Libc != platform.LibcGlibc && p.Libc != platform.LibcMusl {
+			d.errorf(path+".libc", CodeInvalidValue, `use "glibc" or "musl"`, "unknown libc %q", target.Libc)
+		}
+	}
+	if target.Variant != "" && !validVariant(p.Arch, p.Variant) {
+		d.errorf(path+".variant", CodeInvalidValue, "use v1-v4 for amd64, v5-v7 for arm or v8-v9 for arm64", "unsupported CPU level %q for %s", target.Variant, p.Arch)
+	}
+}
+
+// validVariant проверяет уровень CPU для архитектуры
+func validVariant(arch, variant string) bool {
+	switch arch {
+	case "amd64":
+		return variant >= "v1" && variant <= "v4" && len(variant) == 2
+	case "arm":
+		return variant >= "v5" && variant <= "v7" && len(variant) == 2
+	case "arm64":
+		return variant == "v8" || variant == "v9"
+	}
+	return false
+}
+
+// validateName проверяет имя пакета
+func validateName(d *diagnostics, path, name string) {
+	switch {
+	case strings.TrimSpace(name) == "":
+		d.errorf(path, CodeRequired, "", "package name is required")
+	case len(name) > MaxPackageNameLength:
+		d.errorf(path, CodeInvalidName, "", "package name is longer than %d characters", MaxPackageNameLength)
+	case !packageNamePattern.MatchString(name):
+		suggestion := ""
+		if lower := strings.ToLower(strings.TrimSpace(name)); packageNamePattern.MatchString(lower) {
+			suggestion = fmt.Sprintf("did you mean %q?", lower)
+		}
+		d.errorf(path, CodeInvalidName, suggestion,
+			"package name %q may only contain lowercase letters, digits, '.', '_' and '-'", name)
+	}
+}
+
+// validateVersion проверяет версию пакета по semver
+func validateVersion(d *diagnostics, path, version string) {
+	if strings.TrimSpace(version) == "" {
+		d.errorf(path, CodeRequired, `use a version such as "1.0.0"`, "version is required")
+		return
+	}
+	if _, err := semver.Parse(version); err != nil {
+		suggestion := `use a version such as "1.0.0"`
+		if tolerant, err := semver.ParseTolerant(version); err == nil {
+			suggestion = fmt.Sprintf("did you mean %q?", tolerant.String())
+		}
+		d.errorf(path, CodeInvalidVersion, suggestion, "%v", err)
+	}
+}
+
+// validateURL проверяет абсолютный URL
+func validateURL(d *diagnostics, path, value string) {
+	if value == "" {
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		d.warnf(path, CodeInvalidURL, "use an absolute URL such as https://example.com", "invalid URL %q", value)
+	}
+}
+
+// validatePatterns проверяет шаблоны файлов
+func validatePatterns(d *diagnostics, field string, patterns []string) {
+	for i, pattern := range patterns {
+		path := fmt.Sprintf("%s[%d]", field, i)
+		if strings.TrimSpace(pattern) == "" {
+			d.warnf(path, CodeEmpty, "remove the entry", "empty file pattern")
+			continue
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			d.errorf(path, CodeInvalidPattern, "", "invalid file pattern %q: %v", pattern, err)
+		}
+	}
+}
+
+// validatePlatformList проверяет список шаблонов OS или архитектур
+func validatePlatformList(d *diagnostics, field string, patterns []string, known func(string) bool) {
+	for i, pattern := range patterns {
+		path := fmt.Sprintf("%s[%d]", field, i)
+		value := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(pattern), "!"))
+		switch {
+		case value == "":
+			d.warnf(path, CodeEmpty, "remove the entry", "empty platform")
+		case strings.ContainsAny(value, "*?["):
+			if _, err := filepath.Match(value, ""); err != nil {
+				d.errorf(path, CodeInvalidPattern, "", "invalid platform pattern %q: %v", pattern, err)
+			}
+		case !known(value):
+			d.warnf(path, CodeUnknownPlatform, "", "unknown platform %q", value)
+		}
+	}
+}
+
+// joinFormats перечисляет форматы через запятую
+func joinFormats(formats []ArchiveFormat) string {
+	names := make([]string, len(formats))
+	for i, format := range formats {
+		names[i] = string(format)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,164 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+// diagnosticCodes возвращает коды сообщений уровня severity по путям
+func diagnosticCodes(diagnostics Diagnostics, severity Severity) map[string]string {
+	codes := make(map[string]string)
+	for _, diag := range diagnostics {
+		if diag.Severity == severity {
+			codes[diag.Path] = diag.Code
+		}
+	}
+	return codes
+}
+
+// checkCodes сравнивает коды сообщений с ожидаемыми
+func checkCodes(t *testing.T, kind string, got, want map[string]string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s = %v, want %v", kind, got, want)
+	}
+	for path, code := range want {
+		if got[path] != code {
+			t.Errorf("%s %s: code = %q, want %q", kind, path, got[path], code)
+		}
+	}
+}
+
+func TestPackageManifestValidate(t *testing.T) {
+	valid := func() *PackageManifest {
+		return &PackageManifest{
+			Name:         "@scope/app",
+			Version:      "1.2.3",
+			Description:  "Application",
+			License:      "MIT",
+			Homepage:     "https://example.com",
+			Dependencies: map[string]string{"lib": "^1.0.0"},
+			OS:           []string{"linux", "!windows", "mac*"},
+			Arch:         []string{"amd64"},
+			MinVersion:   "1.0",
+		}
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(m *PackageManifest)
+		errors   map[string]string
+		warnings map[string]string
+	}{
+		{"valid", func(m *PackageManifest) {}, nil, nil},
+		{"missing name and version", func(m *PackageManifest) { m.Name, m.Version = "", "" },
+			map[string]string{"name": CodeRequired, "version": CodeRequired}, nil},
+		{"uppercase name", func(m *PackageManifest) { m.Name = "App" },
+			map[string]string{"name": CodeInvalidName}, nil},
+		{"long name", func(m *PackageManifest) { m.Name = strings.Repeat("a", MaxPackageNameLength+1) },
+			map[string]string{"name": CodeInvalidName}, nil},
+		{"non-semver version", func(m *PackageManifest) { m.Version = "v1.2" },
+			map[string]string{"version": CodeInvalidVersion}, nil},
+		{"invalid minVersion", func(m *PackageManifest) { m.MinVersion = "latest" },
+			map[string]string{"minVersion": CodeInvalidVersion}, nil},
+		{"invalid pattern", func(m *PackageManifest) { m.Files = []string{"[", ""} },
+			map[string]string{"files[0]": CodeInvalidPattern}, map[string]string{"files[1]": CodeEmpty}},
+		{"relations", func(m *PackageManifest) { m.Conflicts = map[string]string{"lib": "*"} },
+			map[string]string{"conflicts.lib": CodeOverlap}, nil},
+		{"warnings only", func(m *PackageManifest) {
+			m.Description, m.License, m.Homepage = "", "", "example.com"
+			m.Keywords = []string{"cli", "CLI", " "}
+			m.Scripts = map[string]string{"build": " "}
+			m.Hooks = &PackageHooks{PostInstall: []string{""}}
+			m.OS = []string{"beos"}
+		}, nil, map[string]string{
+			"description":          CodeEmpty,
+			"license":              CodeEmpty,
+			"homepage":             CodeInvalidURL,
+			"keywords[1]":          CodeDuplicate,
+			"keywords[2]":          CodeEmpty,
+			"scripts.build":        CodeEmpty,
+			"hooks.postInstall[0]": CodeEmpty,
+			"os[0]":                CodeUnknownPlatform,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest := valid()
+			tt.modify(manifest)
+			diagnostics := manifest.Validate()
+			checkCodes(t, "errors", diagnosticCodes(diagnostics, SeverityError), tt.errors)
+			checkCodes(t, "warnings", diagnosticCodes(diagnostics, SeverityWarning), tt.warnings)
+			if (diagnostics.Err() != nil) != (len(tt.errors) > 0) {
+				t.Errorf("Err() = %v", diagnostics.Err())
+			}
+		})
+	}
+}
+
+func TestBuildManifestValidate(t *testing.T) {
+	valid := func() *BuildManifest {
+		return &BuildManifest{
+			Name:         "app",
+			Version:      "1.0.0",
+			OutputDir:    "dist",
+			IncludeFiles: []string{"bin/*"},
+			Compression:  CompressionConfig{Format: "tar.zst", Level: 19},
+			Targets:      []BuildTarget{{OS: "linux", Arch: "amd64", Variant: "v3"}, {OS: "linux", Arch: "arm64", Libc: "musl"}},
+			Environment:  map[string]string{"CGO_ENABLED": "0"},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(b *BuildManifest)
+		errors   map[string]string
+		warnings map[string]string
+	}{
+		{"valid", func(b *BuildManifest) {}, nil, nil},
+		{"missing format", func(b *BuildManifest) { b.Compression = CompressionConfig{} },
+			map[string]string{"compression.format": CodeRequired}, nil},
+		{"unknown format", func(b *BuildManifest) { b.Compression = CompressionConfig{Format: "rar"} },
+			map[string]string{"compression.format": CodeUnknownFormat}, nil},
+		{"level out of range", func(b *BuildManifest) { b.Compression = CompressionConfig{Format: "zip", Level: 12} },
+			map[string]string{"compression.level": CodeInvalidValue}, nil},
+		{"duplicate target", func(b *BuildManifest) { b.Targets = append(b.Targets, BuildTarget{OS: "Linux", Arch: "x86_64_v3"}) },
+			map[string]string{"targets[2]": CodeDuplicate}, nil},
+		{"invalid target", func(b *BuildManifest) { b.Targets = []BuildTarget{{OS: "linux", Libc: "uclibc", Variant: "v9"}} },
+			map[string]string{"targets[0].arch": CodeRequired, "targets[0].libc": CodeInvalidValue, "targets[0].variant": CodeInvalidValue}, nil},
+		{"libc outside linux", func(b *BuildManifest) { b.Targets = []BuildTarget{{OS: "darwin", Arch: "arm64", Libc: "musl"}} },
+			nil, map[string]string{"targets[0].libc": CodeInvalidValue}},
+		{"invalid environment", func(b *BuildManifest) { b.Environment = map[string]string{"A=B": "1"} },
+			map[string]string{"environment.A=B": CodeInvalidName}, nil},
+		{"empty", func(b *BuildManifest) { b.OutputDir, b.IncludeFiles, b.Targets = "", nil, nil },
+			nil, map[string]string{"outputDir": CodeEmpty, "includeFiles": CodeEmpty, "targets": CodeEmpty}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest := valid()
+			tt.modify(manifest)
+			diagnostics := manifest.Validate()
+			checkCodes(t, "errors", diagnosticCodes(diagnostics, SeverityError), tt.errors)
+			checkCodes(t, "warnings", diagnosticCodes(diagnostics, SeverityWarning), tt.warnings)
+		})
+	}
+}
+
+func TestPackageMetadataValidate(t *testing.T) {
+	metadata := &PackageMetadata{
+		PackageManifest: &PackageManifest{Name: "app", Version: "1.0", Description: "d", License: "MIT"},
+		BuildManifest: &BuildManifest{
+			Name: "app", Version: "1.0.0", OutputDir: "dist", IncludeFiles: []string{"*"},
+			Targets: []BuildTarget{{OS: "linux", Arch: "amd64"}},
+		},
+	}
+	codes := diagnosticCodes(metadata.Validate(), SeverityError)
+	checkCodes(t, "errors", codes, map[string]string{
+		"package.version":          CodeInvalidVersion,
+		"build.compression.format": CodeRequired,
+	})
+
+	if diagnostics := (&PackageMetadata{}).Validate(); len(diagnostics) != 0 {
+		t.Errorf("empty metadata diagnostics = %v", diagnostics)
+	}
+}
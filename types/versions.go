@@ -0,0 +1,50 @@
+package types
+
+import (
+	"github.com/criage-oss/criage-common/semver"
+)
+
+// LatestStableVersion возвращает последнюю стабильную версию пакета
+func (p *PackageEntry) LatestStableVersion() (*VersionEntry, error) {
+	return p.latestVersion(false)
+}
+
+// LatestAnyVersion возвращает последнюю версию пакета, включая pre-release
+func (p *PackageEntry) LatestAnyVersion() (*VersionEntry, error) {
+	return p.latestVersion(true)
+}
+
+// SortedVersions возвращает версии пакета по возрастанию.
+// Записи с некорректной версией пропускаются.
+func (p *PackageEntry) SortedVersions() []VersionEntry {
+	parsed := make([]*semver.Version, 0, len(p.Versions))
+	byVersion := make(map[*semver.Version]VersionEntry, len(p.Versions))
+	for _, entry := range p.Versions {
+		v, err := semver.ParseTolerant(entry.Version)
+		if err != nil {
+			continue
+		}
+		parsed = append(parsed, v)
+		byVersion[v] = entry
+	}
+
+	semver.Sort(parsed)
+
+	sorted := make([]VersionEntry, 0, len(parsed))
+	for _, v := range parsed {
+		sorted = append(sorted, byVersion[v])
+	}
+	return sorted
+}
+
+// latestVersion выбирает наибольшую версию пакета
+func (p *PackageEntry) latestVersion(includePrerelease bool) (*VersionEntry, error) {
+	sorted := p.SortedVersions()
+	for i := len(sorted) - 1; i >= 0; i-- {
+		v, _ := semver.ParseTolerant(sorted[i].Version)
+		if includePrerelease || v.IsStable() {
+			return &sorted[i], nil
+		}
+	}
+	return nil, semver.ErrNoVersions
+}